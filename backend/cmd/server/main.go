@@ -1,119 +1,436 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 
+	"0xhub/backend/internal/audit"
+	"0xhub/backend/internal/auth"
+	"0xhub/backend/internal/certs"
+	"0xhub/backend/internal/config"
 	"0xhub/backend/internal/handlers"
+	"0xhub/backend/internal/metrics"
 	"0xhub/backend/internal/models"
+	"0xhub/backend/internal/seed"
 	"0xhub/backend/internal/store"
+	"0xhub/backend/internal/webhooks"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
-	// Initialize store and seed with sample data
-	store := store.NewStore()
-	seedProjects(store)
+	cfg, err := config.Load(os.Args[0], os.Args[1:], os.Getenv)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		log.Fatal("Failed to load configuration: ", err)
+	}
+	log.Printf("Effective configuration:\n%s", cfg)
+
+	// Background work stops once the server has drained, before the store
+	// and audit log are closed
+	ctx, stopBackground := context.WithCancel(context.Background())
+	var background sync.WaitGroup
+
+	serverMetrics := metrics.New()
+
+	// Initialize store, timing the storage backend itself
+	backingStore, err := newStore(cfg.Store)
+	if err != nil {
+		log.Fatal("Failed to initialize store:", err)
+	}
+	// Durable backends keep the revision history alongside the projects
+	revisionLog, _ := backingStore.(store.RevisionLog)
+	backingStore = serverMetrics.InstrumentStore(backingStore)
+	// Record the revisions of every project, publish changes, then keep the
+	// search index in sync with every mutation
+	history, err := store.NewHistoryStore(backingStore, revisionLog, cfg.Store.RevisionHistory)
+	if err != nil {
+		log.Fatal("Failed to load revision history:", err)
+	}
+	// Publish every change to watchers
+	watched, err := store.NewWatchedStore(history, store.DefaultChangeHistory)
+	if err != nil {
+		log.Fatal("Failed to start change feed:", err)
+	}
+	serverMetrics.RegisterSubscribers(watched)
+	indexed, err := store.NewIndexedStore(watched)
+	if err != nil {
+		log.Fatal("Failed to build search index:", err)
+	}
+	serverMetrics.RegisterProjects(indexed)
+	if cfg.Seed.File != "" {
+		if err := seedStore(indexed, cfg.Seed.File, cfg.Seed.Mode); err != nil {
+			log.Fatal("Failed to seed projects:", err)
+		}
+	}
+
+	auditLog, err := newAuditLog(cfg.Audit.Log, cfg.Audit.Stdout)
+	if err != nil {
+		log.Fatal("Failed to open audit log:", err)
+	}
+
+	// Deleted projects wait in the trash until their retention passes
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		store.RunPurger(ctx, history, cfg.Trash.Retention, cfg.Trash.PurgeInterval, func(p *models.Project) {
+			event := audit.NewEvent(audit.ActionPurge, p.ID, p, nil)
+			event.Actor = store.PurgerActor
+			if err := auditLog.Append(event); err != nil {
+				log.Printf("Failed to record audit event for project %s: %v", p.ID, err)
+			}
+		})
+	}()
+
+	// Deliver every change to the subscribed webhooks
+	webhookRegistry, err := webhooks.NewRegistry(cfg.Webhooks.File)
+	if err != nil {
+		log.Fatal("Failed to load webhooks:", err)
+	}
+	dispatcher := webhooks.NewDispatcher(webhookRegistry, webhooks.Options{
+		MaxAttempts: cfg.Webhooks.MaxAttempts,
+		Timeout:     cfg.Webhooks.Timeout,
+	})
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		if err := dispatcher.Run(ctx, watched); err != nil {
+			log.Println("Webhook dispatcher stopped:", err)
+		}
+	}()
+
+	// Writes require credentials when any are configured; without them
+	// they stay open for local development
+	authenticator, err := newAuthenticator(ctx, &background, cfg.Auth)
+	if err != nil {
+		log.Fatal("Failed to configure authentication:", err)
+	}
+	// requireWrite admits callers that can write at least some projects;
+	// ProjectsHandler then checks ownership of the project itself
+	var requireWrite, requireAdmin []gin.HandlerFunc
+	if authenticator != nil {
+		requireWrite = []gin.HandlerFunc{
+			auth.Require(authenticator),
+			auth.RequireAnyPermission(auth.PermissionWrite, auth.PermissionWriteManaged),
+		}
+		requireAdmin = []gin.HandlerFunc{
+			auth.Require(authenticator),
+			auth.RequireAnyPermission(auth.PermissionAdmin),
+		}
+	} else {
+		log.Println("WARNING: no credentials configured, write endpoints are unauthenticated")
+	}
 
 	// Initialize handlers
-	projectsHandler := handlers.NewProjectsHandler(store)
+	projectsHandler := handlers.NewProjectsHandler(indexed, auditLog)
+	searchHandler := handlers.NewSearchHandler(indexed)
+	revisionsHandler := handlers.NewRevisionsHandler(indexed, history, auditLog)
+	auditHandler := handlers.NewAuditHandler(auditLog)
+	webhooksHandler := handlers.NewWebhooksHandler(webhookRegistry, dispatcher)
 
 	// Setup router
 	router := gin.Default()
+	router.Use(handlers.RequestID())
+	router.Use(serverMetrics.Middleware())
 
 	// CORS configuration
-	config := cors.DefaultConfig()
-	config.AllowOrigins = []string{"http://localhost:5173", "http://localhost:3000"}
-	config.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
-	router.Use(cors.New(config))
+	corsConfig := cors.DefaultConfig()
+	corsConfig.AllowOrigins = cfg.Server.CORSOrigins
+	corsConfig.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", "If-Match", "If-None-Match", "Last-Event-ID", handlers.UpdatedByHeader, handlers.RequestIDHeader}
+	corsConfig.ExposeHeaders = []string{"ETag", handlers.RequestIDHeader}
+	router.Use(cors.New(corsConfig))
 
-	// Health check endpoint
+	// Change feeds accept the same browser origins as CORS
+	watchHandler := handlers.NewWatchHandler(watched)
+	webSocketHandler := handlers.NewWebSocketHandler(watched, corsConfig.AllowOrigins)
+
+	// Health check endpoint. It fails while the server drains, so load
+	// balancers stop sending it requests.
+	var draining atomic.Bool
 	router.GET("/api/health", func(c *gin.Context) {
+		if draining.Load() {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"status": "draining",
+			})
+			return
+		}
 		c.JSON(200, gin.H{
 			"status": "ok",
 		})
 	})
 
+	// Prometheus metrics, public like the health check
+	router.GET("/metrics", serverMetrics.Handler())
+
 	// API routes
 	api := router.Group("/api")
 	{
 		api.GET("/projects", projectsHandler.GetProjects)
+		api.GET("/projects/watch", watchHandler.Watch)
+		api.GET("/projects/ws", webSocketHandler.Serve)
 		api.GET("/projects/:id", projectsHandler.GetProject)
-		api.POST("/projects", projectsHandler.CreateProject)
-		api.PUT("/projects/:id", projectsHandler.UpdateProject)
-		api.DELETE("/projects/:id", projectsHandler.DeleteProject)
+		api.GET("/projects/:id/revisions", revisionsHandler.ListRevisions)
+		api.GET("/projects/:id/revisions/:rev", revisionsHandler.GetRevision)
+		api.GET("/search", searchHandler.Search)
+		api.GET("/trash", projectsHandler.GetTrash)
+		api.GET("/export", projectsHandler.Export)
+	}
+
+	// Write routes require authentication; reads stay public
+	write := api.Group("", requireWrite...)
+	{
+		write.POST("/projects", projectsHandler.CreateProject)
+		write.PUT("/projects/:id", projectsHandler.UpdateProject)
+		write.PATCH("/projects/:id", projectsHandler.PatchProject)
+		write.DELETE("/projects/:id", projectsHandler.DeleteProject)
+		write.POST("/projects/:id/restore", projectsHandler.RestoreProject)
+		write.POST("/projects/:id/revisions/:rev/restore", revisionsHandler.RestoreRevision)
+		write.POST("/import", projectsHandler.Import)
+	}
+
+	// The audit log names callers and their addresses, and webhooks can send
+	// every change anywhere, so only admins use them
+	admin := api.Group("", requireAdmin...)
+	{
+		admin.GET("/audit", auditHandler.List)
+		admin.GET("/webhooks", webhooksHandler.ListWebhooks)
+		admin.POST("/webhooks", webhooksHandler.CreateWebhook)
+		admin.GET("/webhooks/:id", webhooksHandler.GetWebhook)
+		admin.PUT("/webhooks/:id", webhooksHandler.UpdateWebhook)
+		admin.DELETE("/webhooks/:id", webhooksHandler.DeleteWebhook)
+		admin.GET("/webhooks/:id/deliveries", webhooksHandler.ListDeliveries)
+		admin.GET("/webhooks/:id/deliveries/:delivery", webhooksHandler.GetDelivery)
+		admin.POST("/webhooks/:id/deliveries/:delivery/redeliver", webhooksHandler.Redeliver)
+	}
+
+	server := &http.Server{
+		Addr:              cfg.Server.Addr,
+		Handler:           router,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
+	}
+	server.RegisterOnShutdown(watchHandler.Shutdown)
+	server.RegisterOnShutdown(webSocketHandler.Shutdown)
+	if tlsConfig := cfg.Server.TLS; tlsConfig.CertFile != "" {
+		reloader, err := certs.NewReloader(tlsConfig.CertFile, tlsConfig.KeyFile, tlsConfig.ClientCAFile)
+		if err != nil {
+			log.Fatal("Failed to load TLS certificates:", err)
+		}
+		if reloader.RequiresClientCerts() {
+			log.Println("Requiring client certificates signed by", tlsConfig.ClientCAFile)
+		}
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			reloader.Watch(ctx, tlsConfig.ReloadInterval)
+		}()
+		server.TLSConfig = reloader.TLSConfig()
 	}
 
-	// Start server
-	log.Println("Server starting on :8080")
-	if err := router.Run(":8080"); err != nil {
-		log.Fatal("Failed to start server:", err)
+	exitCode := 0
+	if err := serve(server, cfg.Server, &draining); err != nil {
+		log.Println("Server failed:", err)
+		exitCode = 1
 	}
+
+	// Stop background work, then flush what is persisted
+	stopBackground()
+	background.Wait()
+	if closer, ok := auditLog.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			log.Println("Failed to close audit log:", err)
+			exitCode = 1
+		}
+	}
+	if err := indexed.Close(); err != nil {
+		log.Println("Failed to close store:", err)
+		exitCode = 1
+	}
+	log.Println("Server stopped")
+	os.Exit(exitCode)
+}
+
+// serve runs server until SIGTERM or SIGINT. It then reports unhealthy
+// through draining for cfg.ShutdownDelay, stops accepting connections and
+// waits up to cfg.ShutdownTimeout for requests in flight.
+func serve(server *http.Server, cfg config.ServerConfig, draining *atomic.Bool) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	errs := make(chan error, 1)
+	go func() {
+		if server.TLSConfig != nil {
+			log.Println("Server starting on", server.Addr, "with TLS")
+			errs <- server.ListenAndServeTLS("", "")
+			return
+		}
+		log.Println("Server starting on", server.Addr)
+		errs <- server.ListenAndServe()
+	}()
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+	// A second signal stops the server immediately
+	stop()
+
+	draining.Store(true)
+	if cfg.ShutdownDelay > 0 {
+		log.Println("Shutting down in", cfg.ShutdownDelay)
+		time.Sleep(cfg.ShutdownDelay)
+	}
+	log.Println("Shutting down, waiting up to", cfg.ShutdownTimeout, "for requests in flight")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		server.Close()
+		return fmt.Errorf("requests still running after %s were cut off: %w", cfg.ShutdownTimeout, err)
+	}
+	return nil
 }
 
-// seedProjects adds sample projects for testing
-func seedProjects(store *store.Store) {
-	projects := []*models.Project{
-		{
-			ID:          "1",
-			Name:        "Kubernetes",
-			Description: "Production-Grade Container Orchestration",
-			URL:         "https://kubernetes.io",
-			Icon:        "https://kubernetes.io/images/favicon.png",
-			Category:    "Infrastructure",
-			Status:      "active",
-		},
-		{
-			ID:          "2",
-			Name:        "Docker",
-			Description: "Empowering App Development for Developers",
-			URL:         "https://www.docker.com",
-			Icon:        "https://www.docker.com/favicon.ico",
-			Category:    "Containerization",
-			Status:      "active",
-		},
-		{
-			ID:          "3",
-			Name:        "Prometheus",
-			Description: "Monitoring and alerting toolkit",
-			URL:         "https://prometheus.io",
-			Icon:        "https://prometheus.io/assets/favicon.ico",
-			Category:    "Monitoring",
-			Status:      "active",
-		},
-		{
-			ID:          "4",
-			Name:        "Grafana",
-			Description: "The open observability platform",
-			URL:         "https://grafana.com",
-			Icon:        "https://grafana.com/favicon.ico",
-			Category:    "Visualization",
-			Status:      "active",
-		},
-		{
-			ID:          "5",
-			Name:        "Helm",
-			Description: "The package manager for Kubernetes",
-			URL:         "https://helm.sh",
-			Icon:        "https://helm.sh/img/favicon-32x32.png",
-			Category:    "DevOps",
-			Status:      "active",
-		},
-		{
-			ID:          "6",
-			Name:        "Istio",
-			Description: "Connect, secure, control, and observe services",
-			URL:         "https://istio.io",
-			Icon:        "https://istio.io/favicon.ico",
-			Category:    "Service Mesh",
-			Status:      "active",
-		},
-	}
-
-	for _, project := range projects {
-		store.Create(project)
-	}
-	log.Println("Seeded", len(projects), "sample projects")
+// newAuthenticator combines every configured credential type. It returns
+// nil when none is configured. Background reloads run until ctx is done and
+// are tracked in background.
+func newAuthenticator(ctx context.Context, background *sync.WaitGroup, cfg config.AuthConfig) (auth.Authenticator, error) {
+	var authenticators []auth.Authenticator
+
+	apiKeys, err := auth.NewAPIKeys(cfg.APIKeys, cfg.APIKeysFile)
+	if err != nil {
+		return nil, err
+	}
+	if apiKeys.Len() > 0 || cfg.APIKeysFile != "" {
+		log.Println("Loaded", apiKeys.Len(), "API keys")
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			apiKeys.Watch(ctx, cfg.APIKeysReloadInterval)
+		}()
+		authenticators = append(authenticators, apiKeys)
+	}
+
+	if oidc := cfg.OIDC; oidc.JWKS != "" {
+		jwtConfig := auth.JWTConfig{
+			Issuer:        oidc.Issuer,
+			Audience:      oidc.Audience,
+			UsernameClaim: oidc.UsernameClaim,
+			RolesClaim:    oidc.RolesClaim,
+		}
+		if oidc.DefaultRole != "" {
+			role, err := auth.ParseRole(oidc.DefaultRole)
+			if err != nil {
+				return nil, fmt.Errorf("invalid OIDC default role: %w", err)
+			}
+			jwtConfig.DefaultRole = role
+		}
+		keys, err := auth.NewJWKS(ctx, oidc.JWKS, oidc.JWKSRefreshInterval)
+		if err != nil {
+			return nil, err
+		}
+		jwtAuthenticator, err := auth.NewJWTAuthenticator(jwtConfig, keys)
+		if err != nil {
+			return nil, err
+		}
+		log.Println("Accepting JWTs issued by", oidc.Issuer, "for", oidc.Audience)
+		authenticators = append(authenticators, jwtAuthenticator)
+	}
+
+	if cfg.TokenReview.ServiceAccounts != "" {
+		accounts, err := auth.ParseServiceAccounts(cfg.TokenReview.ServiceAccounts)
+		if err != nil {
+			return nil, err
+		}
+		reviewConfig, err := auth.InClusterTokenReviewConfig()
+		if err != nil {
+			return nil, err
+		}
+		reviewConfig.Audience = cfg.TokenReview.Audience
+		reviewConfig.ServiceAccounts = accounts
+		tokenReview, err := auth.NewTokenReviewAuthenticator(reviewConfig)
+		if err != nil {
+			return nil, err
+		}
+		log.Println("Accepting tokens of", len(accounts), "service accounts via TokenReview")
+		authenticators = append(authenticators, tokenReview)
+	}
+
+	if len(authenticators) == 0 {
+		return nil, nil
+	}
+	return auth.Chain(authenticators...), nil
+}
+
+// newAuditLog opens the audit log at path, or keeps recent events in memory
+// when path is empty, optionally mirroring events to stdout
+func newAuditLog(path string, stdout bool) (audit.Log, error) {
+	var auditLog audit.Log
+	if path != "" {
+		fileLog, err := audit.NewFileLog(path)
+		if err != nil {
+			return nil, err
+		}
+		log.Println("Writing audit log to", path)
+		auditLog = fileLog
+	} else {
+		auditLog = audit.NewMemoryLog(audit.DefaultMemoryLogSize)
+	}
+	if stdout {
+		return audit.NewTee(auditLog, os.Stdout), nil
+	}
+	return auditLog, nil
+}
+
+// newStore creates the configured storage backend
+func newStore(cfg config.StoreConfig) (store.Store, error) {
+	switch cfg.Backend {
+	case config.StoreMemory:
+		log.Println("Using in-memory store")
+		return store.NewStore(), nil
+	case config.StoreFile:
+		log.Println("Using file-backed store in", cfg.DataDir)
+		return store.NewFileStore(cfg.DataDir, cfg.CompactThreshold)
+	case config.StoreSQLite:
+		log.Println("Using SQLite store at", cfg.SQLitePath)
+		return store.NewSQLiteStore(cfg.SQLitePath)
+	default:
+		return nil, fmt.Errorf("unknown store backend %q", cfg.Backend)
+	}
+}
+
+// seedStore loads the seed file into s
+func seedStore(s store.Store, path, mode string) error {
+	projects, err := seed.Load(path)
+	if err != nil {
+		return err
+	}
+	result, err := seed.Apply(s, projects, mode)
+	if err != nil {
+		return err
+	}
+	if result.Skipped {
+		log.Println("Store is not empty, skipped seeding from", path)
+		return nil
+	}
+	log.Printf("Seeded from %s: %d created, %d updated, %d unchanged",
+		path, result.Created, result.Updated, result.Unchanged)
+	return nil
 }
@@ -0,0 +1,120 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"0xhub/backend/internal/closer"
+	"0xhub/backend/internal/models"
+)
+
+// Actions recorded in the audit log
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionRestore = "restore"
+	// ActionPurge permanently deletes a project from the trash
+	ActionPurge = "purge"
+)
+
+// Event records a single mutation of a project
+type Event struct {
+	// ID increases with every event appended to a log
+	ID        int64     `json:"id"`
+	Time      time.Time `json:"time"`
+	Actor     string    `json:"actor,omitempty"`
+	Action    string    `json:"action"`
+	ProjectID string    `json:"projectId"`
+	// Before and After are the project around the mutation; Before is nil
+	// for a create and After is nil for a delete
+	Before    *models.Project      `json:"before,omitempty"`
+	After     *models.Project      `json:"after,omitempty"`
+	Changes   []models.FieldChange `json:"changes"`
+	SourceIP  string               `json:"sourceIp,omitempty"`
+	RequestID string               `json:"requestId,omitempty"`
+}
+
+// NewEvent creates an event for a mutation of the project from before to
+// after, computing the field changes between them
+func NewEvent(action, projectID string, before, after *models.Project) *Event {
+	return &Event{
+		Time:      time.Now().UTC(),
+		Action:    action,
+		ProjectID: projectID,
+		Before:    before,
+		After:     after,
+		Changes:   models.Diff(before, after),
+	}
+}
+
+// Query selects events from a log
+type Query struct {
+	// ProjectID only matches events for that project when set
+	ProjectID string
+	// Since only matches events at or after that time when set
+	Since time.Time
+	// Before only matches events with a lower ID when set, so the last ID
+	// of a newest first page is the cursor for the next one
+	Before int64
+	// Limit caps the number of events returned; zero returns all
+	Limit int
+	// Newest returns the most recent matching events, newest first, rather
+	// than the oldest
+	Newest bool
+}
+
+func (q Query) matches(e *Event) bool {
+	if q.ProjectID != "" && e.ProjectID != q.ProjectID {
+		return false
+	}
+	if q.Before > 0 && e.ID >= q.Before {
+		return false
+	}
+	return q.Since.IsZero() || !e.Time.Before(q.Since)
+}
+
+// Log is an append-only record of audit events
+type Log interface {
+	// Append assigns the event an ID and records it
+	Append(event *Event) error
+	// Query returns matching events, oldest first unless q.Newest is set
+	Query(q Query) ([]Event, error)
+}
+
+// Tee is a Log that also writes every appended event as a JSON line to w,
+// such as stdout for a log collector
+type Tee struct {
+	Log
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewTee mirrors the events appended to log to w
+func NewTee(log Log, w io.Writer) *Tee {
+	return &Tee{Log: log, w: w}
+}
+
+// Append records the event and writes it to the mirror
+func (t *Tee) Append(event *Event) error {
+	if err := t.Log.Append(event); err != nil {
+		return err
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit event: %w", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, err = t.w.Write(append(data, '\n'))
+	return err
+}
+
+// Close closes the log the events are appended to
+func (t *Tee) Close() error {
+	return closer.Close(t.Log)
+}
@@ -0,0 +1,204 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"0xhub/backend/internal/models"
+)
+
+func newTestFileLog(t *testing.T, path string) *FileLog {
+	t.Helper()
+	l, err := NewFileLog(path)
+	if err != nil {
+		t.Fatalf("NewFileLog() failed: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return l
+}
+
+// logs returns every Log implementation
+func logs(t *testing.T) map[string]Log {
+	return map[string]Log{
+		"memory": NewMemoryLog(0),
+		"file":   newTestFileLog(t, filepath.Join(t.TempDir(), "audit.log")),
+	}
+}
+
+func appendEvents(t *testing.T, l Log) {
+	t.Helper()
+	before := &models.Project{ID: "1", Name: "Kubernetes", Status: "active"}
+	after := &models.Project{ID: "1", Name: "Kubernetes", Status: "archived"}
+	events := []*Event{
+		NewEvent(ActionCreate, "1", nil, before),
+		NewEvent(ActionCreate, "2", nil, &models.Project{ID: "2", Name: "Docker"}),
+		NewEvent(ActionUpdate, "1", before, after),
+		NewEvent(ActionDelete, "1", after, nil),
+	}
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, e := range events {
+		e.Time = base.Add(time.Duration(i) * time.Minute)
+		e.Actor = "alice"
+		if err := l.Append(e); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+		if e.ID != int64(i+1) {
+			t.Fatalf("Expected event ID %d, got %d", i+1, e.ID)
+		}
+	}
+}
+
+func eventIDs(events []Event) []int64 {
+	ids := make([]int64, 0, len(events))
+	for _, e := range events {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+func TestLog_Query(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 2, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		query Query
+		want  []int64
+	}{
+		{name: "all events oldest first", query: Query{}, want: []int64{1, 2, 3, 4}},
+		{name: "by project", query: Query{ProjectID: "1"}, want: []int64{1, 3, 4}},
+		{name: "since is inclusive", query: Query{Since: since}, want: []int64{3, 4}},
+		{name: "project and since", query: Query{ProjectID: "2", Since: since}, want: []int64{}},
+		{name: "limit", query: Query{Limit: 2}, want: []int64{1, 2}},
+		{name: "before", query: Query{Before: 3}, want: []int64{1, 2}},
+		{name: "newest first", query: Query{Newest: true}, want: []int64{4, 3, 2, 1}},
+		{name: "newest limit", query: Query{Newest: true, Limit: 2}, want: []int64{4, 3}},
+		{name: "newest before", query: Query{Newest: true, Before: 4, Limit: 2}, want: []int64{3, 2}},
+		{name: "newest by project", query: Query{Newest: true, ProjectID: "1", Limit: 2}, want: []int64{4, 3}},
+		{name: "newest since", query: Query{Newest: true, Since: since, Before: 4}, want: []int64{3}},
+	}
+
+	for logName, l := range logs(t) {
+		appendEvents(t, l)
+		for _, tt := range tests {
+			t.Run(logName+"/"+tt.name, func(t *testing.T) {
+				events, err := l.Query(tt.query)
+				if err != nil {
+					t.Fatalf("Query failed: %v", err)
+				}
+				got := eventIDs(events)
+				if len(got) != len(tt.want) {
+					t.Fatalf("Expected %v, got %v", tt.want, got)
+				}
+				for i := range got {
+					if got[i] != tt.want[i] {
+						t.Fatalf("Expected %v, got %v", tt.want, got)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestLog_EventChanges(t *testing.T) {
+	for logName, l := range logs(t) {
+		t.Run(logName, func(t *testing.T) {
+			appendEvents(t, l)
+			events, err := l.Query(Query{})
+			if err != nil {
+				t.Fatalf("Query failed: %v", err)
+			}
+
+			update := events[2]
+			if update.Action != ActionUpdate || update.Actor != "alice" {
+				t.Fatalf("Expected update by alice, got %+v", update)
+			}
+			if len(update.Changes) != 1 || update.Changes[0].Field != "status" {
+				t.Fatalf("Expected a single status change, got %+v", update.Changes)
+			}
+			if update.Changes[0].Before != "active" || update.Changes[0].After != "archived" {
+				t.Fatalf("Expected active -> archived, got %+v", update.Changes[0])
+			}
+			if update.Before == nil || update.After == nil || update.After.Status != "archived" {
+				t.Fatalf("Expected before and after snapshots, got %+v", update)
+			}
+		})
+	}
+}
+
+func TestMemoryLog_DropsOldest(t *testing.T) {
+	l := NewMemoryLog(2)
+	for i := 0; i < 3; i++ {
+		if err := l.Append(NewEvent(ActionCreate, "1", nil, &models.Project{ID: "1"})); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+
+	events, err := l.Query(Query{})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if got := eventIDs(events); len(got) != 2 || got[0] != 2 || got[1] != 3 {
+		t.Fatalf("Expected events [2 3], got %v", got)
+	}
+}
+
+func TestFileLog_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	l, err := NewFileLog(path)
+	if err != nil {
+		t.Fatalf("NewFileLog() failed: %v", err)
+	}
+	appendEvents(t, l)
+	l.Close()
+
+	// A torn final line from a crash is skipped
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	f.WriteString(`{"id":5,"acti`)
+	f.Close()
+
+	reopened := newTestFileLog(t, path)
+	event := NewEvent(ActionCreate, "3", nil, &models.Project{ID: "3"})
+	if err := reopened.Append(event); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	if event.ID != 5 {
+		t.Fatalf("Expected numbering to continue at 5, got %d", event.ID)
+	}
+
+	events, err := reopened.Query(Query{})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(events) != 5 || events[4].ProjectID != "3" {
+		t.Fatalf("Expected 5 events ending with project 3, got %v", eventIDs(events))
+	}
+}
+
+func TestTee(t *testing.T) {
+	var out bytes.Buffer
+	l := NewTee(NewMemoryLog(0), &out)
+	appendEvents(t, l)
+
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	if len(lines) != 4 {
+		t.Fatalf("Expected 4 JSON lines, got %d", len(lines))
+	}
+	var event Event
+	if err := json.Unmarshal(lines[3], &event); err != nil {
+		t.Fatalf("Invalid JSON line: %v", err)
+	}
+	if event.ID != 4 || event.Action != ActionDelete {
+		t.Fatalf("Expected delete event 4, got %+v", event)
+	}
+
+	events, err := l.Query(Query{})
+	if err != nil || len(events) != 4 {
+		t.Fatalf("Expected the wrapped log to hold 4 events, got %d (%v)", len(events), err)
+	}
+}
@@ -0,0 +1,155 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+)
+
+// FileLog appends audit events as JSON lines to a file, which is never
+// rewritten
+type FileLog struct {
+	mu     sync.RWMutex
+	path   string
+	file   *os.File
+	lastID int64
+}
+
+// NewFileLog opens the log at path, creating it if needed
+func NewFileLog(path string) (*FileLog, error) {
+	l := &FileLog{path: path}
+	// Continue numbering after the last event already in the file
+	if err := l.scan(func(e *Event) bool {
+		l.lastID = max(l.lastID, e.ID)
+		return true
+	}); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	l.file = file
+	// Terminate a line torn by a crash so the next event starts on its own
+	if torn, err := endsMidLine(path); err != nil || torn {
+		if err == nil {
+			_, err = file.Write([]byte{'\n'})
+		}
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to repair audit log: %w", err)
+		}
+	}
+	return l, nil
+}
+
+// endsMidLine reports whether the non-empty file at path lacks a trailing
+// newline
+func endsMidLine(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil || info.Size() == 0 {
+		return false, err
+	}
+	last := make([]byte, 1)
+	if _, err := f.ReadAt(last, info.Size()-1); err != nil {
+		return false, err
+	}
+	return last[0] != '\n', nil
+}
+
+// Append durably records an event
+func (l *FileLog) Append(event *Event) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	event.ID = l.lastID + 1
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit event: %w", err)
+	}
+	if _, err := l.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit event: %w", err)
+	}
+	if err := l.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync audit log: %w", err)
+	}
+	l.lastID = event.ID
+	return nil
+}
+
+// Query returns matching events, oldest first unless q.Newest is set. The
+// file is read from the start either way; newest first keeps the last Limit
+// matches as it goes.
+func (l *FileLog) Query(q Query) ([]Event, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	events := make([]Event, 0)
+	err := l.scan(func(e *Event) bool {
+		if q.Before > 0 && e.ID >= q.Before {
+			// IDs increase through the file, so nothing later matches
+			return false
+		}
+		if q.matches(e) {
+			events = append(events, *e)
+		}
+		if !q.Newest {
+			return q.Limit <= 0 || len(events) < q.Limit
+		}
+		if q.Limit > 0 && len(events) > q.Limit {
+			events = events[1:]
+		}
+		return true
+	})
+	if q.Newest {
+		slices.Reverse(events)
+	}
+	return events, err
+}
+
+// Close closes the file
+func (l *FileLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.file.Close()
+}
+
+// scan calls fn with each event in the file until it returns false. A line
+// torn by a crash mid-write is skipped.
+func (l *FileLog) scan(fn func(e *Event) bool) error {
+	f, err := os.Open(l.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		if !fn(&e) {
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package audit
+
+import "sync"
+
+// DefaultMemoryLogSize is the number of events a MemoryLog keeps by default
+const DefaultMemoryLogSize = 10000
+
+// MemoryLog keeps the most recent audit events in memory
+type MemoryLog struct {
+	mu     sync.RWMutex
+	events []Event
+	size   int
+	lastID int64
+}
+
+// NewMemoryLog creates a log holding at most size events, dropping the
+// oldest beyond that. A size of zero or less uses DefaultMemoryLogSize.
+func NewMemoryLog(size int) *MemoryLog {
+	if size <= 0 {
+		size = DefaultMemoryLogSize
+	}
+	return &MemoryLog{size: size}
+}
+
+// Append records an event
+func (l *MemoryLog) Append(event *Event) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.lastID++
+	event.ID = l.lastID
+	if len(l.events) >= l.size {
+		l.events = append(l.events[:0], l.events[len(l.events)-l.size+1:]...)
+	}
+	l.events = append(l.events, *event)
+	return nil
+}
+
+// Query returns matching events, oldest first unless q.Newest is set
+func (l *MemoryLog) Query(q Query) ([]Event, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	events := make([]Event, 0)
+	for n := range l.events {
+		i := n
+		if q.Newest {
+			i = len(l.events) - 1 - n
+		}
+		if q.matches(&l.events[i]) {
+			events = append(events, l.events[i])
+			if q.Limit > 0 && len(events) == q.Limit {
+				break
+			}
+		}
+	}
+	return events, nil
+}
@@ -0,0 +1,175 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultAPIKeyRole is granted to API keys that do not name a role
+const DefaultAPIKeyRole = RoleEditor
+
+// apiKey is a parsed API key entry
+type apiKey struct {
+	token string
+	role  Role
+}
+
+// APIKeys authenticates bearer tokens against a set of named static keys.
+// Keys come from an inline list, typically an environment variable, and an
+// optional file that can be reloaded while the server runs.
+type APIKeys struct {
+	inline map[string]apiKey
+	path   string
+
+	mu sync.RWMutex
+	// keys maps the SHA-256 of each token to its identity, so lookups do not
+	// compare secrets byte by byte
+	keys map[[sha256.Size]byte]Identity
+	// loaded is the file content keys were last built from
+	loaded []byte
+}
+
+// NewAPIKeys loads keys from inline and, if path is not empty, the file at
+// path. Both hold one "name=token" or "name:role=token" entry per line or
+// comma-separated item; blank lines and lines starting with "#" are ignored.
+// Keys without a role get DefaultAPIKeyRole.
+func NewAPIKeys(inline, path string) (*APIKeys, error) {
+	parsed, err := parseAPIKeys(inline)
+	if err != nil {
+		return nil, err
+	}
+	k := &APIKeys{inline: parsed, path: path}
+	if err := k.Reload(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Len returns the number of configured keys
+func (k *APIKeys) Len() int {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	return len(k.keys)
+}
+
+// Authenticate returns the identity named after the key matching token
+func (k *APIKeys) Authenticate(_ context.Context, token string) (*Identity, error) {
+	sum := sha256.Sum256([]byte(token))
+
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	identity, ok := k.keys[sum]
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+	return &identity, nil
+}
+
+// Reload rereads the key file. On error the previous keys stay in effect.
+func (k *APIKeys) Reload() error {
+	var content []byte
+	if k.path != "" {
+		data, err := os.ReadFile(k.path)
+		if err != nil {
+			return fmt.Errorf("failed to read API keys: %w", err)
+		}
+		content = data
+	}
+
+	k.mu.RLock()
+	unchanged := k.keys != nil && bytes.Equal(content, k.loaded)
+	k.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	fromFile, err := parseAPIKeys(string(content))
+	if err != nil {
+		return fmt.Errorf("%s: %w", k.path, err)
+	}
+	keys := make(map[[sha256.Size]byte]Identity, len(k.inline)+len(fromFile))
+	names := make(map[string]bool)
+	for _, set := range []map[string]apiKey{k.inline, fromFile} {
+		for name, key := range set {
+			if names[name] {
+				return fmt.Errorf("API key %q is defined more than once", name)
+			}
+			sum := sha256.Sum256([]byte(key.token))
+			if other, ok := keys[sum]; ok {
+				return fmt.Errorf("API keys %q and %q share a token", other.Name, name)
+			}
+			names[name] = true
+			keys[sum] = Identity{Name: name, Roles: []Role{key.role}}
+		}
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys = keys
+	k.loaded = content
+	return nil
+}
+
+// Watch reloads the key file every interval until ctx is done, so rotated
+// keys, such as an updated Kubernetes Secret, take effect without a restart
+func (k *APIKeys) Watch(ctx context.Context, interval time.Duration) {
+	if k.path == "" {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := k.Reload(); err != nil {
+				log.Println("Failed to reload API keys, keeping the previous ones:", err)
+			}
+		}
+	}
+}
+
+// parseAPIKeys parses "name[:role]=token" entries separated by newlines or
+// commas
+func parseAPIKeys(s string) (map[string]apiKey, error) {
+	keys := make(map[string]apiKey)
+	entries := strings.FieldsFunc(s, func(r rune) bool { return r == '\n' || r == ',' })
+	for i, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		name, token, ok := strings.Cut(entry, "=")
+		name, token = strings.TrimSpace(name), strings.TrimSpace(token)
+		if !ok || name == "" || token == "" {
+			// The entry may hold a secret, so only report where it is
+			return nil, fmt.Errorf("malformed API key entry %d, expected name[:role]=token", i+1)
+		}
+		role := DefaultAPIKeyRole
+		if n, r, ok := strings.Cut(name, ":"); ok {
+			parsed, err := ParseRole(r)
+			if err != nil {
+				return nil, fmt.Errorf("API key %q: %w", n, err)
+			}
+			name, role = strings.TrimSpace(n), parsed
+			if name == "" {
+				return nil, fmt.Errorf("malformed API key entry %d, expected name[:role]=token", i+1)
+			}
+		}
+		if _, exists := keys[name]; exists {
+			return nil, fmt.Errorf("API key %q is defined more than once", name)
+		}
+		keys[name] = apiKey{token: token, role: role}
+	}
+	return keys, nil
+}
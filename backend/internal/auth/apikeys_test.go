@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeys_Authenticate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api-keys")
+	require.NoError(t, os.WriteFile(path, []byte("# rotated monthly\noperator=op-token\n\nci = ci-token\n"), 0o600))
+
+	require.NoError(t, os.WriteFile(path, []byte("# rotated monthly\noperator:operator=op-token\n\nci = ci-token\n"), 0o600))
+
+	keys, err := NewAPIKeys("admin:Admin=admin-token,deploy=deploy-token", path)
+	require.NoError(t, err)
+	assert.Equal(t, 4, keys.Len())
+
+	for token, want := range map[string]Identity{
+		"admin-token":  {Name: "admin", Roles: []Role{RoleAdmin}},
+		"deploy-token": {Name: "deploy", Roles: []Role{DefaultAPIKeyRole}},
+		"op-token":     {Name: "operator", Roles: []Role{RoleOperator}},
+		"ci-token":     {Name: "ci", Roles: []Role{DefaultAPIKeyRole}},
+	} {
+		identity, err := keys.Authenticate(context.Background(), token)
+		require.NoError(t, err)
+		assert.Equal(t, want, *identity)
+	}
+
+	_, err = keys.Authenticate(context.Background(), "wrong-token")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestAPIKeys_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"missing token":   "admin=",
+		"missing name":    "=token",
+		"bare token":      "secret-token",
+		"duplicate name":  "admin=a,admin=b",
+		"duplicate token": "admin=same,ci=same",
+		"unknown role":    "admin:root=secret-token",
+		"role only":       ":admin=secret-token",
+	}
+	for name, inline := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewAPIKeys(inline, "")
+			require.Error(t, err)
+			assert.NotContains(t, err.Error(), "secret-token")
+		})
+	}
+
+	_, err := NewAPIKeys("", filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
+
+func TestAPIKeys_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api-keys")
+	require.NoError(t, os.WriteFile(path, []byte("operator=old-token"), 0o600))
+
+	keys, err := NewAPIKeys("", path)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte("operator=new-token"), 0o600))
+	require.NoError(t, keys.Reload())
+
+	_, err = keys.Authenticate(context.Background(), "old-token")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	identity, err := keys.Authenticate(context.Background(), "new-token")
+	require.NoError(t, err)
+	assert.Equal(t, "operator", identity.Name)
+
+	// A broken file keeps the previous keys in effect
+	require.NoError(t, os.WriteFile(path, []byte("operator"), 0o600))
+	assert.Error(t, keys.Reload())
+	_, err = keys.Authenticate(context.Background(), "new-token")
+	assert.NoError(t, err)
+}
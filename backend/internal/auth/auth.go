@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrInvalidCredentials is returned when a token is not accepted
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// Identity is an authenticated caller
+type Identity struct {
+	// Name identifies the caller, such as the name of its API key
+	Name string `json:"name"`
+	// Roles determine what the caller may do
+	Roles []Role `json:"roles"`
+}
+
+// Authenticator verifies a bearer token and returns who presented it, or
+// ErrInvalidCredentials
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*Identity, error)
+}
+
+// Chain tries each authenticator in turn and accepts the first identity one
+// of them returns
+func Chain(authenticators ...Authenticator) Authenticator {
+	return chain(authenticators)
+}
+
+type chain []Authenticator
+
+func (ch chain) Authenticate(ctx context.Context, token string) (*Identity, error) {
+	for _, authenticator := range ch {
+		identity, err := authenticator.Authenticate(ctx, token)
+		if err == nil {
+			return identity, nil
+		}
+		if !errors.Is(err, ErrInvalidCredentials) {
+			return nil, err
+		}
+	}
+	return nil, ErrInvalidCredentials
+}
+
+// identityKey is the gin context key holding the authenticated Identity
+const identityKey = "auth.identity"
+
+// Require returns middleware that rejects requests without a bearer token
+// accepted by authenticator, and records the caller's Identity otherwise
+func Require(authenticator Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := bearerToken(c.Request)
+		if !ok {
+			unauthorized(c, "a bearer token is required")
+			return
+		}
+		identity, err := authenticator.Authenticate(c.Request.Context(), token)
+		if errors.Is(err, ErrInvalidCredentials) {
+			unauthorized(c, "the bearer token is not valid")
+			return
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.Set(identityKey, identity)
+		c.Next()
+	}
+}
+
+// IdentityFrom returns the caller authenticated by Require, or nil
+func IdentityFrom(c *gin.Context) *Identity {
+	identity, _ := c.Get(identityKey)
+	id, _ := identity.(*Identity)
+	return id
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer" header
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+func unauthorized(c *gin.Context, message string) {
+	c.Header("WWW-Authenticate", `Bearer realm="0xhub"`)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+		"error": message,
+	})
+}
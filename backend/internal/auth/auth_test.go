@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequire(t *testing.T) {
+	keys, err := NewAPIKeys("ci=ci-token", "")
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/write", Require(keys), func(c *gin.Context) {
+		c.String(http.StatusOK, IdentityFrom(c).Name)
+	})
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+		wantBody      string
+	}{
+		{"no header", "", http.StatusUnauthorized, ""},
+		{"wrong scheme", "Basic Y2k6Y2ktdG9rZW4=", http.StatusUnauthorized, ""},
+		{"wrong token", "Bearer other-token", http.StatusUnauthorized, ""},
+		{"valid token", "Bearer ci-token", http.StatusOK, "ci"},
+		{"scheme ignores case", "bearer ci-token", http.StatusOK, "ci"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "/write", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusUnauthorized {
+				assert.Equal(t, `Bearer realm="0xhub"`, w.Header().Get("WWW-Authenticate"))
+			} else {
+				assert.Equal(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRequireAnyPermission(t *testing.T) {
+	keys, err := NewAPIKeys("viewer:viewer=viewer-token,operator:operator=operator-token", "")
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/write", Require(keys), RequireAnyPermission(PermissionWrite, PermissionWriteManaged), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	send := func(token string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/write", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, send("operator-token").Code)
+
+	w := send("viewer-token")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), `\"viewer\" with roles [viewer] lacks the projects:write permission`)
+	assert.Contains(t, w.Body.String(), `"missingPermission":"projects:write"`)
+}
+
+func TestIdentity_Can(t *testing.T) {
+	admin := &Identity{Roles: []Role{RoleAdmin}}
+	for _, p := range []Permission{PermissionRead, PermissionWrite, PermissionWriteManaged, PermissionAdmin} {
+		assert.True(t, admin.Can(p), p)
+	}
+
+	editor := &Identity{Roles: []Role{RoleEditor}}
+	assert.True(t, editor.Can(PermissionWrite))
+	assert.False(t, editor.Can(PermissionWriteManaged))
+
+	combined := &Identity{Roles: []Role{RoleEditor, RoleOperator}}
+	assert.True(t, combined.Can(PermissionWriteManaged))
+	assert.False(t, combined.Can(PermissionAdmin))
+
+	assert.False(t, (&Identity{}).Can(PermissionRead))
+}
@@ -0,0 +1,272 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// minJWKSRefetch limits how often an unknown key ID can trigger a refetch,
+// so tokens with made-up key IDs cannot hammer the JWKS endpoint
+const minJWKSRefetch = 10 * time.Second
+
+// jwksFetchTimeout bounds a refetch, which runs apart from the request that
+// triggered it
+const jwksFetchTimeout = 10 * time.Second
+
+// ErrUnknownKey is returned when no JWKS key matches a token
+var ErrUnknownKey = errors.New("no matching key in JWKS")
+
+// JWKS is a cached JSON Web Key Set loaded from a file or an http(s) URL. It
+// is reloaded once refreshInterval has passed, and early when a token names
+// a key ID it does not hold, which picks up signing key rotation. Reloads
+// run one at a time in the background while the cached keys keep serving.
+type JWKS struct {
+	source          string
+	refreshInterval time.Duration
+	client          *http.Client
+	refetch         singleflight.Group
+
+	mu   sync.RWMutex
+	keys map[string]crypto.PublicKey
+	// fetchedAt is when keys were loaded and attemptedAt when the last
+	// reload started, whether or not it succeeded
+	fetchedAt   time.Time
+	attemptedAt time.Time
+}
+
+// NewJWKS loads the key set at source, a file path or an http(s) URL
+func NewJWKS(ctx context.Context, source string, refreshInterval time.Duration) (*JWKS, error) {
+	j := &JWKS{
+		source:          source,
+		refreshInterval: refreshInterval,
+		client:          &http.Client{Timeout: jwksFetchTimeout},
+	}
+	keys, err := j.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	j.keys = keys
+	j.fetchedAt = time.Now()
+	j.attemptedAt = j.fetchedAt
+	return j, nil
+}
+
+// Key returns the public key with the given ID. An empty kid matches the
+// only key of a single-key set.
+func (j *JWKS) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	j.mu.RLock()
+	keys := j.keys
+	age := time.Since(j.fetchedAt)
+	sinceAttempt := time.Since(j.attemptedAt)
+	j.mu.RUnlock()
+
+	key, ok := lookupKey(keys, kid)
+	switch {
+	case !ok && sinceAttempt >= minJWKSRefetch:
+		// Wait for the reload, which may bring the key. A failed reload
+		// leaves the cached keys in place.
+		select {
+		case <-j.reload():
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		j.mu.RLock()
+		keys = j.keys
+		j.mu.RUnlock()
+		key, ok = lookupKey(keys, kid)
+	case ok && age >= j.refreshInterval && sinceAttempt >= min(j.refreshInterval, minJWKSRefetch):
+		// The cached key is still good enough to verify this token
+		j.reload()
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: key ID %q", ErrUnknownKey, kid)
+	}
+	return key, nil
+}
+
+// reload refetches the key set unless a reload is already running, and
+// returns a channel that receives when it ends. It is not tied to any
+// request, so a canceled request cannot fail the reload others wait on.
+func (j *JWKS) reload() <-chan singleflight.Result {
+	return j.refetch.DoChan("", func() (any, error) {
+		j.mu.Lock()
+		j.attemptedAt = time.Now()
+		j.mu.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), jwksFetchTimeout)
+		defer cancel()
+		keys, err := j.fetch(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		j.mu.Lock()
+		j.keys = keys
+		j.fetchedAt = time.Now()
+		j.mu.Unlock()
+		return nil, nil
+	})
+}
+
+func lookupKey(keys map[string]crypto.PublicKey, kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, true
+		}
+	}
+	key, ok := keys[kid]
+	return key, ok
+}
+
+// fetch loads and parses the key set
+func (j *JWKS) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	data, err := j.read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load JWKS from %s: %w", j.source, err)
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS from %s: %w", j.source, err)
+	}
+	return keys, nil
+}
+
+func (j *JWKS) read(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(j.source, "https://") && !strings.HasPrefix(j.source, "http://") {
+		return os.ReadFile(j.source)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// jwk holds the JSON Web Key fields needed for signature verification
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC and OKP
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJWKS decodes the signing keys of a key set. Encryption keys and key
+// types that cannot verify JWT signatures are skipped, and so are keys that
+// fail to decode, after logging them, so one bad key does not disable the
+// others.
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			log.Printf("Skipping unusable JWKS key %q: %v", k.Kid, err)
+			continue
+		}
+		if key != nil {
+			keys[k.Kid] = key
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no signing keys")
+	}
+	return keys, nil
+}
+
+// publicKey decodes the key, or returns nil for unsupported key types
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil || !e.IsInt64() {
+			return nil, errors.New("invalid exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, errX := decodeBigInt(k.X)
+		y, errY := decodeBigInt(k.Y)
+		if errX != nil || errY != nil || !curve.IsOnCurve(x, y) {
+			return nil, errors.New("invalid EC point")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, nil
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, nil
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty value")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jwtLeeway tolerates clock skew between the token issuer and this server
+const jwtLeeway = 30 * time.Second
+
+// JWTConfig configures OIDC / JWT bearer token validation
+type JWTConfig struct {
+	// Issuer and Audience must match the token's iss and aud claims
+	Issuer   string
+	Audience string
+	// UsernameClaim names the claim used as the caller's name; "sub" is used
+	// when it is empty or missing from a token
+	UsernameClaim string
+	// RolesClaim names a claim holding a role or list of roles. Values that
+	// are not known roles are ignored.
+	RolesClaim string
+	// DefaultRole is granted when a token carries no known role; empty
+	// grants nothing
+	DefaultRole Role
+}
+
+// JWTAuthenticator validates signed JWTs, such as OIDC ID tokens, against
+// the keys of a JWKS
+type JWTAuthenticator struct {
+	config JWTConfig
+	keys   *JWKS
+	parser *jwt.Parser
+}
+
+// NewJWTAuthenticator creates an authenticator for tokens issued by
+// config.Issuer for config.Audience and signed by a key in keys
+func NewJWTAuthenticator(config JWTConfig, keys *JWKS) (*JWTAuthenticator, error) {
+	if config.Issuer == "" || config.Audience == "" {
+		return nil, errors.New("JWT validation requires an issuer and an audience")
+	}
+	return &JWTAuthenticator{
+		config: config,
+		keys:   keys,
+		parser: jwt.NewParser(
+			// Only asymmetric algorithms, so a public key can never be
+			// misused as an HMAC secret
+			jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}),
+			jwt.WithIssuer(config.Issuer),
+			jwt.WithAudience(config.Audience),
+			jwt.WithExpirationRequired(),
+			jwt.WithLeeway(jwtLeeway),
+		),
+	}, nil
+}
+
+// Authenticate verifies the token's signature, issuer, audience and expiry
+// and maps its claims to an Identity
+func (a *JWTAuthenticator) Authenticate(ctx context.Context, token string) (*Identity, error) {
+	claims := jwt.MapClaims{}
+	_, err := a.parser.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return a.keys.Key(ctx, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+
+	name, _ := claims[a.config.UsernameClaim].(string)
+	if name == "" {
+		name, _ = claims["sub"].(string)
+	}
+	if name == "" {
+		return nil, fmt.Errorf("%w: token has no subject", ErrInvalidCredentials)
+	}
+	return &Identity{Name: name, Roles: a.roles(claims)}, nil
+}
+
+func (a *JWTAuthenticator) roles(claims jwt.MapClaims) []Role {
+	var values []interface{}
+	switch v := claims[a.config.RolesClaim].(type) {
+	case string:
+		values = []interface{}{v}
+	case []interface{}:
+		values = v
+	}
+
+	var roles []Role
+	for _, v := range values {
+		s, _ := v.(string)
+		if role, err := ParseRole(s); err == nil {
+			roles = append(roles, role)
+		}
+	}
+	if len(roles) == 0 && a.config.DefaultRole != "" {
+		roles = []Role{a.config.DefaultRole}
+	}
+	return roles
+}
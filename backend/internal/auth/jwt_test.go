@@ -0,0 +1,279 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testIssuer   = "https://issuer.example.com"
+	testAudience = "0xhub"
+)
+
+// jwkJSON encodes a public key as a JWK
+func jwkJSON(t *testing.T, kid string, key crypto.PublicKey) map[string]string {
+	t.Helper()
+	enc := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return map[string]string{"kty": "RSA", "kid": kid, "use": "sig", "n": enc(k.N.Bytes()), "e": enc(big.NewInt(int64(k.E)).Bytes())}
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+		return map[string]string{"kty": "EC", "kid": kid, "crv": k.Curve.Params().Name, "x": enc(k.X.FillBytes(make([]byte, size))), "y": enc(k.Y.FillBytes(make([]byte, size)))}
+	}
+	t.Fatalf("unsupported key type %T", key)
+	return nil
+}
+
+func jwksJSON(t *testing.T, keys ...map[string]string) []byte {
+	t.Helper()
+	data, err := json.Marshal(map[string]interface{}{"keys": keys})
+	require.NoError(t, err)
+	return data
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, key crypto.PrivateKey, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":   testIssuer,
+		"aud":   testAudience,
+		"sub":   "user-123",
+		"email": "alice@example.com",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": []string{"editor", "unknown"},
+	}
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, jwksJSON(t, jwkJSON(t, "rsa", &rsaKey.PublicKey), jwkJSON(t, "ec", &ecKey.PublicKey)), 0o600))
+	keys, err := NewJWKS(context.Background(), path, time.Hour)
+	require.NoError(t, err)
+
+	authenticator, err := NewJWTAuthenticator(JWTConfig{
+		Issuer:        testIssuer,
+		Audience:      testAudience,
+		UsernameClaim: "email",
+		RolesClaim:    "roles",
+		DefaultRole:   RoleViewer,
+	}, keys)
+	require.NoError(t, err)
+
+	identity, err := authenticator.Authenticate(context.Background(), signToken(t, jwt.SigningMethodRS256, rsaKey, "rsa", validClaims()))
+	require.NoError(t, err)
+	assert.Equal(t, &Identity{Name: "alice@example.com", Roles: []Role{RoleEditor}}, identity)
+
+	// A token without known roles gets the default role, and falls back to
+	// sub without the username claim
+	claims := validClaims()
+	delete(claims, "email")
+	claims["roles"] = "superuser"
+	identity, err = authenticator.Authenticate(context.Background(), signToken(t, jwt.SigningMethodES256, ecKey, "ec", claims))
+	require.NoError(t, err)
+	assert.Equal(t, &Identity{Name: "user-123", Roles: []Role{RoleViewer}}, identity)
+
+	invalid := map[string]string{
+		"wrong issuer":     signToken(t, jwt.SigningMethodRS256, rsaKey, "rsa", withClaim("iss", "https://evil.example.com")),
+		"wrong audience":   signToken(t, jwt.SigningMethodRS256, rsaKey, "rsa", withClaim("aud", "other")),
+		"expired":          signToken(t, jwt.SigningMethodRS256, rsaKey, "rsa", withClaim("exp", time.Now().Add(-time.Hour).Unix())),
+		"no expiry":        signToken(t, jwt.SigningMethodRS256, rsaKey, "rsa", withClaim("exp", nil)),
+		"unknown key":      signToken(t, jwt.SigningMethodRS256, otherKey, "other", validClaims()),
+		"wrong key for id": signToken(t, jwt.SigningMethodRS256, otherKey, "rsa", validClaims()),
+		"HMAC":             signToken(t, jwt.SigningMethodHS256, []byte("secret"), "rsa", validClaims()),
+		"none":             signToken(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "rsa", validClaims()),
+		"malformed":        "not.a.token",
+	}
+	for name, token := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := authenticator.Authenticate(context.Background(), token)
+			assert.ErrorIs(t, err, ErrInvalidCredentials)
+		})
+	}
+}
+
+func withClaim(name string, value interface{}) jwt.MapClaims {
+	claims := validClaims()
+	if value == nil {
+		delete(claims, name)
+	} else {
+		claims[name] = value
+	}
+	return claims
+}
+
+func TestParseJWKS_SkipsUnusableKeys(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	broken := map[string]string{"kty": "EC", "kid": "broken", "crv": "P-256", "x": "!", "y": "!"}
+
+	keys, err := parseJWKS(jwksJSON(t, broken, jwkJSON(t, "rsa", &key.PublicKey)))
+	require.NoError(t, err)
+	assert.Len(t, keys, 1)
+	assert.Contains(t, keys, "rsa")
+
+	_, err = parseJWKS(jwksJSON(t, broken))
+	assert.EqualError(t, err, "no signing keys")
+}
+
+func TestJWKS_RefetchesOnUnknownKey(t *testing.T) {
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var rotated atomic.Bool
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		if rotated.Load() {
+			w.Write(jwksJSON(t, jwkJSON(t, "new", &newKey.PublicKey)))
+			return
+		}
+		w.Write(jwksJSON(t, jwkJSON(t, "old", &oldKey.PublicKey)))
+	}))
+	defer server.Close()
+
+	keys, err := NewJWKS(context.Background(), server.URL, time.Hour)
+	require.NoError(t, err)
+	authenticator, err := NewJWTAuthenticator(JWTConfig{Issuer: testIssuer, Audience: testAudience}, keys)
+	require.NoError(t, err)
+
+	_, err = authenticator.Authenticate(context.Background(), signToken(t, jwt.SigningMethodRS256, oldKey, "old", validClaims()))
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), fetches.Load())
+
+	// A freshly fetched set is not refetched for unknown key IDs
+	rotated.Store(true)
+	newToken := signToken(t, jwt.SigningMethodRS256, newKey, "new", validClaims())
+	_, err = authenticator.Authenticate(context.Background(), newToken)
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	assert.Equal(t, int32(1), fetches.Load())
+
+	// Once it is old enough, an unknown key ID picks up the rotated set
+	keys.mu.Lock()
+	keys.attemptedAt = time.Now().Add(-minJWKSRefetch)
+	keys.mu.Unlock()
+	_, err = authenticator.Authenticate(context.Background(), newToken)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), fetches.Load())
+}
+
+func TestJWKS_ReloadsOutsideRequests(t *testing.T) {
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fetches.Add(1) == 1 {
+			w.Write(jwksJSON(t, jwkJSON(t, "old", &oldKey.PublicKey)))
+			return
+		}
+		<-release
+		w.Write(jwksJSON(t, jwkJSON(t, "old", &oldKey.PublicKey), jwkJSON(t, "new", &newKey.PublicKey)))
+	}))
+	defer server.Close()
+	defer close(release)
+
+	keys, err := NewJWKS(context.Background(), server.URL, time.Hour)
+	require.NoError(t, err)
+	age := func() {
+		keys.mu.Lock()
+		keys.fetchedAt = time.Now().Add(-2 * time.Hour)
+		keys.attemptedAt = keys.fetchedAt
+		keys.mu.Unlock()
+	}
+
+	// An expired set starts a reload but keeps serving its keys meanwhile
+	age()
+	key, err := keys.Key(context.Background(), "old")
+	require.NoError(t, err)
+	assert.Equal(t, &oldKey.PublicKey, key)
+	require.Eventually(t, func() bool { return fetches.Load() == 2 }, time.Second, time.Millisecond)
+
+	// Unknown key IDs wait for the running reload rather than starting
+	// their own, and a canceled request does not cancel it
+	age()
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = keys.Key(canceled, "new")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	results := make(chan crypto.PublicKey, 5)
+	for range 5 {
+		go func() {
+			key, err := keys.Key(context.Background(), "new")
+			assert.NoError(t, err)
+			results <- key
+		}()
+	}
+	release <- struct{}{}
+	for range 5 {
+		assert.Equal(t, &newKey.PublicKey, <-results)
+	}
+	assert.Equal(t, int32(2), fetches.Load())
+}
+
+func TestChain(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, jwksJSON(t, jwkJSON(t, "", &key.PublicKey)), 0o600))
+	keys, err := NewJWKS(context.Background(), path, time.Hour)
+	require.NoError(t, err)
+	jwtAuthenticator, err := NewJWTAuthenticator(JWTConfig{Issuer: testIssuer, Audience: testAudience}, keys)
+	require.NoError(t, err)
+	apiKeys, err := NewAPIKeys("ci=ci-token", "")
+	require.NoError(t, err)
+
+	authenticator := Chain(apiKeys, jwtAuthenticator)
+
+	identity, err := authenticator.Authenticate(context.Background(), "ci-token")
+	require.NoError(t, err)
+	assert.Equal(t, "ci", identity.Name)
+
+	// A key set with a single key also matches tokens without a key ID
+	identity, err = authenticator.Authenticate(context.Background(), signToken(t, jwt.SigningMethodES256, key, "", validClaims()))
+	require.NoError(t, err)
+	assert.Equal(t, "user-123", identity.Name)
+
+	_, err = authenticator.Authenticate(context.Background(), "other-token")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Role is a named set of permissions granted to a caller
+type Role string
+
+// Roles a caller can hold
+const (
+	// RoleViewer can only read
+	RoleViewer Role = "viewer"
+	// RoleEditor manages projects created through the API
+	RoleEditor Role = "editor"
+	// RoleOperator manages the projects it syncs from Project resources
+	RoleOperator Role = "operator"
+	// RoleAdmin can do everything
+	RoleAdmin Role = "admin"
+)
+
+// Permission is a single action a role may allow
+type Permission string
+
+// Permissions checked by the API
+const (
+	// PermissionRead allows reading projects
+	PermissionRead Permission = "projects:read"
+	// PermissionWrite allows writing projects not managed by the operator
+	PermissionWrite Permission = "projects:write"
+	// PermissionWriteManaged allows writing projects managed by the operator
+	PermissionWriteManaged Permission = "projects:write-managed"
+	// PermissionAdmin allows administrative endpoints
+	PermissionAdmin Permission = "admin"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleViewer:   {PermissionRead},
+	RoleEditor:   {PermissionRead, PermissionWrite},
+	RoleOperator: {PermissionRead, PermissionWriteManaged},
+	RoleAdmin:    {PermissionRead, PermissionWrite, PermissionWriteManaged, PermissionAdmin},
+}
+
+// ParseRole validates a role name
+func ParseRole(s string) (Role, error) {
+	role := Role(strings.ToLower(strings.TrimSpace(s)))
+	if _, ok := rolePermissions[role]; !ok {
+		return "", fmt.Errorf("unknown role %q, expected viewer, editor, operator or admin", s)
+	}
+	return role, nil
+}
+
+// Can reports whether any of the identity's roles grants permission
+func (i *Identity) Can(permission Permission) bool {
+	for _, role := range i.Roles {
+		if slices.Contains(rolePermissions[role], permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireAnyPermission returns middleware that lets a request through only
+// if the identity recorded by Require holds at least one of permissions
+func RequireAnyPermission(permissions ...Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity := IdentityFrom(c)
+		if identity == nil {
+			unauthorized(c, "a bearer token is required")
+			return
+		}
+		for _, permission := range permissions {
+			if identity.Can(permission) {
+				c.Next()
+				return
+			}
+		}
+		Forbidden(c, identity, permissions[0], "")
+	}
+}
+
+// Forbidden responds with 403, naming the permission the caller is missing.
+// reason optionally explains why that permission is needed.
+func Forbidden(c *gin.Context, identity *Identity, permission Permission, reason string) {
+	roles := make([]string, 0, len(identity.Roles))
+	for _, role := range identity.Roles {
+		roles = append(roles, string(role))
+	}
+	message := fmt.Sprintf("%q with roles [%s] lacks the %s permission", identity.Name, strings.Join(roles, ", "), permission)
+	if reason != "" {
+		message += ": " + reason
+	}
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+		"error":             message,
+		"missingPermission": permission,
+	})
+}
@@ -0,0 +1,341 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// In-cluster locations of the API server credentials, as mounted into every
+// pod with a ServiceAccount
+const (
+	inClusterTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	inClusterCAFile    = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+)
+
+// serviceAccountUserPrefix starts the username Kubernetes gives ServiceAccounts
+const serviceAccountUserPrefix = "system:serviceaccount:"
+
+// Defaults for TokenReviewConfig
+const (
+	// DefaultTokenReviewCacheTTL is how long a successful review is reused
+	DefaultTokenReviewCacheTTL = time.Minute
+	// DefaultTokenReviewNegativeCacheTTL is how long a rejected token stays
+	// rejected without another review
+	DefaultTokenReviewNegativeCacheTTL = 10 * time.Second
+	// DefaultMaxConcurrentTokenReviews is how many reviews may be in flight
+	DefaultMaxConcurrentTokenReviews = 10
+)
+
+// maxCachedTokenReviews bounds the review cache; once it is full, further
+// reviews are not cached until entries expire
+const maxCachedTokenReviews = 10000
+
+// TokenReviewConfig configures Kubernetes ServiceAccount token authentication
+type TokenReviewConfig struct {
+	// Host is the Kubernetes API server URL
+	Host string
+	// TokenFile holds the token this server presents to the API server. It
+	// is reread for every review so rotated tokens are picked up.
+	TokenFile string
+	// CAFile verifies the API server's certificate; empty uses system roots
+	CAFile string
+	// Audience the reviewed tokens must be issued for; empty accepts the
+	// API server's default audience
+	Audience string
+	// ServiceAccounts maps each allowed "namespace/name" to its role
+	ServiceAccounts map[string]Role
+	// CacheTTL is how long a successful review is reused; zero uses
+	// DefaultTokenReviewCacheTTL
+	CacheTTL time.Duration
+	// NegativeCacheTTL is how long a rejected token is rejected without
+	// another review, so retries with a bad token don't each reach the API
+	// server; zero uses DefaultTokenReviewNegativeCacheTTL
+	NegativeCacheTTL time.Duration
+	// MaxConcurrentReviews bounds the reviews in flight; further requests
+	// wait for one to finish. Zero uses DefaultMaxConcurrentTokenReviews.
+	MaxConcurrentReviews int
+}
+
+// InClusterTokenReviewConfig returns a config that reaches the API server
+// with the credentials of the pod this server runs in
+func InClusterTokenReviewConfig() (TokenReviewConfig, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return TokenReviewConfig{}, errors.New("not running in a Kubernetes cluster: KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT are not set")
+	}
+	return TokenReviewConfig{
+		Host:      "https://" + net.JoinHostPort(host, port),
+		TokenFile: inClusterTokenFile,
+		CAFile:    inClusterCAFile,
+	}, nil
+}
+
+// ParseServiceAccounts parses comma-separated "namespace/name" or
+// "namespace/name:role" entries. Entries without a role get RoleOperator.
+func ParseServiceAccounts(s string) (map[string]Role, error) {
+	accounts := make(map[string]Role)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		account, roleName, hasRole := strings.Cut(entry, ":")
+		namespace, name, ok := strings.Cut(account, "/")
+		if !ok || namespace == "" || name == "" || strings.Contains(name, "/") {
+			return nil, fmt.Errorf("malformed service account %q, expected namespace/name[:role]", entry)
+		}
+		role := RoleOperator
+		if hasRole {
+			parsed, err := ParseRole(roleName)
+			if err != nil {
+				return nil, fmt.Errorf("service account %q: %w", account, err)
+			}
+			role = parsed
+		}
+		accounts[account] = role
+	}
+	return accounts, nil
+}
+
+// TokenReviewAuthenticator authenticates Kubernetes ServiceAccount tokens by
+// submitting them to the API server's TokenReview API. Only allow-listed
+// ServiceAccounts are accepted.
+type TokenReviewAuthenticator struct {
+	config TokenReviewConfig
+	client *http.Client
+
+	// reviews holds a token for every review in flight
+	reviews chan struct{}
+
+	mu    sync.Mutex
+	cache map[[sha256.Size]byte]cachedReview
+}
+
+// cachedReview is the outcome of a review: the identity of an accepted token
+// or the error a rejected one failed with
+type cachedReview struct {
+	identity Identity
+	err      error
+	expires  time.Time
+}
+
+func (r cachedReview) result() (*Identity, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	identity := r.identity
+	return &identity, nil
+}
+
+// NewTokenReviewAuthenticator creates an authenticator that reviews tokens
+// with the API server described by config
+func NewTokenReviewAuthenticator(config TokenReviewConfig) (*TokenReviewAuthenticator, error) {
+	if len(config.ServiceAccounts) == 0 {
+		return nil, errors.New("TokenReview authentication requires at least one allowed service account")
+	}
+	if config.CacheTTL <= 0 {
+		config.CacheTTL = DefaultTokenReviewCacheTTL
+	}
+	if config.NegativeCacheTTL <= 0 {
+		config.NegativeCacheTTL = DefaultTokenReviewNegativeCacheTTL
+	}
+	if config.MaxConcurrentReviews <= 0 {
+		config.MaxConcurrentReviews = DefaultMaxConcurrentTokenReviews
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if config.CAFile != "" {
+		pem, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read API server CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", config.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return &TokenReviewAuthenticator{
+		config: config,
+		client: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		},
+		reviews: make(chan struct{}, config.MaxConcurrentReviews),
+		cache:   make(map[[sha256.Size]byte]cachedReview),
+	}, nil
+}
+
+// tokenReview is the subset of authentication.k8s.io/v1 TokenReview used here
+type tokenReview struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Spec       struct {
+		Token     string   `json:"token"`
+		Audiences []string `json:"audiences,omitempty"`
+	} `json:"spec"`
+	Status struct {
+		Authenticated bool `json:"authenticated"`
+		User          struct {
+			Username string `json:"username"`
+		} `json:"user"`
+		Audiences []string `json:"audiences"`
+		Error     string   `json:"error"`
+	} `json:"status"`
+}
+
+// Authenticate reviews token and accepts it if it belongs to an allowed
+// ServiceAccount
+func (a *TokenReviewAuthenticator) Authenticate(ctx context.Context, token string) (*Identity, error) {
+	// ServiceAccount tokens are JWTs; don't send anything else to the API server
+	if strings.Count(token, ".") != 2 {
+		return nil, ErrInvalidCredentials
+	}
+
+	sum := sha256.Sum256([]byte(token))
+	if entry, ok := a.cached(sum); ok {
+		return entry.result()
+	}
+
+	select {
+	case a.reviews <- struct{}{}:
+		defer func() { <-a.reviews }()
+	case <-ctx.Done():
+		return nil, fmt.Errorf("gave up waiting to review token: %w", ctx.Err())
+	}
+	// Another request may have reviewed the same token while this one waited
+	if entry, ok := a.cached(sum); ok {
+		return entry.result()
+	}
+
+	review, err := a.review(ctx, token)
+	if err != nil {
+		// The token may be fine; only rejections are cached
+		return nil, err
+	}
+	identity, err := a.accept(review)
+	if err != nil {
+		a.store(sum, cachedReview{err: err, expires: time.Now().Add(a.config.NegativeCacheTTL)})
+		return nil, err
+	}
+	a.store(sum, cachedReview{identity: *identity, expires: time.Now().Add(a.config.CacheTTL)})
+	return identity, nil
+}
+
+// accept returns the identity of a reviewed token, or why it is rejected
+func (a *TokenReviewAuthenticator) accept(review *tokenReview) (*Identity, error) {
+	if !review.Status.Authenticated {
+		return nil, fmt.Errorf("%w: token review failed: %s", ErrInvalidCredentials, review.Status.Error)
+	}
+	if a.config.Audience != "" && !slices.Contains(review.Status.Audiences, a.config.Audience) {
+		return nil, fmt.Errorf("%w: token is not issued for %q", ErrInvalidCredentials, a.config.Audience)
+	}
+
+	username := review.Status.User.Username
+	account, ok := strings.CutPrefix(username, serviceAccountUserPrefix)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q is not a service account", ErrInvalidCredentials, username)
+	}
+	// account is "namespace:name"
+	account = strings.Replace(account, ":", "/", 1)
+	role, ok := a.config.ServiceAccounts[account]
+	if !ok {
+		return nil, fmt.Errorf("%w: service account %s is not allowed", ErrInvalidCredentials, account)
+	}
+	return &Identity{Name: username, Roles: []Role{role}}, nil
+}
+
+func (a *TokenReviewAuthenticator) review(ctx context.Context, token string) (*tokenReview, error) {
+	request := tokenReview{APIVersion: "authentication.k8s.io/v1", Kind: "TokenReview"}
+	request.Spec.Token = token
+	if a.config.Audience != "" {
+		request.Spec.Audiences = []string{a.config.Audience}
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		strings.TrimSuffix(a.config.Host, "/")+"/apis/authentication.k8s.io/v1/tokenreviews", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token review: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if a.config.TokenFile != "" {
+		credential, err := os.ReadFile(a.config.TokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read API server token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(credential)))
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit token review: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token review: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("token review failed with status %d: %s", resp.StatusCode, data)
+	}
+
+	var review tokenReview
+	if err := json.Unmarshal(data, &review); err != nil {
+		return nil, fmt.Errorf("failed to decode token review: %w", err)
+	}
+	return &review, nil
+}
+
+// cached returns the unexpired outcome of an earlier review of the token
+// with the given hash
+func (a *TokenReviewAuthenticator) cached(sum [sha256.Size]byte) (cachedReview, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	entry, ok := a.cache[sum]
+	if !ok {
+		return cachedReview{}, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(a.cache, sum)
+		return cachedReview{}, false
+	}
+	return entry, true
+}
+
+func (a *TokenReviewAuthenticator) store(sum [sha256.Size]byte, review cachedReview) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+	// Projected tokens rotate, so drop stale entries rather than letting the
+	// cache grow without bound
+	for key, entry := range a.cache {
+		if now.After(entry.expires) {
+			delete(a.cache, key)
+		}
+	}
+	if len(a.cache) >= maxCachedTokenReviews {
+		return
+	}
+	a.cache[sum] = review
+}
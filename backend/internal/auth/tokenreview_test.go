@@ -0,0 +1,222 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTokenReviewServer authenticates the tokens in users, keyed by token
+// and mapped to a username, for the "0xhub-backend" audience
+func fakeTokenReviewServer(t *testing.T, users map[string]string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var reviews atomic.Int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/apis/authentication.k8s.io/v1/tokenreviews", r.URL.Path)
+		assert.Equal(t, "Bearer backend-credential", r.Header.Get("Authorization"))
+		reviews.Add(1)
+
+		var review tokenReview
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&review))
+		if username, ok := users[review.Spec.Token]; ok {
+			review.Status.Authenticated = true
+			review.Status.User.Username = username
+			review.Status.Audiences = []string{"0xhub-backend"}
+		} else {
+			review.Status.Error = "invalid bearer token"
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(review)
+	}))
+	t.Cleanup(server.Close)
+	return server, &reviews
+}
+
+func newTestTokenReviewConfig(t *testing.T, server *httptest.Server) TokenReviewConfig {
+	t.Helper()
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("backend-credential\n"), 0o600))
+	caFile := filepath.Join(dir, "ca.crt")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caFile, caPEM, 0o600))
+
+	accounts, err := ParseServiceAccounts("0xhub/0xhub-operator, tools/ci:viewer")
+	require.NoError(t, err)
+	return TokenReviewConfig{
+		Host:            server.URL,
+		TokenFile:       tokenFile,
+		CAFile:          caFile,
+		Audience:        "0xhub-backend",
+		ServiceAccounts: accounts,
+	}
+}
+
+func TestTokenReviewAuthenticator(t *testing.T) {
+	server, reviews := fakeTokenReviewServer(t, map[string]string{
+		"operator.sa.token": "system:serviceaccount:0xhub:0xhub-operator",
+		"ci.sa.token":       "system:serviceaccount:tools:ci",
+		"other.sa.token":    "system:serviceaccount:default:default",
+		"user.jwt.token":    "alice",
+	})
+	authenticator, err := NewTokenReviewAuthenticator(newTestTokenReviewConfig(t, server))
+	require.NoError(t, err)
+
+	identity, err := authenticator.Authenticate(context.Background(), "operator.sa.token")
+	require.NoError(t, err)
+	assert.Equal(t, &Identity{Name: "system:serviceaccount:0xhub:0xhub-operator", Roles: []Role{RoleOperator}}, identity)
+
+	identity, err = authenticator.Authenticate(context.Background(), "ci.sa.token")
+	require.NoError(t, err)
+	assert.Equal(t, []Role{RoleViewer}, identity.Roles)
+
+	for _, token := range []string{"other.sa.token", "user.jwt.token", "unknown.sa.token"} {
+		_, err := authenticator.Authenticate(context.Background(), token)
+		assert.ErrorIs(t, err, ErrInvalidCredentials, token)
+	}
+
+	// Tokens that are not JWTs never reach the API server
+	before := reviews.Load()
+	_, err = authenticator.Authenticate(context.Background(), "static-api-key")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	assert.Equal(t, before, reviews.Load())
+}
+
+func TestTokenReviewAuthenticator_CachesReviews(t *testing.T) {
+	server, reviews := fakeTokenReviewServer(t, map[string]string{
+		"operator.sa.token": "system:serviceaccount:0xhub:0xhub-operator",
+	})
+	config := newTestTokenReviewConfig(t, server)
+	config.CacheTTL = 50 * time.Millisecond
+	authenticator, err := NewTokenReviewAuthenticator(config)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, err := authenticator.Authenticate(context.Background(), "operator.sa.token")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), reviews.Load())
+
+	time.Sleep(60 * time.Millisecond)
+	_, err = authenticator.Authenticate(context.Background(), "operator.sa.token")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), reviews.Load())
+}
+
+func TestTokenReviewAuthenticator_CachesRejections(t *testing.T) {
+	server, reviews := fakeTokenReviewServer(t, map[string]string{
+		"other.sa.token": "system:serviceaccount:default:default",
+	})
+	config := newTestTokenReviewConfig(t, server)
+	config.NegativeCacheTTL = 50 * time.Millisecond
+	authenticator, err := NewTokenReviewAuthenticator(config)
+	require.NoError(t, err)
+
+	for _, token := range []string{"other.sa.token", "unknown.sa.token"} {
+		_, first := authenticator.Authenticate(context.Background(), token)
+		require.ErrorIs(t, first, ErrInvalidCredentials, token)
+		for i := 0; i < 2; i++ {
+			_, err := authenticator.Authenticate(context.Background(), token)
+			assert.Equal(t, first, err, "Cached rejections keep their reason")
+		}
+	}
+	assert.Equal(t, int32(2), reviews.Load())
+
+	time.Sleep(60 * time.Millisecond)
+	_, err = authenticator.Authenticate(context.Background(), "unknown.sa.token")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	assert.Equal(t, int32(3), reviews.Load())
+}
+
+func TestTokenReviewAuthenticator_LimitsConcurrentReviews(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			highest := maxInFlight.Load()
+			if n <= highest || maxInFlight.CompareAndSwap(highest, n) {
+				break
+			}
+		}
+		<-release
+
+		var review tokenReview
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&review))
+		review.Status.Authenticated = true
+		review.Status.User.Username = "system:serviceaccount:0xhub:0xhub-operator"
+		review.Status.Audiences = []string{"0xhub-backend"}
+		json.NewEncoder(w).Encode(review)
+	}))
+	defer server.Close()
+	config := newTestTokenReviewConfig(t, server)
+	config.MaxConcurrentReviews = 2
+	authenticator, err := NewTokenReviewAuthenticator(config)
+	require.NoError(t, err)
+
+	results := make(chan error, 5)
+	for i := range 5 {
+		go func() {
+			_, err := authenticator.Authenticate(context.Background(), fmt.Sprintf("token.sa.%d", i))
+			results <- err
+		}()
+	}
+	require.Eventually(t, func() bool { return inFlight.Load() == 2 }, time.Second, time.Millisecond)
+
+	// Requests waiting for a review give up with their context
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = authenticator.Authenticate(ctx, "waiting.sa.token")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.NotErrorIs(t, err, ErrInvalidCredentials)
+
+	close(release)
+	for range 5 {
+		assert.NoError(t, <-results)
+	}
+	assert.Equal(t, int32(2), maxInFlight.Load())
+}
+
+func TestTokenReviewAuthenticator_APIServerError(t *testing.T) {
+	var reviews atomic.Int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reviews.Add(1)
+		http.Error(w, "forbidden", http.StatusForbidden)
+	}))
+	defer server.Close()
+	authenticator, err := NewTokenReviewAuthenticator(newTestTokenReviewConfig(t, server))
+	require.NoError(t, err)
+
+	// Failing to review is a server error, not a rejected credential, so it
+	// is not cached either
+	for i := 0; i < 2; i++ {
+		_, err = authenticator.Authenticate(context.Background(), "operator.sa.token")
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrInvalidCredentials)
+		assert.Contains(t, err.Error(), "status 403")
+	}
+	assert.Equal(t, int32(2), reviews.Load())
+}
+
+func TestParseServiceAccounts(t *testing.T) {
+	accounts, err := ParseServiceAccounts("0xhub/0xhub-operator,tools/ci:admin,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Role{"0xhub/0xhub-operator": RoleOperator, "tools/ci": RoleAdmin}, accounts)
+
+	for _, invalid := range []string{"operator", "/name", "ns/", "ns/name:root", "a/b/c"} {
+		_, err := ParseServiceAccounts(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
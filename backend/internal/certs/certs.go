@@ -0,0 +1,147 @@
+package certs
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Reloader serves a TLS certificate and key, and the CA bundle client
+// certificates must chain to, from PEM files. The files are reread when
+// they change, so renewed certificates, such as an updated Kubernetes
+// Secret, take effect for new connections without a restart.
+type Reloader struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	// loaded holds the file contents the certificates were last built from
+	loaded [][]byte
+}
+
+// NewReloader loads the certificate and key in certFile and keyFile and,
+// if clientCAFile is not empty, the CA bundle client certificates are
+// verified against
+func NewReloader(certFile, keyFile, clientCAFile string) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile, clientCAFile: clientCAFile}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// RequiresClientCerts reports whether clients must present a certificate
+func (r *Reloader) RequiresClientCerts() bool {
+	return r.clientCAFile != ""
+}
+
+// Reload rereads the files. On error the previous certificates stay in
+// effect.
+func (r *Reloader) Reload() error {
+	contents := make([][]byte, 0, 3)
+	for _, path := range []string{r.certFile, r.keyFile, r.clientCAFile} {
+		if path == "" {
+			contents = append(contents, nil)
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read TLS file: %w", err)
+		}
+		contents = append(contents, data)
+	}
+
+	r.mu.RLock()
+	unchanged := r.cert != nil && equalContents(contents, r.loaded)
+	r.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	cert, err := tls.X509KeyPair(contents[0], contents[1])
+	if err != nil {
+		return fmt.Errorf("invalid certificate %s or key %s: %w", r.certFile, r.keyFile, err)
+	}
+	var clientCAs *x509.CertPool
+	if r.clientCAFile != "" {
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(contents[2]) {
+			return fmt.Errorf("no certificates found in client CA bundle %s", r.clientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.loaded = contents
+	return nil
+}
+
+// Watch reloads the files every interval until ctx is done
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Reload(); err != nil {
+				log.Println("Failed to reload TLS certificates, keeping the previous ones:", err)
+			}
+		}
+	}
+}
+
+// TLSConfig returns a server configuration using the current certificates
+// for every new connection. With a client CA bundle, every client must
+// present a certificate it signed.
+func (r *Reloader) TLSConfig() *tls.Config {
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: []string{"h2", "http/1.1"},
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+
+			return r.cert, nil
+		},
+	}
+	if r.RequiresClientCerts() {
+		// ClientCAs has no callback, so each connection gets a copy of the
+		// configuration holding the current bundle
+		config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+
+			c := config.Clone()
+			c.GetConfigForClient = nil
+			c.ClientAuth = tls.RequireAndVerifyClientCert
+			c.ClientCAs = r.clientCAs
+			return c, nil
+		}
+	}
+	return config
+}
+
+func equalContents(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
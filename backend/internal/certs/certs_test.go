@@ -0,0 +1,219 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCA issues certificates for tests
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate CA key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create CA certificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns the PEM certificate and key of a leaf named commonName
+func (ca *testCA) issue(t *testing.T, commonName string, usage x509.ExtKeyUsage) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+// serve runs an HTTPS server with r's configuration and returns its URL
+func serve(t *testing.T, r *Reloader) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	server := &http.Server{
+		Handler:   http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}),
+		TLSConfig: r.TLSConfig(),
+	}
+	go server.ServeTLS(ln, "", "")
+	t.Cleanup(func() { server.Close() })
+	return "https://" + ln.Addr().String()
+}
+
+// get requests url trusting ca, presenting clientCert if it is not nil, and
+// returns the common name of the server's certificate
+func get(url string, ca *testCA, clientCert *tls.Certificate) (string, error) {
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	config := &tls.Config{RootCAs: roots}
+	if clientCert != nil {
+		config.Certificates = []tls.Certificate{*clientCert}
+	}
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: config, DisableKeepAlives: true}}
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	return resp.TLS.PeerCertificates[0].Subject.CommonName, nil
+}
+
+func TestReloader_ServesAndReloads(t *testing.T) {
+	ca := newTestCA(t)
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	cert, key := ca.issue(t, "first", x509.ExtKeyUsageServerAuth)
+	writeFile(t, certFile, cert)
+	writeFile(t, keyFile, key)
+
+	r, err := NewReloader(certFile, keyFile, "")
+	if err != nil {
+		t.Fatalf("NewReloader failed: %v", err)
+	}
+	url := serve(t, r)
+	if name, err := get(url, ca, nil); err != nil || name != "first" {
+		t.Fatalf("Expected the first certificate, got %q (%v)", name, err)
+	}
+	if _, err := get(url, newTestCA(t), nil); err == nil {
+		t.Fatal("Expected a client trusting another CA to fail")
+	}
+
+	cert, key = ca.issue(t, "second", x509.ExtKeyUsageServerAuth)
+	writeFile(t, certFile, cert)
+	writeFile(t, keyFile, key)
+	if err := r.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if name, err := get(url, ca, nil); err != nil || name != "second" {
+		t.Fatalf("Expected the renewed certificate, got %q (%v)", name, err)
+	}
+
+	// A key that does not match is rejected and the current pair kept
+	_, otherKey := ca.issue(t, "third", x509.ExtKeyUsageServerAuth)
+	writeFile(t, keyFile, otherKey)
+	if err := r.Reload(); err == nil {
+		t.Fatal("Expected a mismatched key to fail")
+	}
+	if name, err := get(url, ca, nil); err != nil || name != "second" {
+		t.Fatalf("Expected the previous certificate to stay in use, got %q (%v)", name, err)
+	}
+}
+
+func TestReloader_RequiresClientCerts(t *testing.T) {
+	serverCA, clientCA := newTestCA(t), newTestCA(t)
+	dir := t.TempDir()
+	certFile, keyFile, caFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), filepath.Join(dir, "ca.crt")
+	cert, key := serverCA.issue(t, "server", x509.ExtKeyUsageServerAuth)
+	writeFile(t, certFile, cert)
+	writeFile(t, keyFile, key)
+	writeFile(t, caFile, clientCA.pem)
+
+	r, err := NewReloader(certFile, keyFile, caFile)
+	if err != nil {
+		t.Fatalf("NewReloader failed: %v", err)
+	}
+	if !r.RequiresClientCerts() {
+		t.Fatal("Expected client certificates to be required")
+	}
+	url := serve(t, r)
+
+	if _, err := get(url, serverCA, nil); err == nil {
+		t.Fatal("Expected a client without a certificate to be rejected")
+	}
+	clientPEM, clientKey := clientCA.issue(t, "operator", x509.ExtKeyUsageClientAuth)
+	clientCert, err := tls.X509KeyPair(clientPEM, clientKey)
+	if err != nil {
+		t.Fatalf("failed to load client certificate: %v", err)
+	}
+	if _, err := get(url, serverCA, &clientCert); err != nil {
+		t.Fatalf("Expected a client certificate from the CA to be accepted, got %v", err)
+	}
+	otherPEM, otherKey := serverCA.issue(t, "intruder", x509.ExtKeyUsageClientAuth)
+	otherCert, _ := tls.X509KeyPair(otherPEM, otherKey)
+	if _, err := get(url, serverCA, &otherCert); err == nil {
+		t.Fatal("Expected a client certificate from another CA to be rejected")
+	}
+
+	// A rotated client CA applies to new connections
+	writeFile(t, caFile, serverCA.pem)
+	if err := r.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if _, err := get(url, serverCA, &otherCert); err != nil {
+		t.Fatalf("Expected the rotated client CA to be used, got %v", err)
+	}
+}
+
+func TestNewReloader_Errors(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	certFile, keyFile, caFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), filepath.Join(dir, "ca.crt")
+	cert, key := ca.issue(t, "server", x509.ExtKeyUsageServerAuth)
+	writeFile(t, certFile, cert)
+	writeFile(t, keyFile, key)
+	writeFile(t, caFile, []byte("not a certificate"))
+
+	if _, err := NewReloader(certFile, filepath.Join(dir, "missing.key"), ""); err == nil {
+		t.Fatal("Expected a missing key file to fail")
+	}
+	if _, err := NewReloader(certFile, keyFile, caFile); err == nil {
+		t.Fatal("Expected a CA bundle without certificates to fail")
+	}
+}
@@ -0,0 +1,13 @@
+// Package closer closes what a decorator wraps, for decorators whose inner
+// value may or may not hold resources.
+package closer
+
+import "io"
+
+// Close closes v if it implements io.Closer and does nothing otherwise
+func Close(v interface{}) error {
+	if c, ok := v.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
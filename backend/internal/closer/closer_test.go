@@ -0,0 +1,20 @@
+package closer
+
+import (
+	"errors"
+	"testing"
+)
+
+type closeFunc func() error
+
+func (f closeFunc) Close() error { return f() }
+
+func TestClose(t *testing.T) {
+	closed := errors.New("closed")
+	if err := Close(closeFunc(func() error { return closed })); !errors.Is(err, closed) {
+		t.Fatalf("Expected the Close error, got %v", err)
+	}
+	if err := Close(struct{}{}); err != nil {
+		t.Fatalf("Expected nil for a value without Close, got %v", err)
+	}
+}
@@ -0,0 +1,319 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"0xhub/backend/internal/auth"
+	"0xhub/backend/internal/seed"
+	"0xhub/backend/internal/store"
+	"0xhub/backend/internal/webhooks"
+)
+
+// Storage backends
+const (
+	StoreMemory = "memory"
+	StoreFile   = "file"
+	StoreSQLite = "sqlite"
+)
+
+// Config is the configuration of the backend server
+type Config struct {
+	Server   ServerConfig
+	Store    StoreConfig
+	Trash    TrashConfig
+	Audit    AuditConfig
+	Seed     SeedConfig
+	Webhooks WebhooksConfig
+	Auth     AuthConfig
+
+	// sources records where each setting that is not a default came from,
+	// by key
+	sources map[string]string
+}
+
+// ServerConfig configures the HTTP server
+type ServerConfig struct {
+	// Addr is the host:port the server listens on
+	Addr string
+	// CORSOrigins are the browser origins allowed to call the API and open
+	// change feeds
+	CORSOrigins []string
+	// ReadHeaderTimeout, ReadTimeout, WriteTimeout and IdleTimeout bound
+	// each connection as in http.Server; zero disables them. Change feeds
+	// are exempt from WriteTimeout.
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+	// ShutdownDelay is how long the server keeps serving after SIGTERM,
+	// reporting itself unhealthy, so load balancers stop sending requests
+	ShutdownDelay time.Duration
+	// ShutdownTimeout bounds the wait for requests in flight to finish
+	ShutdownTimeout time.Duration
+	TLS             TLSConfig
+}
+
+// TLSConfig enables HTTPS, and optionally mutual TLS, when CertFile is set
+type TLSConfig struct {
+	// CertFile and KeyFile hold the PEM server certificate chain and key
+	CertFile string
+	KeyFile  string
+	// ClientCAFile holds the PEM CA bundle client certificates must chain
+	// to; when set, every client must present one
+	ClientCAFile string
+	// ReloadInterval is how often the files are checked for changes
+	ReloadInterval time.Duration
+}
+
+// StoreConfig selects and configures the storage backend
+type StoreConfig struct {
+	// Backend is StoreMemory, StoreFile or StoreSQLite
+	Backend    string
+	SQLitePath string
+	DataDir    string
+	// CompactThreshold is the number of journal entries after which the
+	// file store writes a new snapshot
+	CompactThreshold int
+	// RevisionHistory is the number of revisions kept for each project
+	RevisionHistory int
+}
+
+// TrashConfig configures how long deleted projects are kept
+type TrashConfig struct {
+	Retention     time.Duration
+	PurgeInterval time.Duration
+}
+
+// AuditConfig configures the audit log
+type AuditConfig struct {
+	// Log is the file events are appended to; empty keeps recent events in
+	// memory
+	Log    string
+	Stdout bool
+}
+
+// SeedConfig configures the projects the store is seeded with
+type SeedConfig struct {
+	File string
+	Mode string
+}
+
+// WebhooksConfig configures webhook subscriptions and deliveries
+type WebhooksConfig struct {
+	// File is where subscriptions are saved; empty keeps them in memory
+	File        string
+	MaxAttempts int
+	Timeout     time.Duration
+}
+
+// AuthConfig configures the credentials accepted for writes
+type AuthConfig struct {
+	// APIKeys holds inline name[:role]=token entries
+	APIKeys               string
+	APIKeysFile           string
+	APIKeysReloadInterval time.Duration
+	OIDC                  OIDCConfig
+	TokenReview           TokenReviewConfig
+}
+
+// OIDCConfig configures OIDC / JWT bearer token validation, enabled by JWKS
+type OIDCConfig struct {
+	// JWKS is a file path or URL of the keys tokens are signed with
+	JWKS                string
+	JWKSRefreshInterval time.Duration
+	Issuer              string
+	Audience            string
+	UsernameClaim       string
+	RolesClaim          string
+	// DefaultRole is granted to tokens naming no known role; empty grants
+	// nothing
+	DefaultRole string
+}
+
+// TokenReviewConfig configures Kubernetes ServiceAccount token
+// authentication, enabled by ServiceAccounts
+type TokenReviewConfig struct {
+	// ServiceAccounts holds comma-separated namespace/name[:role] entries
+	ServiceAccounts string
+	Audience        string
+}
+
+// Default returns the configuration used for every setting that is not
+// set in a config file, the environment or a flag
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Addr:              ":8080",
+			CORSOrigins:       []string{"http://localhost:5173", "http://localhost:3000"},
+			ReadHeaderTimeout: 10 * time.Second,
+			ReadTimeout:       30 * time.Second,
+			WriteTimeout:      60 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			MaxHeaderBytes:    http.DefaultMaxHeaderBytes,
+			ShutdownTimeout:   20 * time.Second,
+			TLS: TLSConfig{
+				ReloadInterval: time.Minute,
+			},
+		},
+		Store: StoreConfig{
+			Backend:          StoreMemory,
+			SQLitePath:       "0xhub.db",
+			DataDir:          "data",
+			CompactThreshold: store.DefaultCompactThreshold,
+			RevisionHistory:  store.DefaultHistorySize,
+		},
+		Trash: TrashConfig{
+			Retention:     store.DefaultTrashRetention,
+			PurgeInterval: time.Hour,
+		},
+		Seed: SeedConfig{
+			Mode: seed.ModeIfEmpty,
+		},
+		Webhooks: WebhooksConfig{
+			MaxAttempts: webhooks.DefaultMaxAttempts,
+			Timeout:     webhooks.DefaultTimeout,
+		},
+		Auth: AuthConfig{
+			APIKeysReloadInterval: 30 * time.Second,
+			OIDC: OIDCConfig{
+				JWKSRefreshInterval: time.Hour,
+				UsernameClaim:       "sub",
+				RolesClaim:          "roles",
+				DefaultRole:         string(auth.RoleViewer),
+			},
+		},
+	}
+}
+
+// Validate checks every setting and returns all problems found
+func (c *Config) Validate() error {
+	var errs []error
+	invalid := func(key, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+	positive := func(key string, d time.Duration) {
+		if d <= 0 {
+			invalid(key, "must be a positive duration")
+		}
+	}
+	notNegative := func(key string, d time.Duration) {
+		if d < 0 {
+			invalid(key, "must not be negative")
+		}
+	}
+
+	if _, port, err := net.SplitHostPort(c.Server.Addr); err != nil || port == "" {
+		invalid("server.addr", "must be host:port or :port, got %q", c.Server.Addr)
+	}
+	if len(c.Server.CORSOrigins) == 0 {
+		invalid("server.corsOrigins", "must list at least one origin")
+	}
+	for _, origin := range c.Server.CORSOrigins {
+		if origin == "*" {
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || strings.Trim(u.Path, "/") != "" {
+			invalid("server.corsOrigins", "%q is not an http(s)://host[:port] origin", origin)
+		}
+	}
+	notNegative("server.readHeaderTimeout", c.Server.ReadHeaderTimeout)
+	notNegative("server.readTimeout", c.Server.ReadTimeout)
+	notNegative("server.writeTimeout", c.Server.WriteTimeout)
+	notNegative("server.idleTimeout", c.Server.IdleTimeout)
+	if c.Server.MaxHeaderBytes <= 0 {
+		invalid("server.maxHeaderBytes", "must be positive")
+	}
+	notNegative("server.shutdownDelay", c.Server.ShutdownDelay)
+	positive("server.shutdownTimeout", c.Server.ShutdownTimeout)
+	if tls := c.Server.TLS; tls.CertFile != "" || tls.KeyFile != "" || tls.ClientCAFile != "" {
+		if tls.CertFile == "" {
+			invalid("server.tls.certFile", "is required with a TLS key or client CA")
+		}
+		if tls.KeyFile == "" {
+			invalid("server.tls.keyFile", "is required with a TLS certificate")
+		}
+		positive("server.tls.reloadInterval", tls.ReloadInterval)
+	}
+
+	switch c.Store.Backend {
+	case StoreMemory:
+	case StoreFile:
+		if c.Store.DataDir == "" {
+			invalid("store.dataDir", "is required by the file store")
+		}
+	case StoreSQLite:
+		if c.Store.SQLitePath == "" {
+			invalid("store.sqlitePath", "is required by the sqlite store")
+		}
+	default:
+		invalid("store.backend", "unknown backend %q, expected memory, file or sqlite", c.Store.Backend)
+	}
+	if c.Store.CompactThreshold < 0 {
+		invalid("store.compactThreshold", "must not be negative")
+	}
+	if c.Store.RevisionHistory < 0 {
+		invalid("store.revisionHistory", "must not be negative")
+	}
+
+	notNegative("trash.retention", c.Trash.Retention)
+	positive("trash.purgeInterval", c.Trash.PurgeInterval)
+
+	if c.Seed.Mode != seed.ModeIfEmpty && c.Seed.Mode != seed.ModeReconcile {
+		invalid("seed.mode", "unknown mode %q, expected %s or %s", c.Seed.Mode, seed.ModeIfEmpty, seed.ModeReconcile)
+	}
+
+	if c.Webhooks.MaxAttempts < 0 {
+		invalid("webhooks.maxAttempts", "must not be negative")
+	}
+	positive("webhooks.timeout", c.Webhooks.Timeout)
+
+	positive("auth.apiKeysReloadInterval", c.Auth.APIKeysReloadInterval)
+	if oidc := c.Auth.OIDC; oidc.JWKS != "" {
+		if oidc.Issuer == "" {
+			invalid("auth.oidc.issuer", "is required with a JWKS")
+		}
+		if oidc.Audience == "" {
+			invalid("auth.oidc.audience", "is required with a JWKS")
+		}
+		if oidc.DefaultRole != "" {
+			if _, err := auth.ParseRole(oidc.DefaultRole); err != nil {
+				invalid("auth.oidc.defaultRole", "%v", err)
+			}
+		}
+		positive("auth.oidc.jwksRefreshInterval", oidc.JWKSRefreshInterval)
+	}
+	if _, err := auth.ParseServiceAccounts(c.Auth.TokenReview.ServiceAccounts); err != nil {
+		invalid("auth.tokenReview.serviceAccounts", "%v", err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// String lists every setting as "key = value", noting where values that
+// are not defaults came from. Secrets are redacted.
+func (c *Config) String() string {
+	var b strings.Builder
+	for _, s := range c.settings() {
+		value := s.value.get()
+		if s.secret && value != "" {
+			value = redacted
+		}
+		fmt.Fprintf(&b, "%s = %s", s.key, value)
+		if source, ok := c.sources[s.key]; ok {
+			fmt.Fprintf(&b, " (%s)", source)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// redacted replaces the value of secrets
+const redacted = "[redacted]"
@@ -0,0 +1,228 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func env(values map[string]string) func(string) string {
+	return func(key string) string { return values[key] }
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	c, err := Load("server", nil, env(nil))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if c.Server.Addr != ":8080" || c.Store.Backend != StoreMemory {
+		t.Fatalf("Expected the defaults, got %+v", c)
+	}
+	if len(c.Server.CORSOrigins) != 2 {
+		t.Fatalf("Expected the default CORS origins, got %v", c.Server.CORSOrigins)
+	}
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+server:
+  addr: ":9000"
+  corsOrigins:
+    - https://hub.example.com
+store:
+  backend: file
+  dataDir: /var/lib/0xhub
+trash:
+  retention: 48h
+audit:
+  stdout: true
+`)
+	c, err := Load("server",
+		[]string{"-store", "sqlite", "-audit-stdout=false"},
+		env(map[string]string{
+			FileEnv:         path,
+			"STORE_BACKEND": "memory",
+			"DATA_DIR":      "/env/data",
+			"LISTEN_ADDR":   "",
+		}))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if c.Server.Addr != ":9000" {
+		t.Errorf("Expected the file to set the address, ignoring an empty env var, got %q", c.Server.Addr)
+	}
+	if len(c.Server.CORSOrigins) != 1 || c.Server.CORSOrigins[0] != "https://hub.example.com" {
+		t.Errorf("Expected the file's CORS origins, got %v", c.Server.CORSOrigins)
+	}
+	if c.Store.DataDir != "/env/data" {
+		t.Errorf("Expected env to override the file, got %q", c.Store.DataDir)
+	}
+	if c.Store.Backend != StoreSQLite || c.Audit.Stdout {
+		t.Errorf("Expected flags to override env and the file, got %q and %v", c.Store.Backend, c.Audit.Stdout)
+	}
+	if c.Trash.Retention != 48*time.Hour {
+		t.Errorf("Expected the file's retention, got %v", c.Trash.Retention)
+	}
+	if c.Store.SQLitePath != "0xhub.db" {
+		t.Errorf("Expected the default SQLite path, got %q", c.Store.SQLitePath)
+	}
+
+	printed := c.String()
+	for _, line := range []string{
+		"server.addr = :9000 (file " + path + ")",
+		"store.dataDir = /env/data (env DATA_DIR)",
+		"store.backend = sqlite (flag -store)",
+		"store.sqlitePath = 0xhub.db\n",
+	} {
+		if !strings.Contains(printed, line) {
+			t.Errorf("Expected %q in the printed config:\n%s", line, printed)
+		}
+	}
+}
+
+func TestLoad_ConfigFlagOverridesEnv(t *testing.T) {
+	fromFlag := writeFile(t, "flag.json", `{"store": {"backend": "sqlite"}}`)
+	c, err := Load("server", []string{"-config", fromFlag},
+		env(map[string]string{FileEnv: "/does/not/exist.yaml"}))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if c.Store.Backend != StoreSQLite {
+		t.Fatalf("Expected the config file named by the flag, got %q", c.Store.Backend)
+	}
+}
+
+func TestLoad_RedactsSecrets(t *testing.T) {
+	c, err := Load("server", nil, env(map[string]string{"API_KEYS": "ci=s3cret-token"}))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if c.Auth.APIKeys != "ci=s3cret-token" {
+		t.Fatalf("Expected the API keys from env, got %q", c.Auth.APIKeys)
+	}
+	printed := c.String()
+	if strings.Contains(printed, "s3cret") || !strings.Contains(printed, "auth.apiKeys = [redacted] (env API_KEYS)") {
+		t.Fatalf("Expected the API keys to be redacted:\n%s", printed)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		file    string
+		wantErr string
+	}{
+		{
+			name:    "unknown file setting",
+			file:    "store:\n  backend: memory\n  engine: fast\n",
+			wantErr: "unknown settings store.engine",
+		},
+		{
+			name:    "malformed env value",
+			env:     map[string]string{"TRASH_RETENTION": "30 days"},
+			wantErr: "trash.retention from env TRASH_RETENTION",
+		},
+		{
+			name:    "malformed flag value",
+			args:    []string{"-revision-history", "many"},
+			wantErr: "store.revisionHistory from flag -revision-history",
+		},
+		{
+			name:    "unknown flag",
+			args:    []string{"-listen", ":80"},
+			wantErr: "flag provided but not defined",
+		},
+		{
+			name:    "nested list",
+			file:    "server:\n  corsOrigins:\n    - [a, b]\n",
+			wantErr: "list items must be scalars",
+		},
+		{
+			name:    "blank CORS origins",
+			env:     map[string]string{"CORS_ORIGINS": ","},
+			wantErr: "server.corsOrigins: must list at least one origin",
+		},
+		{
+			name:    "blank CORS origins flag",
+			args:    []string{"-cors-origins=,"},
+			wantErr: "server.corsOrigins: must list at least one origin",
+		},
+		{
+			name:    "missing config file",
+			env:     map[string]string{FileEnv: "/does/not/exist.yaml"},
+			wantErr: "failed to read config file",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := tt.env
+			if tt.file != "" {
+				values = map[string]string{FileEnv: writeFile(t, "config.yaml", tt.file)}
+			}
+			_, err := Load("server", tt.args, env(values))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	c := Default()
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected the defaults to be valid, got %v", err)
+	}
+
+	c.Server.Addr = "8080"
+	c.Server.CORSOrigins = []string{"*", "https://ok.example.com", "hub.example.com"}
+	c.Store.Backend = "postgres"
+	c.Server.WriteTimeout = -time.Second
+	c.Server.ShutdownTimeout = 0
+	c.Server.TLS.ClientCAFile = "/etc/tls/ca.crt"
+	c.Trash.PurgeInterval = 0
+	c.Seed.Mode = "always"
+	c.Auth.OIDC.JWKS = "https://issuer.example.com/jwks.json"
+	c.Auth.OIDC.DefaultRole = "superuser"
+	c.Auth.TokenReview.ServiceAccounts = "operator"
+
+	err := c.Validate()
+	if err == nil {
+		t.Fatal("Expected validation errors")
+	}
+	for _, key := range []string{
+		"server.addr",
+		`server.corsOrigins: "hub.example.com"`,
+		"server.writeTimeout",
+		"server.shutdownTimeout",
+		"server.tls.certFile",
+		"server.tls.keyFile",
+		"store.backend",
+		"trash.purgeInterval",
+		"seed.mode",
+		"auth.oidc.issuer",
+		"auth.oidc.audience",
+		"auth.oidc.defaultRole",
+		"auth.tokenReview.serviceAccounts",
+	} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("Expected an error for %s, got:\n%v", key, err)
+		}
+	}
+	if strings.Contains(err.Error(), "ok.example.com") {
+		t.Errorf("Expected valid origins to pass, got:\n%v", err)
+	}
+}
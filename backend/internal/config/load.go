@@ -0,0 +1,377 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Config file settings
+const (
+	// FileFlag names the flag selecting the config file
+	FileFlag = "config"
+	// FileEnv names the environment variable selecting the config file when
+	// the flag is not given
+	FileEnv = "CONFIG_FILE"
+)
+
+// setting binds one configuration value to its config file key,
+// environment variable and flag. An empty env or flag cannot set it.
+type setting struct {
+	key   string
+	env   string
+	flag  string
+	usage string
+	// secret values are redacted when printed. Secrets have no flag, since
+	// command lines are visible to every user of the host.
+	secret bool
+	value  value
+}
+
+// value parses and formats a setting
+type value interface {
+	set(s string) error
+	get() string
+}
+
+// settings lists every setting of c, in the order they are printed
+func (c *Config) settings() []setting {
+	return []setting{
+		{key: "server.addr", env: "LISTEN_ADDR", flag: "addr", value: stringValue{&c.Server.Addr},
+			usage: "The host:port the server listens on"},
+		{key: "server.corsOrigins", env: "CORS_ORIGINS", flag: "cors-origins", value: listValue{&c.Server.CORSOrigins},
+			usage: "Comma-separated browser origins allowed to call the API, or * for any"},
+		{key: "server.readHeaderTimeout", env: "READ_HEADER_TIMEOUT", flag: "read-header-timeout", value: durationValue{&c.Server.ReadHeaderTimeout},
+			usage: "How long a client may take to send request headers (0 for no limit)"},
+		{key: "server.readTimeout", env: "READ_TIMEOUT", flag: "read-timeout", value: durationValue{&c.Server.ReadTimeout},
+			usage: "How long a client may take to send a whole request (0 for no limit)"},
+		{key: "server.writeTimeout", env: "WRITE_TIMEOUT", flag: "write-timeout", value: durationValue{&c.Server.WriteTimeout},
+			usage: "How long a response may take, except for change feeds (0 for no limit)"},
+		{key: "server.idleTimeout", env: "IDLE_TIMEOUT", flag: "idle-timeout", value: durationValue{&c.Server.IdleTimeout},
+			usage: "How long an idle keep-alive connection stays open (0 for the read timeout)"},
+		{key: "server.maxHeaderBytes", env: "MAX_HEADER_BYTES", flag: "max-header-bytes", value: intValue{&c.Server.MaxHeaderBytes},
+			usage: "The maximum size of request headers"},
+		{key: "server.shutdownDelay", env: "SHUTDOWN_DELAY", flag: "shutdown-delay", value: durationValue{&c.Server.ShutdownDelay},
+			usage: "How long to keep serving after SIGTERM while reporting unhealthy, for load balancers to catch up"},
+		{key: "server.shutdownTimeout", env: "SHUTDOWN_TIMEOUT", flag: "shutdown-timeout", value: durationValue{&c.Server.ShutdownTimeout},
+			usage: "How long to wait for requests in flight to finish on shutdown"},
+		{key: "server.tls.certFile", env: "TLS_CERT_FILE", flag: "tls-cert-file", value: stringValue{&c.Server.TLS.CertFile},
+			usage: "A PEM certificate chain to serve HTTPS with (empty serves plain HTTP)"},
+		{key: "server.tls.keyFile", env: "TLS_KEY_FILE", flag: "tls-key-file", value: stringValue{&c.Server.TLS.KeyFile},
+			usage: "The PEM private key of the TLS certificate"},
+		{key: "server.tls.clientCAFile", env: "TLS_CLIENT_CA_FILE", flag: "tls-client-ca-file", value: stringValue{&c.Server.TLS.ClientCAFile},
+			usage: "A PEM CA bundle; when set, every client must present a certificate it signed"},
+		{key: "server.tls.reloadInterval", env: "TLS_RELOAD_INTERVAL", flag: "tls-reload-interval", value: durationValue{&c.Server.TLS.ReloadInterval},
+			usage: "How often the TLS files are checked for changes"},
+
+		{key: "store.backend", env: "STORE_BACKEND", flag: "store", value: stringValue{&c.Store.Backend},
+			usage: "The storage backend to use (memory, file or sqlite)"},
+		{key: "store.sqlitePath", env: "SQLITE_PATH", flag: "sqlite-path", value: stringValue{&c.Store.SQLitePath},
+			usage: "The path of the SQLite database file when using the sqlite store"},
+		{key: "store.dataDir", env: "DATA_DIR", flag: "data-dir", value: stringValue{&c.Store.DataDir},
+			usage: "The directory holding the snapshot and journal when using the file store"},
+		{key: "store.compactThreshold", env: "COMPACT_THRESHOLD", flag: "compact-threshold", value: intValue{&c.Store.CompactThreshold},
+			usage: "The number of journal entries after which the file store writes a new snapshot"},
+		{key: "store.revisionHistory", env: "REVISION_HISTORY", flag: "revision-history", value: intValue{&c.Store.RevisionHistory},
+			usage: "The number of revisions of each project kept for restoring"},
+
+		{key: "trash.retention", env: "TRASH_RETENTION", flag: "trash-retention", value: durationValue{&c.Trash.Retention},
+			usage: "How long deleted projects stay in the trash before they are purged"},
+		{key: "trash.purgeInterval", env: "TRASH_PURGE_INTERVAL", flag: "trash-purge-interval", value: durationValue{&c.Trash.PurgeInterval},
+			usage: "How often the trash is checked for projects past their retention"},
+
+		{key: "audit.log", env: "AUDIT_LOG", flag: "audit-log", value: stringValue{&c.Audit.Log},
+			usage: "The file the audit log of project changes is appended to (empty keeps recent events in memory)"},
+		{key: "audit.stdout", env: "AUDIT_STDOUT", flag: "audit-stdout", value: boolValue{&c.Audit.Stdout},
+			usage: "Also write every audit event as a JSON line to stdout"},
+
+		{key: "seed.file", env: "SEED_FILE", flag: "seed-file", value: stringValue{&c.Seed.File},
+			usage: "A YAML or JSON file of projects to seed the store with (empty seeds nothing)"},
+		{key: "seed.mode", env: "SEED_MODE", flag: "seed-mode", value: stringValue{&c.Seed.Mode},
+			usage: "When to apply the seed file: if-empty, or reconcile on every start"},
+
+		{key: "webhooks.file", env: "WEBHOOKS_FILE", flag: "webhooks-file", value: stringValue{&c.Webhooks.File},
+			usage: "The file webhook subscriptions are saved to (empty keeps them in memory)"},
+		{key: "webhooks.maxAttempts", env: "WEBHOOK_MAX_ATTEMPTS", flag: "webhook-max-attempts", value: intValue{&c.Webhooks.MaxAttempts},
+			usage: "The number of times a webhook delivery is tried before it fails"},
+		{key: "webhooks.timeout", env: "WEBHOOK_TIMEOUT", flag: "webhook-timeout", value: durationValue{&c.Webhooks.Timeout},
+			usage: "How long a single webhook delivery attempt may take"},
+
+		{key: "auth.apiKeys", env: "API_KEYS", secret: true, value: stringValue{&c.Auth.APIKeys},
+			usage: "Comma-separated name[:role]=token API keys"},
+		{key: "auth.apiKeysFile", env: "API_KEYS_FILE", flag: "api-keys-file", value: stringValue{&c.Auth.APIKeysFile},
+			usage: "A file of name[:role]=token lines, one per API key"},
+		{key: "auth.apiKeysReloadInterval", env: "API_KEYS_RELOAD_INTERVAL", flag: "api-keys-reload-interval", value: durationValue{&c.Auth.APIKeysReloadInterval},
+			usage: "How often the API keys file is checked for changes"},
+		{key: "auth.oidc.jwks", env: "OIDC_JWKS", flag: "oidc-jwks", value: stringValue{&c.Auth.OIDC.JWKS},
+			usage: "A file path or URL of the JWKS used to verify OIDC / JWT bearer tokens; enables JWT authentication"},
+		{key: "auth.oidc.jwksRefreshInterval", env: "OIDC_JWKS_REFRESH_INTERVAL", flag: "oidc-jwks-refresh-interval", value: durationValue{&c.Auth.OIDC.JWKSRefreshInterval},
+			usage: "How often the JWKS is reloaded"},
+		{key: "auth.oidc.issuer", env: "OIDC_ISSUER", flag: "oidc-issuer", value: stringValue{&c.Auth.OIDC.Issuer},
+			usage: "The required iss claim of JWT bearer tokens"},
+		{key: "auth.oidc.audience", env: "OIDC_AUDIENCE", flag: "oidc-audience", value: stringValue{&c.Auth.OIDC.Audience},
+			usage: "The required aud claim of JWT bearer tokens"},
+		{key: "auth.oidc.usernameClaim", env: "OIDC_USERNAME_CLAIM", flag: "oidc-username-claim", value: stringValue{&c.Auth.OIDC.UsernameClaim},
+			usage: "The JWT claim used as the caller's name"},
+		{key: "auth.oidc.rolesClaim", env: "OIDC_ROLES_CLAIM", flag: "oidc-roles-claim", value: stringValue{&c.Auth.OIDC.RolesClaim},
+			usage: "The JWT claim holding the caller's roles"},
+		{key: "auth.oidc.defaultRole", env: "OIDC_DEFAULT_ROLE", flag: "oidc-default-role", value: stringValue{&c.Auth.OIDC.DefaultRole},
+			usage: "The role of JWT callers whose token names no known role (empty for none)"},
+		{key: "auth.tokenReview.serviceAccounts", env: "TOKENREVIEW_SERVICE_ACCOUNTS", flag: "tokenreview-service-accounts", value: stringValue{&c.Auth.TokenReview.ServiceAccounts},
+			usage: "Comma-separated namespace/name[:role] ServiceAccounts whose tokens are accepted via the TokenReview API"},
+		{key: "auth.tokenReview.audience", env: "TOKENREVIEW_AUDIENCE", flag: "tokenreview-audience", value: stringValue{&c.Auth.TokenReview.Audience},
+			usage: "The audience ServiceAccount tokens must be issued for (empty for the API server default)"},
+	}
+}
+
+// Load builds the configuration from the defaults, then an optional YAML
+// or JSON config file, then environment variables, then the flags in args,
+// each overriding the ones before it. Empty environment variables are
+// ignored. The result is validated.
+//
+// The config file is named by the -config flag or $CONFIG_FILE and nests
+// settings by their dotted keys, such as store.backend.
+func Load(name string, args []string, getenv func(string) string) (*Config, error) {
+	c := Default()
+	c.sources = make(map[string]string)
+	settings := c.settings()
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	file := fs.String(FileFlag, "", fmt.Sprintf("A YAML or JSON config file ($%s)", FileEnv))
+	// Flags are applied last, so they are only collected while parsing
+	flags := make(map[string]string)
+	for _, s := range settings {
+		if s.flag == "" {
+			continue
+		}
+		usage := fmt.Sprintf("%s ($%s", s.usage, s.env)
+		if def := s.value.get(); def != "" {
+			usage += fmt.Sprintf(", default %q", def)
+		}
+		usage += ")"
+		collect := func(v string) error {
+			flags[s.key] = v
+			return nil
+		}
+		if _, ok := s.value.(boolValue); ok {
+			fs.BoolFunc(s.flag, usage, collect)
+		} else {
+			fs.Func(s.flag, usage, collect)
+		}
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments %q", fs.Args())
+	}
+
+	if *file == "" {
+		*file = getenv(FileEnv)
+	}
+	if *file != "" {
+		values, err := readFile(*file)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range settings {
+			v, ok := values[s.key]
+			if !ok {
+				continue
+			}
+			delete(values, s.key)
+			if err := c.set(s, v, "file "+*file); err != nil {
+				return nil, err
+			}
+		}
+		if len(values) > 0 {
+			keys := make([]string, 0, len(values))
+			for key := range values {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			return nil, fmt.Errorf("config file %s: unknown settings %s", *file, strings.Join(keys, ", "))
+		}
+	}
+
+	for _, s := range settings {
+		if s.env == "" {
+			continue
+		}
+		if v := getenv(s.env); v != "" {
+			if err := c.set(s, v, "env "+s.env); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, s := range settings {
+		if v, ok := flags[s.key]; ok {
+			if err := c.set(s, v, "flag -"+s.flag); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := c.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return c, nil
+}
+
+// set parses v into s, recording source
+func (c *Config) set(s setting, v, source string) error {
+	if err := s.value.set(v); err != nil {
+		return fmt.Errorf("%s from %s: %w", s.key, source, err)
+	}
+	c.sources[s.key] = source
+	return nil
+}
+
+// readFile reads a config file into settings values by dotted key
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	if data, err = yaml.YAMLToJSON(data); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	var doc any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	if doc == nil {
+		return values, nil
+	}
+	if err := flatten("", doc, values); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// flatten stores the scalars and lists in v by their dotted keys. Lists are
+// joined with commas.
+func flatten(key string, v any, values map[string]string) error {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, child := range v {
+			if key != "" {
+				k = key + "." + k
+			}
+			if err := flatten(k, child, values); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			s, ok := scalar(item)
+			if !ok {
+				return fmt.Errorf("%s: list items must be scalars", key)
+			}
+			items[i] = s
+		}
+		values[key] = strings.Join(items, ",")
+		return nil
+	default:
+		if key == "" {
+			return errors.New("expected a mapping of settings")
+		}
+		values[key], _ = scalar(v)
+		return nil
+	}
+}
+
+func scalar(v any) (string, bool) {
+	switch v := v.(type) {
+	case nil:
+		return "", true
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	default:
+		return "", false
+	}
+}
+
+type stringValue struct{ p *string }
+
+func (v stringValue) set(s string) error {
+	*v.p = s
+	return nil
+}
+
+func (v stringValue) get() string { return *v.p }
+
+type intValue struct{ p *int }
+
+func (v intValue) set(s string) error {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return fmt.Errorf("invalid integer %q", s)
+	}
+	*v.p = n
+	return nil
+}
+
+func (v intValue) get() string { return strconv.Itoa(*v.p) }
+
+type boolValue struct{ p *bool }
+
+func (v boolValue) set(s string) error {
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return fmt.Errorf("invalid boolean %q", s)
+	}
+	*v.p = b
+	return nil
+}
+
+func (v boolValue) get() string { return strconv.FormatBool(*v.p) }
+
+type durationValue struct{ p *time.Duration }
+
+func (v durationValue) set(s string) error {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q, expected a value such as 30s or 1h", s)
+	}
+	*v.p = d
+	return nil
+}
+
+func (v durationValue) get() string { return v.p.String() }
+
+// listValue holds comma-separated items; blank items are dropped
+type listValue struct{ p *[]string }
+
+func (v listValue) set(s string) error {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	*v.p = items
+	return nil
+}
+
+func (v listValue) get() string { return strings.Join(*v.p, ",") }
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"0xhub/backend/internal/audit"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// AuditHandler serves the audit log of project mutations
+type AuditHandler struct {
+	log audit.Log
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(log audit.Log) *AuditHandler {
+	return &AuditHandler{
+		log: log,
+	}
+}
+
+// List returns audit events, newest first. When more match than the limit,
+// the response's next link fetches the following page.
+//
+// Query parameters: projectId, since (an RFC 3339 timestamp), before (an
+// event ID; only older events are returned) and limit.
+func (h *AuditHandler) List(c *gin.Context) {
+	query := audit.Query{
+		ProjectID: c.Query("projectId"),
+		Limit:     defaultAuditLimit,
+		Newest:    true,
+	}
+	if raw := c.Query("since"); raw != "" {
+		since, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "since must be an RFC 3339 timestamp",
+			})
+			return
+		}
+		query.Since = since
+	}
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "limit must be a positive integer",
+			})
+			return
+		}
+		query.Limit = min(n, maxAuditLimit)
+	}
+	if raw := c.Query("before"); raw != "" {
+		before, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || before < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "before must be a positive event ID",
+			})
+			return
+		}
+		query.Before = before
+	}
+
+	// Ask for one more event than the page holds to learn whether another
+	// page follows
+	limit := query.Limit
+	query.Limit++
+	events, err := h.log.Query(query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	response := gin.H{}
+	if len(events) > limit {
+		events = events[:limit]
+		next := c.Request.URL.Query()
+		next.Set("before", strconv.FormatInt(events[limit-1].ID, 10))
+		response["next"] = c.Request.URL.Path + "?" + next.Encode()
+	}
+	response["events"] = events
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"0xhub/backend/internal/audit"
+	"0xhub/backend/internal/store"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupAuditRouter(auditLog audit.Log) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	handler := NewProjectsHandler(store.NewStore(), auditLog)
+
+	router := gin.New()
+	router.Use(RequestID())
+	api := router.Group("/api")
+	{
+		api.POST("/projects", handler.CreateProject)
+		api.PUT("/projects/:id", handler.UpdateProject)
+		api.PATCH("/projects/:id", handler.PatchProject)
+		api.DELETE("/projects/:id", handler.DeleteProject)
+		api.GET("/audit", NewAuditHandler(auditLog).List)
+	}
+	return router
+}
+
+func listAuditEvents(t *testing.T, router *gin.Engine, query string) []audit.Event {
+	t.Helper()
+	req, _ := http.NewRequest("GET", "/api/audit?"+query, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response struct {
+		Events []audit.Event `json:"events"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return response.Events
+}
+
+func TestAudit_RecordsMutations(t *testing.T) {
+	router := setupAuditRouter(audit.NewMemoryLog(0))
+
+	send := func(method, path, contentType, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set(UpdatedByHeader, "alice")
+		req.Header.Set(RequestIDHeader, "req-"+method)
+		req.RemoteAddr = "192.0.2.10:4321"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("POST", "/api/projects", "application/json",
+		`{"id":"p1","name":"Project","description":"D","url":"https://example.com"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "req-POST", w.Header().Get(RequestIDHeader))
+	w = send("PUT", "/api/projects/p1", "application/json",
+		`{"name":"Renamed","description":"D","url":"https://example.com"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	w = send("PATCH", "/api/projects/p1", mergePatchContentType, `{"status":"archived"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	w = send("DELETE", "/api/projects/p1", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	// Failed writes are not recorded
+	w = send("DELETE", "/api/projects/p1", "", "")
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	events := listAuditEvents(t, router, "projectId=p1")
+	require.Len(t, events, 4)
+
+	actions := make([]string, 0, len(events))
+	for _, e := range events {
+		actions = append(actions, e.Action)
+		assert.Equal(t, "alice", e.Actor)
+		assert.Equal(t, "192.0.2.10", e.SourceIP)
+		assert.Equal(t, "p1", e.ProjectID)
+	}
+	assert.Equal(t, []string{"delete", "update", "update", "create"}, actions, "Events are listed newest first")
+
+	create := events[3]
+	assert.Nil(t, create.Before)
+	require.NotNil(t, create.After)
+	assert.Equal(t, "Project", create.After.Name)
+	assert.Equal(t, "req-POST", create.RequestID)
+
+	update := events[2]
+	require.NotNil(t, update.Before)
+	assert.Equal(t, "Project", update.Before.Name)
+	assert.Equal(t, "Renamed", update.After.Name)
+	fields := make([]string, 0, len(update.Changes))
+	for _, change := range update.Changes {
+		fields = append(fields, change.Field)
+	}
+	assert.Contains(t, fields, "name")
+	assert.Contains(t, fields, "resourceVersion")
+	assert.NotContains(t, fields, "url")
+
+	patch := events[1]
+	assert.Equal(t, "req-PATCH", patch.RequestID)
+	assert.Equal(t, "archived", patch.After.Status)
+
+	del := events[0]
+	require.NotNil(t, del.Before)
+	assert.Nil(t, del.After)
+	assert.Equal(t, "archived", del.Before.Status)
+}
+
+func TestAudit_List(t *testing.T) {
+	router := setupAuditRouter(audit.NewMemoryLog(0))
+	for _, body := range []string{
+		`{"id":"a","name":"A","description":"D","url":"https://a.example.com"}`,
+		`{"id":"b","name":"B","description":"D","url":"https://b.example.com"}`,
+	} {
+		req, _ := http.NewRequest("POST", "/api/projects", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code)
+	}
+
+	assert.Len(t, listAuditEvents(t, router, ""), 2)
+	assert.Len(t, listAuditEvents(t, router, "limit=1"), 1)
+
+	events := listAuditEvents(t, router, "projectId=b")
+	require.Len(t, events, 1)
+	assert.Equal(t, "b", events[0].ProjectID)
+	assert.NotEmpty(t, events[0].RequestID, "A request ID is generated when none is sent")
+
+	since := events[0].Time.Add(time.Nanosecond).Format(time.RFC3339Nano)
+	assert.Empty(t, listAuditEvents(t, router, "since="+url.QueryEscape(since)))
+
+	for _, query := range []string{"since=yesterday", "limit=0", "limit=x", "before=0", "before=x"} {
+		req, _ := http.NewRequest("GET", "/api/audit?"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestAudit_ListPages(t *testing.T) {
+	auditLog := audit.NewMemoryLog(0)
+	router := setupAuditRouter(auditLog)
+	const total = 250
+	for i := range total {
+		projectID := "even"
+		if i%2 == 1 {
+			projectID = "odd"
+		}
+		require.NoError(t, auditLog.Append(audit.NewEvent(audit.ActionUpdate, projectID, nil, nil)))
+	}
+
+	page := func(target string) ([]audit.Event, string) {
+		t.Helper()
+		req, _ := http.NewRequest("GET", target, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var response struct {
+			Events []audit.Event `json:"events"`
+			Next   string        `json:"next"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response.Events, response.Next
+	}
+
+	// The default page holds the newest events, and next links walk back to
+	// the oldest without skipping or repeating any
+	events, next := page("/api/audit")
+	require.Len(t, events, defaultAuditLimit)
+	assert.Equal(t, int64(total), events[0].ID)
+	ids := eventIDs(events)
+	for next != "" {
+		events, next = page(next)
+		ids = append(ids, eventIDs(events)...)
+	}
+	require.Len(t, ids, total)
+	for i, id := range ids {
+		assert.Equal(t, int64(total-i), id)
+	}
+
+	// Next links keep the filters and limit
+	events, next = page("/api/audit?projectId=odd&limit=100")
+	require.Len(t, events, 100)
+	assert.Equal(t, int64(total), events[0].ID)
+	next = strings.TrimPrefix(next, "/api/audit?")
+	query, err := url.ParseQuery(next)
+	require.NoError(t, err)
+	assert.Equal(t, "odd", query.Get("projectId"))
+	assert.Equal(t, "100", query.Get("limit"))
+	assert.Equal(t, "52", query.Get("before"))
+	events, next = page("/api/audit?" + next)
+	require.Len(t, events, 25)
+	assert.Equal(t, int64(50), events[0].ID)
+	assert.Equal(t, int64(2), events[24].ID)
+	assert.Empty(t, next, "The last page has no next link")
+}
+
+func eventIDs(events []audit.Event) []int64 {
+	ids := make([]int64, 0, len(events))
+	for _, e := range events {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
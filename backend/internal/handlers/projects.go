@@ -1,44 +1,80 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
 
+	"0xhub/backend/internal/audit"
+	"0xhub/backend/internal/auth"
 	"0xhub/backend/internal/models"
 	"0xhub/backend/internal/store"
 
+	jsonpatch "github.com/evanphx/json-patch/v5"
 	"github.com/gin-gonic/gin"
 )
 
 // ProjectsHandler handles project-related HTTP requests
 type ProjectsHandler struct {
-	store *store.Store
+	store    store.Store
+	auditLog audit.Log
 }
 
-// NewProjectsHandler creates a new projects handler
-func NewProjectsHandler(store *store.Store) *ProjectsHandler {
+// NewProjectsHandler creates a new projects handler that records every
+// mutation in auditLog. A nil auditLog disables auditing.
+func NewProjectsHandler(store store.Store, auditLog audit.Log) *ProjectsHandler {
 	return &ProjectsHandler{
-		store: store,
+		store:    store,
+		auditLog: auditLog,
 	}
 }
 
-// GetProjects returns all projects
+// GetProjects returns a filtered, sorted page of projects
+//
+// Query parameters: category, status, q (free text), sort (id, name,
+// category, status, createdAt or updatedAt, "-" prefix for descending),
+// limit and cursor.
 func (h *ProjectsHandler) GetProjects(c *gin.Context) {
-	projects := h.store.GetAll()
-	c.JSON(http.StatusOK, gin.H{
-		"projects": projects,
-	})
+	opts := store.ListOptions{
+		Category: c.Query("category"),
+		Status:   c.Query("status"),
+		Query:    c.Query("q"),
+		Sort:     c.Query("sort"),
+		Cursor:   c.Query("cursor"),
+	}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "limit must be an integer",
+			})
+			return
+		}
+		opts.Limit = n
+	}
+
+	result, err := h.store.List(opts)
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
 }
 
 // GetProject returns a single project by ID
 func (h *ProjectsHandler) GetProject(c *gin.Context) {
 	id := c.Param("id")
-	project, exists := h.store.GetByID(id)
-	if !exists {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "project not found",
-		})
+	project, err := h.store.GetByID(id)
+	if err != nil {
+		respondStoreError(c, err)
 		return
 	}
+	c.Header("ETag", etag(project))
 	c.JSON(http.StatusOK, project)
 }
 
@@ -52,18 +88,39 @@ func (h *ProjectsHandler) CreateProject(c *gin.Context) {
 		return
 	}
 
-	if project.ID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "id is required",
-		})
+	if err := project.Validate(); err != nil {
+		respondValidationError(c, err)
 		return
 	}
 
-	h.store.Create(&project)
+	trashed, err := findTrashed(h.store, project.ID)
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	managedBy, ok := authorizeCreate(c, trashed)
+	if !ok {
+		return
+	}
+	project.ManagedBy = managedBy
+	project.UpdatedBy = caller(c)
+	if err := h.store.Create(&project); err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	h.record(c, audit.ActionCreate, project.ID, nil, &project)
+	c.Header("ETag", etag(&project))
 	c.JSON(http.StatusCreated, project)
 }
 
-// UpdateProject updates an existing project
+// UpdateProject replaces the project at the given ID, creating it if missing.
+// A PUT of the content already stored writes nothing and returns the stored
+// project, so repeating it does not produce new versions or change events.
+//
+// "If-None-Match: *" restricts the request to creating a new project.
+// "If-Match" restricts it to updating an existing project, which must be at
+// the given ETag unless "*" is sent. Either returns 412 Precondition Failed
+// when its condition does not hold.
 func (h *ProjectsHandler) UpdateProject(c *gin.Context) {
 	id := c.Param("id")
 	var project models.Project
@@ -75,27 +132,567 @@ func (h *ProjectsHandler) UpdateProject(c *gin.Context) {
 	}
 
 	project.ID = id
-	if !h.store.Update(&project) {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "project not found",
-		})
+	if err := project.Validate(); err != nil {
+		respondValidationError(c, err)
 		return
 	}
 
+	// The resource version is server-managed; only If-Match can set the
+	// version a write expects
+	project.ResourceVersion = 0
+	project.UpdatedBy = caller(c)
+
+	var current *models.Project
+	var created bool
+	version, conditional, versionOK := ifMatchVersion(c)
+	createOnly := c.GetHeader("If-None-Match") == "*"
+	for attempt := 1; ; attempt++ {
+		var err error
+		current, err = h.store.GetByID(id)
+		if errors.Is(err, store.ErrNotFound) {
+			current = nil
+		} else if err != nil {
+			respondStoreError(c, err)
+			return
+		}
+		var managedBy string
+		var ok bool
+		if current == nil {
+			trashed, err := findTrashed(h.store, id)
+			if err != nil {
+				respondStoreError(c, err)
+				return
+			}
+			managedBy, ok = authorizeCreate(c, trashed)
+		} else {
+			managedBy, ok = authorizeWrite(c, current)
+		}
+		if !ok {
+			return
+		}
+		project.ManagedBy = managedBy
+
+		// Storing the same content again would only bump the version
+		if current != nil && !createOnly && current.SameContent(&project) &&
+			(!conditional || versionOK && (version == 0 || version == current.ResourceVersion)) {
+			c.Header("ETag", etag(current))
+			c.JSON(http.StatusOK, current)
+			return
+		}
+
+		switch {
+		case createOnly:
+			// Create only: fail if the project already exists
+			err = h.store.Create(&project)
+			created = true
+			if errors.Is(err, store.ErrAlreadyExists) {
+				respondPreconditionFailed(c, "project already exists")
+				return
+			}
+		case conditional:
+			if !versionOK {
+				respondPreconditionFailed(c, "If-Match does not match the current project")
+				return
+			}
+			project.ResourceVersion = version
+			err = h.store.Update(&project)
+			if errors.Is(err, store.ErrNotFound) {
+				respondPreconditionFailed(c, "project not found")
+				return
+			}
+		case current == nil:
+			// Write against the state the authorization checked, so a
+			// concurrent change is never silently overwritten
+			project.ResourceVersion = 0
+			err = h.store.Create(&project)
+			created = true
+		default:
+			project.ResourceVersion = current.ResourceVersion
+			err = h.store.Update(&project)
+			created = false
+		}
+		if !createOnly && !conditional && isWriteRace(err) {
+			if attempt < maxPatchAttempts {
+				continue
+			}
+			respondConcurrentChange(c)
+			return
+		}
+		if err != nil {
+			respondStoreError(c, err)
+			return
+		}
+		break
+	}
+
+	c.Header("ETag", etag(&project))
+	if created {
+		h.record(c, audit.ActionCreate, id, nil, &project)
+		c.JSON(http.StatusCreated, project)
+		return
+	}
+	h.record(c, audit.ActionUpdate, id, current, &project)
 	c.JSON(http.StatusOK, project)
 }
 
-// DeleteProject deletes a project
-func (h *ProjectsHandler) DeleteProject(c *gin.Context) {
+// Patch document media types accepted by PatchProject
+const (
+	mergePatchContentType = "application/merge-patch+json"
+	jsonPatchContentType  = "application/json-patch+json"
+)
+
+// maxPatchAttempts bounds how often an unconditional patch is reapplied when
+// the project changes between reading and writing it
+const maxPatchAttempts = 3
+
+// PatchProject applies a JSON Merge Patch (RFC 7396) or JSON Patch (RFC
+// 6902) document to an existing project, chosen by Content-Type. The
+// patched project is validated like a PUT body, and is not written when
+// the patch changes nothing. An If-Match header makes the
+// patch conditional on the project's current ETag.
+func (h *ProjectsHandler) PatchProject(c *gin.Context) {
 	id := c.Param("id")
-	if !h.store.Delete(id) {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "project not found",
+	contentType := c.ContentType()
+	if contentType != mergePatchContentType && contentType != jsonPatchContentType {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{
+			"error": "Content-Type must be " + mergePatchContentType + " or " + jsonPatchContentType,
 		})
 		return
 	}
 
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	var patch jsonpatch.Patch
+	if contentType == jsonPatchContentType {
+		if patch, err = jsonpatch.DecodePatch(body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid JSON Patch: " + err.Error(),
+			})
+			return
+		}
+	} else if !json.Valid(body) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid JSON Merge Patch: malformed JSON",
+		})
+		return
+	}
+
+	version, conditional, ok := ifMatchVersion(c)
+	if conditional && !ok {
+		respondPreconditionFailed(c, "If-Match does not match the current project")
+		return
+	}
+
+	for attempt := 1; ; attempt++ {
+		current, err := h.store.GetByID(id)
+		if err != nil {
+			respondStoreError(c, err)
+			return
+		}
+		if version != 0 && current.ResourceVersion != version {
+			respondPreconditionFailed(c, "If-Match does not match the current project")
+			return
+		}
+		if _, ok := authorizeWrite(c, current); !ok {
+			return
+		}
+
+		original, err := json.Marshal(current)
+		if err != nil {
+			respondStoreError(c, err)
+			return
+		}
+		var patched []byte
+		if patch != nil {
+			patched, err = patch.Apply(original)
+		} else {
+			patched, err = jsonpatch.MergePatch(original, body)
+		}
+		if errors.Is(err, jsonpatch.ErrTestFailed) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "failed to apply patch: " + err.Error(),
+			})
+			return
+		}
+
+		var project models.Project
+		if err := json.Unmarshal(patched, &project); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "patched project is invalid: " + err.Error(),
+			})
+			return
+		}
+		if project.ID != id {
+			respondValidationError(c, models.ValidationErrors{
+				{Field: "id", Message: "cannot be changed"},
+			})
+			return
+		}
+		if err := project.Validate(); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		if current.SameContent(&project) {
+			c.Header("ETag", etag(current))
+			c.JSON(http.StatusOK, current)
+			return
+		}
+
+		// Write against the version the patch was applied to, so a concurrent
+		// change is never silently overwritten
+		project.ResourceVersion = current.ResourceVersion
+		project.UpdatedBy = caller(c)
+		err = h.store.Update(&project)
+		if errors.Is(err, store.ErrVersionConflict) && !conditional && attempt < maxPatchAttempts {
+			continue
+		}
+		if err != nil {
+			respondStoreError(c, err)
+			return
+		}
+
+		h.record(c, audit.ActionUpdate, id, current, &project)
+		c.Header("ETag", etag(&project))
+		c.JSON(http.StatusOK, project)
+		return
+	}
+}
+
+// DeleteProject moves a project to the trash. An If-Match header makes the
+// delete conditional on the project's current ETag.
+func (h *ProjectsHandler) DeleteProject(c *gin.Context) {
+	id := c.Param("id")
+	version, conditional, ok := ifMatchVersion(c)
+	if conditional && !ok {
+		respondPreconditionFailed(c, "If-Match does not match the current project")
+		return
+	}
+
+	current, err := h.store.GetByID(id)
+	if errors.Is(err, store.ErrNotFound) && conditional {
+		respondPreconditionFailed(c, "project not found")
+		return
+	}
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	if _, ok := authorizeWrite(c, current); !ok {
+		return
+	}
+
+	// Without If-Match, delete the project the authorization checked, so
+	// one that changed in between is not removed anyway
+	if !conditional {
+		version = current.ResourceVersion
+	}
+	_, err = h.store.Delete(id, version)
+	if conditional && errors.Is(err, store.ErrNotFound) {
+		respondPreconditionFailed(c, "project not found")
+		return
+	}
+	if !conditional && isWriteRace(err) {
+		respondConcurrentChange(c)
+		return
+	}
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	h.record(c, audit.ActionDelete, id, current, nil)
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "project deleted",
 	})
 }
+
+// GetTrash returns the deleted projects awaiting purge, most recently deleted
+// first
+func (h *ProjectsHandler) GetTrash(c *gin.Context) {
+	projects, err := h.store.Trash()
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"projects": projects,
+	})
+}
+
+// RestoreProject moves a project out of the trash. An If-Match header makes
+// the restore conditional on the trashed project's ETag.
+func (h *ProjectsHandler) RestoreProject(c *gin.Context) {
+	id := c.Param("id")
+	version, conditional, ok := ifMatchVersion(c)
+	if conditional && !ok {
+		respondPreconditionFailed(c, "If-Match does not match the trashed project")
+		return
+	}
+
+	trashed, err := findTrashed(h.store, id)
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	if trashed == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "project not found in trash",
+		})
+		return
+	}
+	if _, ok := authorizeWrite(c, trashed); !ok {
+		return
+	}
+
+	project, err := h.store.Restore(id, version)
+	if errors.Is(err, store.ErrVersionConflict) {
+		respondPreconditionFailed(c, "If-Match does not match the trashed project")
+		return
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "project not found in trash",
+		})
+		return
+	}
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	h.record(c, audit.ActionRestore, id, trashed, project)
+
+	c.Header("ETag", etag(project))
+	c.JSON(http.StatusOK, project)
+}
+
+// record appends a successful mutation to the audit log
+func (h *ProjectsHandler) record(c *gin.Context, action, id string, before, after *models.Project) {
+	recordAudit(c, h.auditLog, action, id, before, after)
+}
+
+// recordAudit appends a successful mutation to auditLog, if any. A failure to
+// record is logged rather than failing a write that already happened.
+func recordAudit(c *gin.Context, auditLog audit.Log, action, id string, before, after *models.Project) {
+	if auditLog == nil {
+		return
+	}
+	event := audit.NewEvent(action, id, before, after)
+	event.Actor = caller(c)
+	event.SourceIP = c.ClientIP()
+	event.RequestID = RequestIDFrom(c)
+	if err := auditLog.Append(event); err != nil {
+		log.Printf("Failed to record audit event for project %s: %v", id, err)
+	}
+}
+
+// authorizeWrite checks that the caller may write current, or create a new
+// project when current is nil, responding with 403 Forbidden if not. Projects
+// managed by the operator need PermissionWriteManaged and all others need
+// PermissionWrite; a caller holding only PermissionWriteManaged creates
+// managed projects. It returns the ManagedBy the written project keeps.
+//
+// Requests that were not authenticated, because no credentials are
+// configured, may write anything.
+func authorizeWrite(c *gin.Context, current *models.Project) (managedBy string, ok bool) {
+	identity := auth.IdentityFrom(c)
+	managedBy, denied := checkWrite(identity, current)
+	if denied != nil {
+		auth.Forbidden(c, identity, denied.permission, denied.reason)
+		return "", false
+	}
+	return managedBy, true
+}
+
+// authorizeCreate is authorizeWrite for a new project. Creating a project
+// replaces a trashed one with its ID, so the caller must be allowed to write
+// trashed, if any, as well.
+func authorizeCreate(c *gin.Context, trashed *models.Project) (managedBy string, ok bool) {
+	identity := auth.IdentityFrom(c)
+	managedBy, denied := checkCreate(identity, trashed)
+	if denied != nil {
+		auth.Forbidden(c, identity, denied.permission, denied.reason)
+		return "", false
+	}
+	return managedBy, true
+}
+
+// checkCreate is authorizeCreate without the response, like checkWrite
+func checkCreate(identity *auth.Identity, trashed *models.Project) (managedBy string, denied *writeDenial) {
+	if trashed != nil {
+		if _, denied := checkWrite(identity, trashed); denied != nil {
+			return "", denied
+		}
+	}
+	return checkWrite(identity, nil)
+}
+
+// findTrashed returns the trashed project with the ID, or nil if there is none
+func findTrashed(s store.Store, id string) (*models.Project, error) {
+	trash, err := s.Trash()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range trash {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	return nil, nil
+}
+
+// writeDenial is the permission a caller lacks to write a project, and why
+type writeDenial struct {
+	permission auth.Permission
+	reason     string
+}
+
+func (d *writeDenial) Error() string {
+	return fmt.Sprintf("missing permission %s: %s", d.permission, d.reason)
+}
+
+// checkWrite is authorizeWrite without the response, for callers that
+// report denials themselves. identity is nil for unauthenticated requests.
+func checkWrite(identity *auth.Identity, current *models.Project) (managedBy string, denied *writeDenial) {
+	if current != nil {
+		managedBy = current.ManagedBy
+	}
+	if identity == nil {
+		return managedBy, nil
+	}
+
+	if current == nil {
+		switch {
+		case identity.Can(auth.PermissionWrite):
+			return "", nil
+		case identity.Can(auth.PermissionWriteManaged):
+			return models.ManagedByOperator, nil
+		}
+		return "", &writeDenial{auth.PermissionWrite, "creating projects requires it"}
+	}
+
+	if managedBy == models.ManagedByOperator {
+		if !identity.Can(auth.PermissionWriteManaged) {
+			return "", &writeDenial{auth.PermissionWriteManaged,
+				fmt.Sprintf("project %q is managed by the operator", current.ID)}
+		}
+		return managedBy, nil
+	}
+	if !identity.Can(auth.PermissionWrite) {
+		return "", &writeDenial{auth.PermissionWrite,
+			fmt.Sprintf("project %q is not managed by the operator", current.ID)}
+	}
+	return managedBy, nil
+}
+
+// UpdatedByHeader lets a caller name itself as the author of a write when
+// the request is not authenticated
+const UpdatedByHeader = "X-Updated-By"
+
+// caller identifies who is making the request, recorded as the UpdatedBy of
+// the projects it writes. An authenticated identity takes precedence over
+// UpdatedByHeader.
+func caller(c *gin.Context) string {
+	if identity := auth.IdentityFrom(c); identity != nil {
+		return identity.Name
+	}
+	return strings.TrimSpace(c.GetHeader(UpdatedByHeader))
+}
+
+// etag formats a project's resource version as a strong entity tag
+func etag(project *models.Project) string {
+	return `"` + strconv.FormatInt(project.ResourceVersion, 10) + `"`
+}
+
+// ifMatchVersion reads the If-Match header. conditional reports whether the
+// header was sent; version is the expected resource version, or zero for
+// "*". ok is false when the header holds no entity tag this server issues,
+// so it can never match.
+func ifMatchVersion(c *gin.Context) (version int64, conditional bool, ok bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		return 0, false, false
+	}
+	if header == "*" {
+		return 0, true, true
+	}
+	if len(header) < 3 || header[0] != '"' || header[len(header)-1] != '"' {
+		return 0, true, false
+	}
+	version, err := strconv.ParseInt(header[1:len(header)-1], 10, 64)
+	if err != nil || version <= 0 {
+		return 0, true, false
+	}
+	return version, true, true
+}
+
+// respondValidationError reports invalid payload fields. The error message
+// summarizes every field and fields lists them individually.
+func respondValidationError(c *gin.Context, err error) {
+	var fields models.ValidationErrors
+	errors.As(err, &fields)
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":  err.Error(),
+		"fields": fields,
+	})
+}
+
+// respondPreconditionFailed reports a failed conditional request
+func respondPreconditionFailed(c *gin.Context, message string) {
+	c.JSON(http.StatusPreconditionFailed, gin.H{
+		"error": message,
+	})
+}
+
+// isWriteRace reports whether an unconditional write failed because the
+// project changed after it was read
+func isWriteRace(err error) bool {
+	return errors.Is(err, store.ErrAlreadyExists) || errors.Is(err, store.ErrVersionConflict) || errors.Is(err, store.ErrNotFound)
+}
+
+// respondConcurrentChange reports an unconditional write that kept racing
+// other writers to the same project
+func respondConcurrentChange(c *gin.Context) {
+	c.JSON(http.StatusConflict, gin.H{
+		"error": "project was changed concurrently, retry the request",
+	})
+}
+
+// respondStoreError maps a store error to an HTTP error response
+func respondStoreError(c *gin.Context, err error) {
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "project not found",
+		})
+		return
+	}
+	if errors.Is(err, store.ErrAlreadyExists) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "project already exists",
+		})
+		return
+	}
+	if errors.Is(err, store.ErrVersionConflict) {
+		respondPreconditionFailed(c, "If-Match does not match the current project")
+		return
+	}
+	if errors.Is(err, store.ErrInvalidListOptions) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": err.Error(),
+	})
+}
@@ -1,13 +1,19 @@
 package handlers
 
 import (
+	"0xhub/backend/internal/audit"
+	"0xhub/backend/internal/auth"
 	"0xhub/backend/internal/models"
 	"0xhub/backend/internal/store"
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -17,7 +23,7 @@ import (
 func setupRouter() *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	testStore := store.NewStore()
-	handler := NewProjectsHandler(testStore)
+	handler := NewProjectsHandler(testStore, nil)
 
 	router := gin.New()
 	api := router.Group("/api")
@@ -26,6 +32,7 @@ func setupRouter() *gin.Engine {
 		api.GET("/projects/:id", handler.GetProject)
 		api.POST("/projects", handler.CreateProject)
 		api.PUT("/projects/:id", handler.UpdateProject)
+		api.PATCH("/projects/:id", handler.PatchProject)
 		api.DELETE("/projects/:id", handler.DeleteProject)
 	}
 
@@ -59,7 +66,7 @@ func TestGetProjects_WithData(t *testing.T) {
 	})
 
 	// Recreate handler with populated store
-	handler := NewProjectsHandler(testStore)
+	handler := NewProjectsHandler(testStore, nil)
 	gin.SetMode(gin.TestMode)
 	testRouter := gin.New()
 	api := testRouter.Group("/api")
@@ -80,6 +87,56 @@ func TestGetProjects_WithData(t *testing.T) {
 	assert.Equal(t, 1, len(projects))
 }
 
+func TestGetProjects_FilterSortAndPaginate(t *testing.T) {
+	testStore := store.NewStore()
+	testStore.Create(&models.Project{ID: "a", Name: "Alpha", URL: "https://a.com", Category: "Tools"})
+	testStore.Create(&models.Project{ID: "b", Name: "Bravo", URL: "https://b.com", Category: "Tools"})
+	testStore.Create(&models.Project{ID: "c", Name: "Charlie", URL: "https://c.com", Category: "Other"})
+
+	handler := NewProjectsHandler(testStore, nil)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/projects", handler.GetProjects)
+
+	req, _ := http.NewRequest("GET", "/api/projects?category=tools&sort=-name&limit=1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var page store.ListResult
+	err := json.Unmarshal(w.Body.Bytes(), &page)
+	require.NoError(t, err)
+	require.Len(t, page.Projects, 1)
+	assert.Equal(t, "b", page.Projects[0].ID)
+	assert.Equal(t, 2, page.Total)
+	require.NotEmpty(t, page.NextCursor)
+
+	req, _ = http.NewRequest("GET", "/api/projects?category=tools&sort=-name&limit=1&cursor="+page.NextCursor, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	page = store.ListResult{}
+	err = json.Unmarshal(w.Body.Bytes(), &page)
+	require.NoError(t, err)
+	require.Len(t, page.Projects, 1)
+	assert.Equal(t, "a", page.Projects[0].ID)
+	assert.Empty(t, page.NextCursor)
+}
+
+func TestGetProjects_InvalidQuery(t *testing.T) {
+	router := setupRouter()
+
+	for _, query := range []string{"sort=url", "limit=abc", "cursor=bogus"} {
+		req, _ := http.NewRequest("GET", "/api/projects?"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
 func TestGetProject_Exists(t *testing.T) {
 	testStore := store.NewStore()
 	testStore.Create(&models.Project{
@@ -89,7 +146,7 @@ func TestGetProject_Exists(t *testing.T) {
 		URL:         "https://test.com",
 	})
 
-	handler := NewProjectsHandler(testStore)
+	handler := NewProjectsHandler(testStore, nil)
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
 	api := router.Group("/api")
@@ -146,6 +203,38 @@ func TestCreateProject_Success(t *testing.T) {
 	assert.Equal(t, project.Name, created.Name)
 }
 
+func TestCreateProject_Conflict(t *testing.T) {
+	testStore := store.NewStore()
+	testStore.Create(&models.Project{
+		ID:          "test-1",
+		Name:        "Operator Project",
+		Description: "Managed by the operator",
+		URL:         "https://operator.com",
+	})
+
+	handler := NewProjectsHandler(testStore, nil)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/projects", handler.CreateProject)
+
+	jsonData, _ := json.Marshal(models.Project{
+		ID:          "test-1",
+		Name:        "Typo",
+		Description: "A duplicate ID",
+		URL:         "https://typo.com",
+	})
+	req, _ := http.NewRequest("POST", "/api/projects", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	project, err := testStore.GetByID("test-1")
+	require.NoError(t, err)
+	assert.Equal(t, "Operator Project", project.Name)
+}
+
 func TestCreateProject_MissingID(t *testing.T) {
 	router := setupRouter()
 	project := models.Project{
@@ -168,6 +257,39 @@ func TestCreateProject_MissingID(t *testing.T) {
 	assert.Contains(t, response["error"].(string), "id is required")
 }
 
+func TestCreateProject_ValidationErrors(t *testing.T) {
+	router := setupRouter()
+	project := models.Project{
+		ID:          "Invalid_ID",
+		Name:        "New Project",
+		Description: "A new project",
+		URL:         "not-a-uri",
+		Status:      "unknown",
+	}
+
+	jsonData, _ := json.Marshal(project)
+	req, _ := http.NewRequest("POST", "/api/projects", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response struct {
+		Error  string              `json:"error"`
+		Fields []models.FieldError `json:"fields"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+	assert.NotEmpty(t, response.Error)
+
+	fields := make([]string, 0, len(response.Fields))
+	for _, f := range response.Fields {
+		fields = append(fields, f.Field)
+	}
+	assert.ElementsMatch(t, []string{"id", "url", "status"}, fields)
+}
+
 func TestCreateProject_InvalidJSON(t *testing.T) {
 	router := setupRouter()
 	req, _ := http.NewRequest("POST", "/api/projects", bytes.NewBufferString("invalid json"))
@@ -187,7 +309,7 @@ func TestUpdateProject_Success(t *testing.T) {
 		URL:         "https://test.com",
 	})
 
-	handler := NewProjectsHandler(testStore)
+	handler := NewProjectsHandler(testStore, nil)
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
 	api := router.Group("/api")
@@ -214,20 +336,463 @@ func TestUpdateProject_Success(t *testing.T) {
 	assert.Equal(t, "Updated Project", result.Name)
 }
 
+func TestProject_ChangeMetadata(t *testing.T) {
+	router := setupRouter()
+
+	write := func(method, path, updatedBy string, project models.Project) models.Project {
+		jsonData, _ := json.Marshal(project)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(UpdatedByHeader, updatedBy)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Less(t, w.Code, 300, w.Body.String())
+
+		var result models.Project
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		return result
+	}
+
+	created := write("POST", "/api/projects", "alice", models.Project{
+		ID:          "test-1",
+		Name:        "Test Project",
+		Description: "A test project",
+		URL:         "https://test.com",
+		UpdatedBy:   "mallory",
+	})
+	assert.Equal(t, "alice", created.UpdatedBy)
+	assert.False(t, created.CreatedAt.IsZero())
+	assert.Equal(t, created.CreatedAt, created.UpdatedAt)
+
+	time.Sleep(2 * time.Millisecond)
+	write("POST", "/api/projects", "alice", models.Project{
+		ID:          "test-2",
+		Name:        "Second Project",
+		Description: "Another test project",
+		URL:         "https://test.com",
+	})
+
+	time.Sleep(2 * time.Millisecond)
+	updated := write("PUT", "/api/projects/test-1", "bob", models.Project{
+		Name:        "Updated Project",
+		Description: "An updated project",
+		URL:         "https://updated.com",
+		CreatedAt:   time.Unix(0, 0),
+	})
+	assert.Equal(t, "bob", updated.UpdatedBy)
+	assert.True(t, updated.CreatedAt.Equal(created.CreatedAt))
+	assert.True(t, updated.UpdatedAt.After(created.UpdatedAt))
+
+	req, _ := http.NewRequest("GET", "/api/projects?sort=-updatedAt", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response store.ListResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Projects, 2)
+	assert.Equal(t, "test-1", response.Projects[0].ID)
+	assert.Equal(t, "test-2", response.Projects[1].ID)
+}
+
+func TestProject_UpdatedByAuthenticatedCaller(t *testing.T) {
+	keys, err := auth.NewAPIKeys("ci=ci-token", "")
+	require.NoError(t, err)
+
+	handler := NewProjectsHandler(store.NewStore(), nil)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/projects", auth.Require(keys), handler.CreateProject)
+
+	jsonData, _ := json.Marshal(models.Project{
+		ID:          "test-1",
+		Name:        "Test Project",
+		Description: "A test project",
+		URL:         "https://test.com",
+	})
+	req, _ := http.NewRequest("POST", "/api/projects", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer ci-token")
+	// The header cannot override the authenticated identity
+	req.Header.Set(UpdatedByHeader, "someone-else")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var result models.Project
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, "ci", result.UpdatedBy)
+}
+
+func TestProject_RoleBasedAuthorization(t *testing.T) {
+	keys, err := auth.NewAPIKeys("admin:admin=admin-token,editor:editor=editor-token,viewer:viewer=viewer-token,operator:operator=operator-token", "")
+	require.NoError(t, err)
+
+	handler := NewProjectsHandler(store.NewStore(), nil)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	write := router.Group("/api", auth.Require(keys), auth.RequireAnyPermission(auth.PermissionWrite, auth.PermissionWriteManaged))
+	write.POST("/projects", handler.CreateProject)
+	write.PUT("/projects/:id", handler.UpdateProject)
+	write.PATCH("/projects/:id", handler.PatchProject)
+	write.DELETE("/projects/:id", handler.DeleteProject)
+
+	send := func(method, path, token string, body interface{}) *httptest.ResponseRecorder {
+		var req *http.Request
+		if body != nil {
+			jsonData, _ := json.Marshal(body)
+			req, _ = http.NewRequest(method, path, bytes.NewBuffer(jsonData))
+		} else {
+			req, _ = http.NewRequest(method, path, nil)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if method == "PATCH" {
+			req.Header.Set("Content-Type", "application/merge-patch+json")
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	project := func(id string) models.Project {
+		return models.Project{ID: id, Name: "Project " + id, Description: "A test project", URL: "https://test.com"}
+	}
+	managedBy := func(w *httptest.ResponseRecorder) string {
+		var result models.Project
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		return result.ManagedBy
+	}
+	missingPermission := func(w *httptest.ResponseRecorder) string {
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Contains(t, response["error"], "lacks the")
+		return fmt.Sprint(response["missingPermission"])
+	}
+
+	// Viewers cannot write at all
+	w := send("POST", "/api/projects", "viewer-token", project("manual"))
+	require.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, "projects:write", missingPermission(w))
+
+	// Projects created by the operator are managed by it
+	w = send("POST", "/api/projects", "editor-token", project("manual"))
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Empty(t, managedBy(w))
+	w = send("PUT", "/api/projects/synced", "operator-token", project("synced"))
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, models.ManagedByOperator, managedBy(w))
+
+	// Editors cannot touch operator-managed projects
+	for _, method := range []string{"PUT", "PATCH", "DELETE"} {
+		var body interface{}
+		if method != "DELETE" {
+			body = project("synced")
+		}
+		w = send(method, "/api/projects/synced", "editor-token", body)
+		require.Equal(t, http.StatusForbidden, w.Code, method)
+		assert.Equal(t, "projects:write-managed", missingPermission(w))
+	}
+
+	// The operator cannot touch manually created projects
+	w = send("PUT", "/api/projects/manual", "operator-token", project("manual"))
+	require.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, "projects:write", missingPermission(w))
+
+	// Owners keep access, and ownership survives their updates
+	w = send("PUT", "/api/projects/synced", "operator-token", project("synced"))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, models.ManagedByOperator, managedBy(w))
+	w = send("PUT", "/api/projects/manual", "editor-token", project("manual"))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// Admins can write everything
+	w = send("PUT", "/api/projects/synced", "admin-token", project("synced"))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, models.ManagedByOperator, managedBy(w))
+	w = send("DELETE", "/api/projects/manual", "admin-token", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Creating a project replaces a trashed one, which needs access to it too
+	w = send("DELETE", "/api/projects/synced", "admin-token", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	for _, method := range []string{"POST", "PUT"} {
+		path := "/api/projects"
+		if method == "PUT" {
+			path += "/manual"
+		}
+		w = send(method, path, "operator-token", project("manual"))
+		require.Equal(t, http.StatusForbidden, w.Code, method)
+		assert.Equal(t, "projects:write", missingPermission(w))
+	}
+	w = send("PUT", "/api/projects/synced", "editor-token", project("synced"))
+	require.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, "projects:write-managed", missingPermission(w))
+	w = send("PUT", "/api/projects/synced", "operator-token", project("synced"))
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, models.ManagedByOperator, managedBy(w))
+}
+
+// racingStore runs afterRead once, right after the handler's first read of
+// a project or of all projects, to simulate a concurrent write. A non-nil
+// readErr fails reads of a project.
+type racingStore struct {
+	store.Store
+	afterRead func()
+	readErr   error
+	deletes   int
+}
+
+func (s *racingStore) GetByID(id string) (*models.Project, error) {
+	if s.readErr != nil {
+		return nil, s.readErr
+	}
+	project, err := s.Store.GetByID(id)
+	s.raced()
+	return project, err
+}
+
+func (s *racingStore) GetAll() ([]*models.Project, error) {
+	projects, err := s.Store.GetAll()
+	s.raced()
+	return projects, err
+}
+
+func (s *racingStore) raced() {
+	if s.afterRead != nil {
+		afterRead := s.afterRead
+		s.afterRead = nil
+		afterRead()
+	}
+}
+
+func (s *racingStore) Delete(id string, version int64) (*models.Project, error) {
+	s.deletes++
+	return s.Store.Delete(id, version)
+}
+
+func TestProject_WritesRecheckConcurrentChanges(t *testing.T) {
+	keys, err := auth.NewAPIKeys("editor:editor=editor-token,operator:operator=operator-token", "")
+	require.NoError(t, err)
+	inner := store.NewStore()
+	racing := &racingStore{Store: inner}
+	handler := NewProjectsHandler(racing, nil)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	write := router.Group("/api", auth.Require(keys), auth.RequireAnyPermission(auth.PermissionWrite, auth.PermissionWriteManaged))
+	write.PUT("/projects/:id", handler.UpdateProject)
+	write.DELETE("/projects/:id", handler.DeleteProject)
+
+	send := func(method, path, token string, body interface{}) *httptest.ResponseRecorder {
+		jsonData, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	project := func(id, name string) *models.Project {
+		return &models.Project{ID: id, Name: name, Description: "A test project", URL: "https://test.com"}
+	}
+
+	// An editor creates the project after the operator saw none: the
+	// operator's write is checked again and refused instead of replacing it
+	racing.afterRead = func() { require.NoError(t, inner.Create(project("manual", "By hand"))) }
+	w := send("PUT", "/api/projects/manual", "operator-token", project("manual", "Synced"))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	current, err := inner.GetByID("manual")
+	require.NoError(t, err)
+	assert.Equal(t, "By hand", current.Name)
+	assert.Empty(t, current.ManagedBy)
+
+	// An update racing another writer is applied to the latest version
+	racing.afterRead = func() {
+		changed := project("manual", "Renamed")
+		require.NoError(t, inner.Update(changed))
+	}
+	w = send("PUT", "/api/projects/manual", "editor-token", project("manual", "Edited"))
+	assert.Equal(t, http.StatusOK, w.Code)
+	current, err = inner.GetByID("manual")
+	require.NoError(t, err)
+	assert.Equal(t, "Edited", current.Name)
+
+	// A delete without If-Match only removes the project it checked
+	racing.afterRead = func() { require.NoError(t, inner.Update(project("manual", "Changed again"))) }
+	w = send("DELETE", "/api/projects/manual", "editor-token", nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	_, err = inner.GetByID("manual")
+	assert.NoError(t, err)
+
+	// A failed read is reported rather than skipping the authorization
+	racing.readErr = errors.New("store unavailable")
+	deletes := racing.deletes
+	w = send("DELETE", "/api/projects/manual", "editor-token", nil)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, deletes, racing.deletes)
+}
+
+func TestUpdateProject_ValidationErrors(t *testing.T) {
+	testStore := store.NewStore()
+	testStore.Create(&models.Project{
+		ID:          "test-1",
+		Name:        "Test Project",
+		Description: "A test project",
+		URL:         "https://test.com",
+	})
+
+	handler := NewProjectsHandler(testStore, nil)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.PUT("/api/projects/:id", handler.UpdateProject)
+
+	jsonData, _ := json.Marshal(models.Project{Name: "Updated Project", URL: "https://updated.com"})
+	req, _ := http.NewRequest("PUT", "/api/projects/test-1", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// The stored project is left untouched
+	project, err := testStore.GetByID("test-1")
+	require.NoError(t, err)
+	assert.Equal(t, "Test Project", project.Name)
+}
+
+func TestUpdateProject_CreatesWhenMissing(t *testing.T) {
+	testStore := store.NewStore()
+	handler := NewProjectsHandler(testStore, nil)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.PUT("/api/projects/:id", handler.UpdateProject)
+
+	jsonData, _ := json.Marshal(models.Project{
+		Name:        "New Project",
+		Description: "A new project",
+		URL:         "https://new.com",
+	})
+	req, _ := http.NewRequest("PUT", "/api/projects/new-project", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	project, err := testStore.GetByID("new-project")
+	require.NoError(t, err)
+	assert.Equal(t, "New Project", project.Name)
+
+	// A second PUT replaces the project
+	req, _ = http.NewRequest("PUT", "/api/projects/new-project", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestUpdateProject_UnchangedContent(t *testing.T) {
+	auditLog := audit.NewMemoryLog(0)
+	router := setupAuditRouter(auditLog)
+	body := `{"name":"Project","description":"D","url":"https://example.com","status":"active"}`
+
+	send := func(method, contentType, body string, header ...string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, "/api/projects/p1", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", contentType)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("PUT", "application/json", body)
+	require.Equal(t, http.StatusCreated, w.Code)
+	etag := w.Header().Get("ETag")
+	var created models.Project
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	// Repeating the PUT, with or without a matching If-Match, or patching in
+	// the values already stored returns the stored project untouched
+	for _, w := range []*httptest.ResponseRecorder{
+		send("PUT", "application/json", body),
+		send("PUT", "application/json", body, "If-Match", etag),
+		send("PUT", "application/json", body, "If-Match", "*"),
+		send("PATCH", mergePatchContentType, `{"status":"active"}`),
+	} {
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, etag, w.Header().Get("ETag"))
+		var response models.Project
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, created, response)
+	}
+	events, err := auditLog.Query(audit.Query{})
+	require.NoError(t, err)
+	assert.Len(t, events, 1, "Unchanged writes are not recorded")
+
+	// A stale If-Match still fails, even without changes
+	w = send("PUT", "application/json", body, "If-Match", `"999"`)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+}
+
+func TestUpdateProject_IfNoneMatch(t *testing.T) {
+	testStore := store.NewStore()
+	testStore.Create(&models.Project{
+		ID:          "test-1",
+		Name:        "Test Project",
+		Description: "A test project",
+		URL:         "https://test.com",
+	})
+
+	handler := NewProjectsHandler(testStore, nil)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.PUT("/api/projects/:id", handler.UpdateProject)
+
+	jsonData, _ := json.Marshal(models.Project{
+		Name:        "Clobbered",
+		Description: "Should not be written",
+		URL:         "https://clobbered.com",
+	})
+
+	// Existing project: create-only request is rejected
+	req, _ := http.NewRequest("PUT", "/api/projects/test-1", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-None-Match", "*")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	project, _ := testStore.GetByID("test-1")
+	assert.Equal(t, "Test Project", project.Name)
+
+	// Missing project: create-only request succeeds
+	req, _ = http.NewRequest("PUT", "/api/projects/test-2", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-None-Match", "*")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
 func TestUpdateProject_NotFound(t *testing.T) {
 	router := setupRouter()
 	updated := models.Project{
-		Name: "Updated Project",
-		URL:  "https://updated.com",
+		Name:        "Updated Project",
+		Description: "An updated project",
+		URL:         "https://updated.com",
 	}
 
 	jsonData, _ := json.Marshal(updated)
 	req, _ := http.NewRequest("PUT", "/api/projects/non-existent", bytes.NewBuffer(jsonData))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", "*")
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
-	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
 
 	var response map[string]interface{}
 	err := json.Unmarshal(w.Body.Bytes(), &response)
@@ -235,6 +800,202 @@ func TestUpdateProject_NotFound(t *testing.T) {
 	assert.Equal(t, "project not found", response["error"])
 }
 
+func TestProject_ETagAndIfMatch(t *testing.T) {
+	router := setupRouter()
+
+	jsonData, _ := json.Marshal(models.Project{
+		ID:          "test-1",
+		Name:        "Test Project",
+		Description: "A test project",
+		URL:         "https://test.com",
+	})
+	req, _ := http.NewRequest("POST", "/api/projects", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	req, _ = http.NewRequest("GET", "/api/projects/test-1", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	var project models.Project
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &project))
+	assert.Equal(t, fmt.Sprintf("%q", strconv.FormatInt(project.ResourceVersion, 10)), etag)
+
+	update := func(ifMatch string) *httptest.ResponseRecorder {
+		jsonData, _ := json.Marshal(models.Project{
+			Name:        "Updated Project",
+			Description: "An updated project",
+			URL:         "https://updated.com",
+		})
+		req, _ := http.NewRequest("PUT", "/api/projects/test-1", bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", ifMatch)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Matching ETag succeeds and issues a new one
+	w = update(etag)
+	assert.Equal(t, http.StatusOK, w.Code)
+	newETag := w.Header().Get("ETag")
+	assert.NotEqual(t, etag, newETag)
+
+	// The old ETag is now stale
+	w = update(etag)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	// Tags this server never issues cannot match
+	w = update("W/\"1\"")
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	// Stale delete is rejected, current delete succeeds
+	req, _ = http.NewRequest("DELETE", "/api/projects/test-1", nil)
+	req.Header.Set("If-Match", etag)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	req, _ = http.NewRequest("DELETE", "/api/projects/test-1", nil)
+	req.Header.Set("If-Match", newETag)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func newPatchTestRouter(t *testing.T) (*gin.Engine, *store.MemoryStore) {
+	testStore := store.NewStore()
+	require.NoError(t, testStore.Create(&models.Project{
+		ID:          "test-1",
+		Name:        "Test Project",
+		Description: "A test project",
+		URL:         "https://test.com",
+		Icon:        "https://test.com/icon.png",
+		Category:    "Testing",
+		Status:      "active",
+	}))
+
+	handler := NewProjectsHandler(testStore, nil)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.PATCH("/api/projects/:id", handler.PatchProject)
+	return router, testStore
+}
+
+func patchProject(router *gin.Engine, id, contentType, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("PATCH", "/api/projects/"+id, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestPatchProject_MergePatch(t *testing.T) {
+	router, testStore := newPatchTestRouter(t)
+
+	w := patchProject(router, "test-1", "application/merge-patch+json", `{"status": "archived", "category": null}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.NotEmpty(t, w.Header().Get("ETag"))
+
+	var response models.Project
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "archived", response.Status)
+	assert.Empty(t, response.Category)
+	// Fields the patch does not mention are kept
+	assert.Equal(t, "https://test.com/icon.png", response.Icon)
+	assert.Equal(t, "Test Project", response.Name)
+
+	stored, err := testStore.GetByID("test-1")
+	require.NoError(t, err)
+	assert.Equal(t, "archived", stored.Status)
+	assert.Equal(t, response.ResourceVersion, stored.ResourceVersion)
+}
+
+func TestPatchProject_JSONPatch(t *testing.T) {
+	router, _ := newPatchTestRouter(t)
+
+	w := patchProject(router, "test-1", "application/json-patch+json", `[
+		{"op": "test", "path": "/status", "value": "active"},
+		{"op": "replace", "path": "/status", "value": "maintenance"},
+		{"op": "remove", "path": "/icon"}
+	]`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response models.Project
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "maintenance", response.Status)
+	assert.Empty(t, response.Icon)
+	assert.Equal(t, "Testing", response.Category)
+
+	// The test operation now fails, so nothing is applied
+	w = patchProject(router, "test-1", "application/json-patch+json", `[
+		{"op": "test", "path": "/status", "value": "active"},
+		{"op": "replace", "path": "/name", "value": "Renamed"}
+	]`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestPatchProject_Errors(t *testing.T) {
+	router, testStore := newPatchTestRouter(t)
+
+	tests := []struct {
+		name        string
+		id          string
+		contentType string
+		body        string
+		wantStatus  int
+	}{
+		{"plain JSON", "test-1", "application/json", `{"status": "archived"}`, http.StatusUnsupportedMediaType},
+		{"not found", "missing", "application/merge-patch+json", `{"status": "archived"}`, http.StatusNotFound},
+		{"malformed merge patch", "test-1", "application/merge-patch+json", `{"status":`, http.StatusBadRequest},
+		{"malformed JSON patch", "test-1", "application/json-patch+json", `{"op": "remove"}`, http.StatusBadRequest},
+		{"missing path", "test-1", "application/json-patch+json", `[{"op": "remove", "path": "/nope"}]`, http.StatusBadRequest},
+		{"wrong type", "test-1", "application/merge-patch+json", `{"name": 42}`, http.StatusBadRequest},
+		{"invalid result", "test-1", "application/merge-patch+json", `{"status": "unknown", "name": null}`, http.StatusBadRequest},
+		{"changed ID", "test-1", "application/json-patch+json", `[{"op": "replace", "path": "/id", "value": "other"}]`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := patchProject(router, tt.id, tt.contentType, tt.body)
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+		})
+	}
+
+	// Rejected patches leave the project untouched
+	stored, err := testStore.GetByID("test-1")
+	require.NoError(t, err)
+	assert.Equal(t, "active", stored.Status)
+	assert.Equal(t, "Test Project", stored.Name)
+}
+
+func TestPatchProject_IfMatch(t *testing.T) {
+	router, testStore := newPatchTestRouter(t)
+	stored, err := testStore.GetByID("test-1")
+	require.NoError(t, err)
+	etag := fmt.Sprintf("%q", strconv.FormatInt(stored.ResourceVersion, 10))
+
+	patch := func(ifMatch string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("PATCH", "/api/projects/test-1", bytes.NewBufferString(`{"status": "inactive"}`))
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		req.Header.Set("If-Match", ifMatch)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := patch(etag)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// The project has moved on, so the old ETag no longer matches
+	w = patch(etag)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+}
+
 func TestDeleteProject_Success(t *testing.T) {
 	testStore := store.NewStore()
 	testStore.Create(&models.Project{
@@ -244,7 +1005,7 @@ func TestDeleteProject_Success(t *testing.T) {
 		URL:         "https://test.com",
 	})
 
-	handler := NewProjectsHandler(testStore)
+	handler := NewProjectsHandler(testStore, nil)
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
 	api := router.Group("/api")
@@ -257,8 +1018,8 @@ func TestDeleteProject_Success(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 
 	// Verify project is deleted
-	_, exists := testStore.GetByID("test-1")
-	assert.False(t, exists, "Project should be deleted")
+	_, err := testStore.GetByID("test-1")
+	assert.ErrorIs(t, err, store.ErrNotFound, "Project should be deleted")
 }
 
 func TestDeleteProject_NotFound(t *testing.T) {
@@ -274,3 +1035,73 @@ func TestDeleteProject_NotFound(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "project not found", response["error"])
 }
+
+func TestProject_TrashAndRestore(t *testing.T) {
+	testStore := store.NewStore()
+	testStore.Create(&models.Project{ID: "test-1", Name: "Test Project", Description: "A test project", URL: "https://test.com"})
+	auditLog := audit.NewMemoryLog(0)
+	handler := NewProjectsHandler(testStore, auditLog)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	api := router.Group("/api")
+	api.GET("/projects", handler.GetProjects)
+	api.DELETE("/projects/:id", handler.DeleteProject)
+	api.GET("/trash", handler.GetTrash)
+	api.POST("/projects/:id/restore", handler.RestoreProject)
+
+	req, _ := http.NewRequest("DELETE", "/api/projects/test-1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest("GET", "/api/projects", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var list store.ListResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Equal(t, 0, list.Total, "Trashed projects are not listed")
+
+	req, _ = http.NewRequest("GET", "/api/trash", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var trash struct {
+		Projects []models.Project `json:"projects"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &trash))
+	require.Len(t, trash.Projects, 1)
+	assert.Equal(t, "test-1", trash.Projects[0].ID)
+	assert.False(t, trash.Projects[0].DeletedAt.IsZero())
+
+	req, _ = http.NewRequest("POST", "/api/projects/test-1/restore", nil)
+	req.Header.Set("If-Match", `"1"`)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code, "The ETag of the live project no longer matches")
+
+	req, _ = http.NewRequest("POST", "/api/projects/test-1/restore", nil)
+	req.Header.Set("If-Match", etag(&trash.Projects[0]))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var restored models.Project
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &restored))
+	assert.Equal(t, "Test Project", restored.Name)
+	assert.True(t, restored.DeletedAt.IsZero())
+	assert.Equal(t, etag(&restored), w.Header().Get("ETag"))
+
+	_, err := testStore.GetByID("test-1")
+	assert.NoError(t, err)
+
+	req, _ = http.NewRequest("POST", "/api/projects/test-1/restore", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	events, err := auditLog.Query(audit.Query{})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, audit.ActionDelete, events[0].Action)
+	assert.Equal(t, audit.ActionRestore, events[1].Action)
+}
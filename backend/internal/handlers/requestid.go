@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader carries the ID correlating a request with its audit events
+// and logs
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds the length of a client-supplied request ID
+const maxRequestIDLength = 128
+
+const requestIDKey = "handlers.requestID"
+
+// RequestID tags every request with an ID, keeping the caller's
+// RequestIDHeader when sent and generating one otherwise. The ID is echoed
+// in the response header.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" || len(id) > maxRequestIDLength {
+			id = newRequestID()
+		}
+		c.Set(requestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// RequestIDFrom returns the ID RequestID assigned to the request, or the
+// RequestIDHeader the caller sent when the middleware is not installed
+func RequestIDFrom(c *gin.Context) string {
+	if id := c.GetString(requestIDKey); id != "" {
+		return id
+	}
+	return c.GetHeader(RequestIDHeader)
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
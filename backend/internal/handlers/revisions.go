@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"0xhub/backend/internal/audit"
+	"0xhub/backend/internal/models"
+	"0xhub/backend/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RevisionsHandler serves the revision history of projects
+type RevisionsHandler struct {
+	store    store.Store
+	history  store.History
+	auditLog audit.Log
+}
+
+// NewRevisionsHandler creates a new revisions handler. Restores are written
+// to store and recorded in auditLog, which may be nil.
+func NewRevisionsHandler(store store.Store, history store.History, auditLog audit.Log) *RevisionsHandler {
+	return &RevisionsHandler{
+		store:    store,
+		history:  history,
+		auditLog: auditLog,
+	}
+}
+
+// ListRevisions returns the retained revisions of a project, newest first,
+// each with the fields changed from the revision before it
+func (h *RevisionsHandler) ListRevisions(c *gin.Context) {
+	revisions, err := h.history.Revisions(c.Param("id"))
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"revisions": revisions,
+	})
+}
+
+// GetRevision returns a single revision of a project with the fields that
+// differ from another revision, given by the against query parameter, or
+// from the current project by default
+func (h *RevisionsHandler) GetRevision(c *gin.Context) {
+	id := c.Param("id")
+	revision, ok := h.revision(c, id, c.Param("rev"))
+	if !ok {
+		return
+	}
+
+	var against *models.Project
+	if raw := c.Query("against"); raw != "" {
+		other, ok := h.revision(c, id, raw)
+		if !ok {
+			return
+		}
+		against = &other.Project
+	} else {
+		current, err := h.store.GetByID(id)
+		if err != nil {
+			respondStoreError(c, err)
+			return
+		}
+		against = current
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"revision": revision.Revision,
+		"project":  revision.Project,
+		"against":  against.ResourceVersion,
+		"changes":  models.Diff(against, &revision.Project),
+	})
+}
+
+// RestoreRevision writes a project back as it was at a revision. The restore
+// is a new revision with a new resource version; server-managed fields are
+// not restored. An If-Match header makes it conditional on the project's
+// current ETag; without one, a project changed concurrently is read again and
+// the restore retried.
+func (h *RevisionsHandler) RestoreRevision(c *gin.Context) {
+	id := c.Param("id")
+	version, conditional, ok := ifMatchVersion(c)
+	if conditional && !ok {
+		respondPreconditionFailed(c, "If-Match does not match the current project")
+		return
+	}
+
+	revision, ok := h.revision(c, id, c.Param("rev"))
+	if !ok {
+		return
+	}
+	var current *models.Project
+	var project models.Project
+	for attempt := 1; ; attempt++ {
+		var err error
+		current, err = h.store.GetByID(id)
+		if err != nil {
+			respondStoreError(c, err)
+			return
+		}
+		if _, ok := authorizeWrite(c, current); !ok {
+			return
+		}
+
+		project = revision.Project
+		// Without If-Match, write over the project the authorization
+		// checked, so a concurrent change is never silently overwritten
+		project.ResourceVersion = current.ResourceVersion
+		if conditional {
+			project.ResourceVersion = version
+		}
+		project.UpdatedBy = caller(c)
+		err = h.store.Update(&project)
+		if conditional && errors.Is(err, store.ErrNotFound) {
+			respondPreconditionFailed(c, "project not found")
+			return
+		}
+		if !conditional && isWriteRace(err) {
+			if attempt < maxPatchAttempts {
+				continue
+			}
+			respondConcurrentChange(c)
+			return
+		}
+		if err != nil {
+			respondStoreError(c, err)
+			return
+		}
+		break
+	}
+	recordAudit(c, h.auditLog, audit.ActionUpdate, id, current, &project)
+
+	c.Header("ETag", etag(&project))
+	c.JSON(http.StatusOK, project)
+}
+
+// revision looks up a revision of a project, responding with an error if it
+// is not found
+func (h *RevisionsHandler) revision(c *gin.Context, id, raw string) (*store.Revision, bool) {
+	rev, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || rev <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "revision must be a positive integer",
+		})
+		return nil, false
+	}
+	revision, err := h.history.Revision(id, rev)
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "revision not found",
+		})
+		return nil, false
+	}
+	if err != nil {
+		respondStoreError(c, err)
+		return nil, false
+	}
+	return revision, true
+}
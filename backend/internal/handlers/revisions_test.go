@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"0xhub/backend/internal/audit"
+	"0xhub/backend/internal/models"
+	"0xhub/backend/internal/store"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupRevisionsRouter(t *testing.T, auditLog audit.Log) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	history, err := store.NewHistoryStore(store.NewStore(), nil, 0)
+	require.NoError(t, err)
+	projects := NewProjectsHandler(history, auditLog)
+	revisions := NewRevisionsHandler(history, history, auditLog)
+
+	router := gin.New()
+	api := router.Group("/api")
+	{
+		api.GET("/projects/:id", projects.GetProject)
+		api.PUT("/projects/:id", projects.UpdateProject)
+		api.DELETE("/projects/:id", projects.DeleteProject)
+		api.GET("/projects/:id/revisions", revisions.ListRevisions)
+		api.GET("/projects/:id/revisions/:rev", revisions.GetRevision)
+		api.POST("/projects/:id/revisions/:rev/restore", revisions.RestoreRevision)
+	}
+	return router
+}
+
+func putRevision(t *testing.T, router *gin.Engine, name string) int64 {
+	t.Helper()
+	body := fmt.Sprintf(`{"name":%q,"description":"D","url":"https://example.com"}`, name)
+	req, _ := http.NewRequest("PUT", "/api/projects/p1", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Contains(t, []int{http.StatusOK, http.StatusCreated}, w.Code, w.Body.String())
+
+	var project struct {
+		ResourceVersion int64 `json:"resourceVersion"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &project))
+	return project.ResourceVersion
+}
+
+func TestRevisions_ListAndDiff(t *testing.T) {
+	router := setupRevisionsRouter(t, nil)
+	first := putRevision(t, router, "First")
+	second := putRevision(t, router, "Second")
+
+	req, _ := http.NewRequest("GET", "/api/projects/p1/revisions", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var list struct {
+		Revisions []store.Revision `json:"revisions"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Revisions, 2)
+	assert.Equal(t, second, list.Revisions[0].Revision)
+	assert.Equal(t, "Second", list.Revisions[0].Project.Name)
+	assert.Equal(t, first, list.Revisions[1].Revision)
+
+	// The first revision differs from the current project by its name
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/api/projects/p1/revisions/%d", first), nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var diff struct {
+		Revision int64 `json:"revision"`
+		Against  int64 `json:"against"`
+		Changes  []struct {
+			Field  string      `json:"field"`
+			Before interface{} `json:"before"`
+			After  interface{} `json:"after"`
+		} `json:"changes"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &diff))
+	assert.Equal(t, first, diff.Revision)
+	assert.Equal(t, second, diff.Against)
+	var name bool
+	for _, change := range diff.Changes {
+		if change.Field == "name" {
+			name = true
+			assert.Equal(t, "Second", change.Before)
+			assert.Equal(t, "First", change.After)
+		}
+	}
+	assert.True(t, name, "Expected a name change in %+v", diff.Changes)
+
+	for path, code := range map[string]int{
+		"/api/projects/p1/revisions/999":                                http.StatusNotFound,
+		"/api/projects/p1/revisions/abc":                                http.StatusBadRequest,
+		fmt.Sprintf("/api/projects/p1/revisions/%d?against=999", first): http.StatusNotFound,
+		"/api/projects/missing/revisions":                               http.StatusNotFound,
+	} {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, code, w.Code, path)
+	}
+}
+
+func TestRevisions_Restore(t *testing.T) {
+	auditLog := audit.NewMemoryLog(0)
+	router := setupRevisionsRouter(t, auditLog)
+	first := putRevision(t, router, "First")
+	second := putRevision(t, router, "Second")
+
+	// A stale If-Match is refused
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/api/projects/p1/revisions/%d/restore", first), nil)
+	req.Header.Set("If-Match", fmt.Sprintf(`"%d"`, first))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	req, _ = http.NewRequest("POST", fmt.Sprintf("/api/projects/p1/revisions/%d/restore", first), nil)
+	req.Header.Set("If-Match", fmt.Sprintf(`"%d"`, second))
+	req.Header.Set(UpdatedByHeader, "alice")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var restored struct {
+		Name            string `json:"name"`
+		ResourceVersion int64  `json:"resourceVersion"`
+		UpdatedBy       string `json:"updatedBy"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &restored))
+	assert.Equal(t, "First", restored.Name)
+	assert.Greater(t, restored.ResourceVersion, second, "A restore is a new revision")
+	assert.Equal(t, "alice", restored.UpdatedBy)
+	assert.Equal(t, fmt.Sprintf(`"%d"`, restored.ResourceVersion), w.Header().Get("ETag"))
+
+	events, err := auditLog.Query(audit.Query{})
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.Equal(t, audit.ActionUpdate, events[2].Action)
+	assert.Equal(t, "Second", events[2].Before.Name)
+	assert.Equal(t, "First", events[2].After.Name)
+
+	// A trashed project has no current version to restore a revision over
+	req, _ = http.NewRequest("DELETE", "/api/projects/p1", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest("POST", fmt.Sprintf("/api/projects/p1/revisions/%d/restore", first), nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestRevisions_RestoreRetriesConcurrentChanges(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	history, err := store.NewHistoryStore(store.NewStore(), nil, 0)
+	require.NoError(t, err)
+	racing := &racingStore{Store: history}
+	auditLog := audit.NewMemoryLog(0)
+	router := gin.New()
+	router.PUT("/api/projects/:id", NewProjectsHandler(history, nil).UpdateProject)
+	router.POST("/api/projects/:id/revisions/:rev/restore", NewRevisionsHandler(racing, history, auditLog).RestoreRevision)
+	first := putRevision(t, router, "First")
+	putRevision(t, router, "Second")
+
+	// Without If-Match, a change between reading and writing the project is
+	// read again rather than overwritten blindly
+	racing.afterRead = func() {
+		_, err := history.Put(&models.Project{ID: "p1", Name: "Third", URL: "https://example.com"})
+		require.NoError(t, err)
+	}
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/api/projects/p1/revisions/%d/restore", first), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	current, err := history.GetByID("p1")
+	require.NoError(t, err)
+	assert.Equal(t, "First", current.Name)
+	revisions, err := history.Revisions("p1")
+	require.NoError(t, err)
+	assert.Equal(t, "Third", revisions[1].Project.Name, "The concurrent write is kept in the history")
+	events, err := auditLog.Query(audit.Query{})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "Third", events[0].Before.Name, "The restore is recorded against the project it replaced")
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"0xhub/backend/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// SearchHandler handles full-text search requests
+type SearchHandler struct {
+	searcher store.Searcher
+}
+
+// NewSearchHandler creates a new search handler
+func NewSearchHandler(searcher store.Searcher) *SearchHandler {
+	return &SearchHandler{
+		searcher: searcher,
+	}
+}
+
+// Search returns projects ranked by relevance to the q query parameter
+func (h *SearchHandler) Search(c *gin.Context) {
+	query := c.Query("q")
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "q is required",
+		})
+		return
+	}
+
+	limit := defaultSearchLimit
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "limit must be a positive integer",
+			})
+			return
+		}
+		limit = min(n, maxSearchLimit)
+	}
+
+	results, err := h.searcher.Search(query, limit)
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"results": results,
+	})
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"0xhub/backend/internal/models"
+	"0xhub/backend/internal/store"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupSearchRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	testStore, err := store.NewIndexedStore(store.NewStore())
+	require.NoError(t, err)
+	testStore.Create(&models.Project{ID: "1", Name: "Prometheus", Description: "Monitoring toolkit", URL: "https://prometheus.io"})
+	testStore.Create(&models.Project{ID: "2", Name: "Grafana", Description: "Dashboards for Prometheus metrics", URL: "https://grafana.com"})
+
+	handler := NewSearchHandler(testStore)
+	router := gin.New()
+	router.GET("/api/search", handler.Search)
+	return router
+}
+
+func TestSearch_Success(t *testing.T) {
+	router := setupSearchRouter(t)
+	req, _ := http.NewRequest("GET", "/api/search?q=prometheus", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Results []store.SearchResult `json:"results"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+	require.Len(t, response.Results, 2)
+	assert.Equal(t, "1", response.Results[0].Project.ID)
+	assert.Equal(t, "<mark>Prometheus</mark>", response.Results[0].Highlights["name"])
+}
+
+func TestSearch_Limit(t *testing.T) {
+	router := setupSearchRouter(t)
+	req, _ := http.NewRequest("GET", "/api/search?q=prometheus&limit=1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Results []store.SearchResult `json:"results"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+	assert.Len(t, response.Results, 1)
+}
+
+func TestSearch_BadRequest(t *testing.T) {
+	router := setupSearchRouter(t)
+
+	for _, query := range []string{"", "q=x&limit=0", "q=x&limit=abc"} {
+		req, _ := http.NewRequest("GET", "/api/search?"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
@@ -0,0 +1,321 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+
+	"0xhub/backend/internal/audit"
+	"0xhub/backend/internal/auth"
+	"0xhub/backend/internal/models"
+	"0xhub/backend/internal/store"
+
+	"github.com/gin-gonic/gin"
+	"sigs.k8s.io/yaml"
+)
+
+// Import modes
+const (
+	// importMerge creates and updates the imported projects, leaving every
+	// other project alone
+	importMerge = "merge"
+	// importReplace also moves projects missing from the import to the trash
+	importReplace = "replace"
+	// importDryRun is short for a merge with dryRun=true
+	importDryRun = "dry-run"
+)
+
+// Import item actions
+const (
+	importCreated = "created"
+	importUpdated = "updated"
+	importDeleted = "deleted"
+	// importSkipped items already match the stored project
+	importSkipped = "skipped"
+	importFailed  = "failed"
+)
+
+// maxImportSize bounds the size of an import document
+const maxImportSize = 10 << 20
+
+// yamlContentType is the media type of YAML documents
+const yamlContentType = "application/yaml"
+
+// transferDocument is the format of exports and imports
+type transferDocument struct {
+	Projects []*models.Project `json:"projects"`
+}
+
+// ImportItem reports what an import did, or would do, with one project
+type ImportItem struct {
+	// Index is the project's position in the import, absent for projects a
+	// replace deletes
+	Index  *int                    `json:"index,omitempty"`
+	ID     string                  `json:"id"`
+	Action string                  `json:"action"`
+	Error  string                  `json:"error,omitempty"`
+	Fields models.ValidationErrors `json:"fields,omitempty"`
+}
+
+// ImportReport is the response to an import
+type ImportReport struct {
+	Mode   string `json:"mode"`
+	DryRun bool   `json:"dryRun"`
+	// Applied reports whether the import changed the store. An import with
+	// any failed item applies nothing.
+	Applied bool           `json:"applied"`
+	Summary map[string]int `json:"summary"`
+	Items   []ImportItem   `json:"items"`
+}
+
+// Export returns every project, sorted by ID, as {"projects": [...]} in
+// YAML when the Accept header prefers it and JSON otherwise
+func (h *ProjectsHandler) Export(c *gin.Context) {
+	projects, err := h.store.GetAll()
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].ID < projects[j].ID
+	})
+	doc := transferDocument{Projects: projects}
+
+	switch c.NegotiateFormat(gin.MIMEJSON, yamlContentType, gin.MIMEYAML, "text/yaml") {
+	case yamlContentType, gin.MIMEYAML, "text/yaml":
+	default:
+		c.JSON(http.StatusOK, doc)
+		return
+	}
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.Data(http.StatusOK, yamlContentType, data)
+}
+
+// Import writes the projects in a JSON or YAML document, chosen by
+// Content-Type, in a single atomic store operation. The document is an
+// export, or a bare list of projects. Server-managed fields are ignored.
+//
+// The mode query parameter is merge (the default), replace or dry-run, and
+// dryRun=true previews either mode without writing. Every project is
+// validated and authorized first: if any fails, nothing is written and the
+// report is returned with 422 Unprocessable Entity. If a project changes
+// between the checks and the write, nothing is written and 409 Conflict is
+// returned.
+func (h *ProjectsHandler) Import(c *gin.Context) {
+	mode := c.DefaultQuery("mode", importMerge)
+	dryRun := c.Query("dryRun") == "true"
+	if mode == importDryRun {
+		mode, dryRun = importMerge, true
+	}
+	if mode != importMerge && mode != importReplace {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "mode must be merge, replace or dry-run",
+		})
+		return
+	}
+
+	projects, err := readImport(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	current, err := h.store.GetAll()
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	existing := make(map[string]*models.Project, len(current))
+	for _, p := range current {
+		existing[p.ID] = p
+	}
+	trash, err := h.store.Trash()
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	// Creating a project replaces a trashed one with its ID
+	trashed := make(map[string]*models.Project, len(trash))
+	for _, p := range trash {
+		trashed[p.ID] = p
+	}
+
+	report := &ImportReport{
+		Mode:    mode,
+		DryRun:  dryRun,
+		Summary: make(map[string]int),
+		Items:   make([]ImportItem, 0, len(projects)),
+	}
+	identity := auth.IdentityFrom(c)
+	updatedBy := caller(c)
+	seen := make(map[string]bool, len(projects))
+	var writes []store.Write
+	for i, project := range projects {
+		item := ImportItem{Index: &i}
+		if project == nil {
+			item.Action, item.Error = importFailed, "project must be an object"
+			report.add(item)
+			continue
+		}
+		item.ID = project.ID
+		previous := existing[project.ID]
+		switch {
+		case seen[project.ID]:
+			item.Action, item.Error = importFailed, "duplicate project id"
+		default:
+			seen[project.ID] = true
+			item.Action = importCreated
+			if previous != nil {
+				item.Action = importUpdated
+			}
+			if err := project.Validate(); err != nil {
+				item.Action, item.Error = importFailed, err.Error()
+				errors.As(err, &item.Fields)
+				break
+			}
+			var managedBy string
+			var denied *writeDenial
+			if previous == nil {
+				managedBy, denied = checkCreate(identity, trashed[project.ID])
+			} else {
+				managedBy, denied = checkWrite(identity, previous)
+			}
+			if denied != nil {
+				item.Action, item.Error = importFailed, denied.Error()
+				break
+			}
+			if previous != nil && previous.SameContent(project) {
+				item.Action = importSkipped
+				break
+			}
+			// Each write expects the project the checks ran on, so the batch
+			// fails if any changed concurrently
+			write := store.Write{Project: importedProject(project, managedBy, updatedBy), Create: previous == nil}
+			if previous != nil {
+				write.Version = previous.ResourceVersion
+			}
+			writes = append(writes, write)
+		}
+		report.add(item)
+	}
+
+	if mode == importReplace {
+		// Projects missing from the import are deleted in ID order
+		ids := make([]string, 0, len(existing))
+		for id := range existing {
+			if !seen[id] {
+				ids = append(ids, id)
+			}
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			item := ImportItem{ID: id, Action: importDeleted}
+			if _, denied := checkWrite(identity, existing[id]); denied != nil {
+				item.Action, item.Error = importFailed, denied.Error()
+			} else {
+				writes = append(writes, store.Write{Project: &models.Project{ID: id}, Delete: true, Version: existing[id].ResourceVersion})
+			}
+			report.add(item)
+		}
+	}
+
+	if report.Summary[importFailed] > 0 {
+		c.JSON(http.StatusUnprocessableEntity, report)
+		return
+	}
+	if dryRun || len(writes) == 0 {
+		c.JSON(http.StatusOK, report)
+		return
+	}
+
+	changes, err := h.store.Apply(writes)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) || errors.Is(err, store.ErrVersionConflict) || errors.Is(err, store.ErrAlreadyExists) {
+			// A project the import writes changed after it was checked
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "projects changed during the import, retry it",
+			})
+			return
+		}
+		respondStoreError(c, err)
+		return
+	}
+	report.Applied = true
+	for _, change := range changes {
+		switch change.Type {
+		case store.ChangeCreated:
+			h.record(c, audit.ActionCreate, change.Project.ID, nil, change.Project)
+		case store.ChangeUpdated:
+			h.record(c, audit.ActionUpdate, change.Project.ID, existing[change.Project.ID], change.Project)
+		case store.ChangeDeleted:
+			h.record(c, audit.ActionDelete, change.Project.ID, existing[change.Project.ID], nil)
+		}
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+func (r *ImportReport) add(item ImportItem) {
+	r.Items = append(r.Items, item)
+	r.Summary[item.Action]++
+}
+
+// readImport decodes the request body as a JSON or YAML import document
+func readImport(c *gin.Context) ([]*models.Project, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read import: %w", err)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	switch mediaType {
+	case "", gin.MIMEJSON:
+	case yamlContentType, gin.MIMEYAML, "text/yaml":
+		if body, err = yaml.YAMLToJSON(body); err != nil {
+			return nil, fmt.Errorf("invalid YAML: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported Content-Type %q, send JSON or YAML", mediaType)
+	}
+
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var projects []*models.Project
+		if err := json.Unmarshal(body, &projects); err != nil {
+			return nil, fmt.Errorf("invalid import: %w", err)
+		}
+		return projects, nil
+	}
+	var doc transferDocument
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("invalid import: %w", err)
+	}
+	return doc.Projects, nil
+}
+
+// importedProject copies the client-managed fields of an imported project
+func importedProject(project *models.Project, managedBy, updatedBy string) *models.Project {
+	return &models.Project{
+		ID:          project.ID,
+		Name:        project.Name,
+		Description: project.Description,
+		URL:         project.URL,
+		Icon:        project.Icon,
+		Category:    project.Category,
+		Status:      project.Status,
+		ManagedBy:   managedBy,
+		UpdatedBy:   updatedBy,
+	}
+}
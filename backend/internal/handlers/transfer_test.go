@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"0xhub/backend/internal/audit"
+	"0xhub/backend/internal/auth"
+	"0xhub/backend/internal/models"
+	"0xhub/backend/internal/store"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+func setupTransferRouter(s store.Store, auditLog audit.Log) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	handler := NewProjectsHandler(s, auditLog)
+
+	router := gin.New()
+	router.GET("/api/export", handler.Export)
+	router.POST("/api/import", handler.Import)
+	return router
+}
+
+func postImport(router *gin.Engine, query, contentType, body string) (*httptest.ResponseRecorder, ImportReport) {
+	req, _ := http.NewRequest("POST", "/api/import"+query, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var report ImportReport
+	json.Unmarshal(w.Body.Bytes(), &report)
+	return w, report
+}
+
+func itemActions(report ImportReport) map[string]string {
+	actions := make(map[string]string)
+	for _, item := range report.Items {
+		actions[item.ID] = item.Action
+	}
+	return actions
+}
+
+func TestExport_JSONAndYAML(t *testing.T) {
+	s := store.NewStore()
+	s.Create(&models.Project{ID: "b", Name: "Docker", Description: "D", URL: "https://docker.com"})
+	s.Create(&models.Project{ID: "a", Name: "Kubernetes", Description: "K", URL: "https://kubernetes.io"})
+	router := setupTransferRouter(s, nil)
+
+	req, _ := http.NewRequest("GET", "/api/export", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+	var doc transferDocument
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	require.Len(t, doc.Projects, 2)
+	assert.Equal(t, "a", doc.Projects[0].ID, "exports are sorted by ID")
+
+	req, _ = http.NewRequest("GET", "/api/export", nil)
+	req.Header.Set("Accept", "application/yaml")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/yaml", w.Header().Get("Content-Type"))
+	var yamlDoc transferDocument
+	require.NoError(t, yaml.Unmarshal(w.Body.Bytes(), &yamlDoc))
+	require.Len(t, yamlDoc.Projects, 2)
+	assert.Equal(t, "Kubernetes", yamlDoc.Projects[0].Name)
+}
+
+func TestImport_Merge(t *testing.T) {
+	s := store.NewStore()
+	s.Create(&models.Project{ID: "same", Name: "Same", Description: "D", URL: "https://same.io"})
+	s.Create(&models.Project{ID: "changed", Name: "Old", Description: "D", URL: "https://changed.io"})
+	s.Create(&models.Project{ID: "untouched", Name: "Untouched", Description: "D", URL: "https://untouched.io"})
+	auditLog := audit.NewMemoryLog(0)
+	router := setupTransferRouter(s, auditLog)
+
+	w, report := postImport(router, "", "application/json", `{"projects":[
+		{"id":"same","name":"Same","description":"D","url":"https://same.io","resourceVersion":99},
+		{"id":"changed","name":"New","description":"D","url":"https://changed.io"},
+		{"id":"new","name":"New","description":"D","url":"https://new.io","managedBy":"operator"}
+	]}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.True(t, report.Applied)
+	assert.Equal(t, map[string]string{"same": "skipped", "changed": "updated", "new": "created"}, itemActions(report))
+	assert.Equal(t, map[string]int{"skipped": 1, "updated": 1, "created": 1}, report.Summary)
+
+	changed, _ := s.GetByID("changed")
+	assert.Equal(t, "New", changed.Name)
+	created, _ := s.GetByID("new")
+	assert.Empty(t, created.ManagedBy, "server-managed fields are not imported")
+	_, err := s.GetByID("untouched")
+	assert.NoError(t, err, "merge leaves other projects alone")
+
+	events, _ := auditLog.Query(audit.Query{})
+	assert.Len(t, events, 2)
+}
+
+func TestImport_ReplaceYAML(t *testing.T) {
+	s := store.NewStore()
+	s.Create(&models.Project{ID: "keep", Name: "Keep", Description: "D", URL: "https://keep.io"})
+	s.Create(&models.Project{ID: "gone", Name: "Gone", Description: "D", URL: "https://gone.io"})
+	router := setupTransferRouter(s, nil)
+
+	body := "- id: keep\n  name: Keep\n  description: D\n  url: https://keep.io\n" +
+		"- id: added\n  name: Added\n  description: D\n  url: https://added.io\n"
+	w, report := postImport(router, "?mode=replace", "application/yaml", body)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, map[string]string{"keep": "skipped", "added": "created", "gone": "deleted"}, itemActions(report))
+
+	_, err := s.GetByID("gone")
+	assert.ErrorIs(t, err, store.ErrNotFound)
+	trash, _ := s.Trash()
+	require.Len(t, trash, 1, "replace moves missing projects to the trash")
+	assert.Equal(t, "gone", trash[0].ID)
+}
+
+func TestImport_DryRun(t *testing.T) {
+	s := store.NewStore()
+	s.Create(&models.Project{ID: "gone", Name: "Gone", Description: "D", URL: "https://gone.io"})
+	router := setupTransferRouter(s, nil)
+	body := `[{"id":"added","name":"Added","description":"D","url":"https://added.io"}]`
+
+	w, report := postImport(router, "?mode=dry-run", "application/json", body)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.True(t, report.DryRun)
+	assert.False(t, report.Applied)
+	assert.Equal(t, map[string]string{"added": "created"}, itemActions(report))
+
+	w, report = postImport(router, "?mode=replace&dryRun=true", "application/json", body)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, map[string]string{"added": "created", "gone": "deleted"}, itemActions(report))
+
+	all, _ := s.GetAll()
+	require.Len(t, all, 1, "a dry run writes nothing")
+	assert.Equal(t, "gone", all[0].ID)
+}
+
+func TestImport_FailureAppliesNothing(t *testing.T) {
+	s := store.NewStore()
+	router := setupTransferRouter(s, nil)
+
+	w, report := postImport(router, "", "application/json", `[
+		{"id":"ok","name":"OK","description":"D","url":"https://ok.io"},
+		{"id":"bad","name":"","description":"D","url":"https://bad.io"},
+		{"id":"ok","name":"Twice","description":"D","url":"https://ok.io"},
+		null
+	]`)
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+	assert.False(t, report.Applied)
+	assert.Equal(t, map[string]int{"created": 1, "failed": 3}, report.Summary)
+	require.Len(t, report.Items, 4)
+	assert.Equal(t, "name", report.Items[1].Fields[0].Field)
+	assert.Equal(t, "duplicate project id", report.Items[2].Error)
+	require.NotNil(t, report.Items[3].Index)
+	assert.Equal(t, 3, *report.Items[3].Index)
+
+	all, _ := s.GetAll()
+	assert.Empty(t, all)
+}
+
+func TestImport_InvalidRequests(t *testing.T) {
+	router := setupTransferRouter(store.NewStore(), nil)
+
+	w, _ := postImport(router, "?mode=upsert", "application/json", `[]`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w, _ = postImport(router, "", "application/json", `{`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w, _ = postImport(router, "", "text/csv", `id,name`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestImport_ChecksOwnership(t *testing.T) {
+	s := store.NewStore()
+	s.Create(&models.Project{ID: "managed", Name: "Managed", Description: "D", URL: "https://managed.io", ManagedBy: models.ManagedByOperator})
+	keys, err := auth.NewAPIKeys("editor:editor=editor-token", "")
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/import", auth.Require(keys), NewProjectsHandler(s, nil).Import)
+
+	req, _ := http.NewRequest("POST", "/api/import", bytes.NewBufferString(
+		`[{"id":"managed","name":"Changed","description":"D","url":"https://managed.io"}]`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer editor-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "managed by the operator")
+	p, _ := s.GetByID("managed")
+	assert.Equal(t, "Managed", p.Name)
+
+	// Creating a project over a trashed one is checked against it
+	_, err = s.Delete("managed", 0)
+	require.NoError(t, err)
+	req, _ = http.NewRequest("POST", "/api/import", bytes.NewBufferString(
+		`[{"id":"managed","name":"Changed","description":"D","url":"https://managed.io"}]`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer editor-token")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "managed by the operator")
+	trash, _ := s.Trash()
+	require.Len(t, trash, 1)
+	assert.Equal(t, "Managed", trash[0].Name)
+}
+
+func TestImport_ConflictsWithConcurrentWrites(t *testing.T) {
+	inner := store.NewStore()
+	inner.Create(&models.Project{ID: "a", Name: "Kubernetes", Description: "K", URL: "https://kubernetes.io"})
+	inner.Create(&models.Project{ID: "b", Name: "Docker", Description: "D", URL: "https://docker.com"})
+	racing := &racingStore{Store: inner}
+	router := setupTransferRouter(racing, nil)
+	body := `[{"id":"a","name":"Imported","description":"K","url":"https://kubernetes.io"},
+		{"id":"c","name":"Helm","description":"H","url":"https://helm.sh"}]`
+
+	for name, race := range map[string]func(){
+		"updated": func() {
+			require.NoError(t, inner.Update(&models.Project{ID: "a", Name: "Changed", Description: "K", URL: "https://kubernetes.io"}))
+		},
+		"created": func() {
+			require.NoError(t, inner.Create(&models.Project{ID: "c", Name: "By hand", Description: "H", URL: "https://helm.sh"}))
+		},
+		"deleted": func() {
+			_, err := inner.Delete("b", 0)
+			require.NoError(t, err)
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			racing.afterRead = race
+			w, _ := postImport(router, "?mode=replace", "application/json", body)
+			require.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+
+			a, err := inner.GetByID("a")
+			require.NoError(t, err)
+			assert.NotEqual(t, "Imported", a.Name)
+			if c, err := inner.GetByID("c"); err == nil {
+				assert.Equal(t, "By hand", c.Name)
+			}
+		})
+	}
+}
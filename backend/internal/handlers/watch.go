@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"0xhub/backend/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// heartbeatInterval is how often an idle stream sends a comment, so proxies
+// keep the connection open and dead clients are noticed
+const heartbeatInterval = 15 * time.Second
+
+// resetEvent tells a client that changes were missed and it should reload
+// every project before applying further events
+const resetEvent = "reset"
+
+// streams ends the long-lived change feeds of a handler when the server
+// shuts down, since they would otherwise hold the shutdown until its
+// deadline
+type streams struct {
+	once     sync.Once
+	shutdown chan struct{}
+}
+
+// Shutdown ends every open stream, so clients reconnect and resume from
+// another server
+func (s *streams) Shutdown() {
+	s.once.Do(func() { close(s.shutdown) })
+}
+
+// WatchHandler streams project changes as Server-Sent Events
+type WatchHandler struct {
+	streams
+	watcher   store.Watcher
+	heartbeat time.Duration
+}
+
+// NewWatchHandler creates a new watch handler
+func NewWatchHandler(watcher store.Watcher) *WatchHandler {
+	return &WatchHandler{
+		streams:   streams{shutdown: make(chan struct{})},
+		watcher:   watcher,
+		heartbeat: heartbeatInterval,
+	}
+}
+
+// Watch streams created, updated and deleted events, each carrying the
+// project as JSON data and its resource version as the event ID. A
+// Last-Event-ID header, or lastEventId query parameter for clients that
+// cannot set headers, resumes after that event; when the events since then
+// are no longer available a reset event is sent first.
+//
+// The stream ends if the client falls too far behind or the server shuts
+// down; reconnecting with Last-Event-ID resumes it.
+func (h *WatchHandler) Watch(c *gin.Context) {
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("lastEventId")
+	}
+	var since int64
+	if lastEventID != "" {
+		n, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || n < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Last-Event-ID must be an event ID from this stream",
+			})
+			return
+		}
+		since = n
+	}
+
+	sub, err := h.watcher.Watch(since)
+	reset := errors.Is(err, store.ErrChangesExpired)
+	if reset {
+		sub, err = h.watcher.Watch(0)
+	}
+	if err != nil {
+		respondStoreError(c, err)
+		return
+	}
+	defer sub.Close()
+
+	// The server's WriteTimeout is meant for ordinary responses, not streams
+	http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// Stop nginx-style proxies from buffering the stream
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	if reset {
+		fmt.Fprintf(c.Writer, "event: %s\ndata: {}\n\n", resetEvent)
+	} else {
+		// Confirm the stream is open even if nothing changes for a while
+		fmt.Fprint(c.Writer, ": connected\n\n")
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-h.shutdown:
+			return
+		case change, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(change.Project)
+			if err != nil {
+				return
+			}
+			fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", change.ID, change.Type, data)
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": heartbeat\n\n")
+			c.Writer.Flush()
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"0xhub/backend/internal/models"
+	"0xhub/backend/internal/store"
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sseEvent is a parsed Server-Sent Event
+type sseEvent struct {
+	id    string
+	event string
+	data  string
+}
+
+func setupWatchServer(t *testing.T) (*store.WatchedStore, *httptest.Server) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	watched, err := store.NewWatchedStore(store.NewStore(), 0)
+	require.NoError(t, err)
+
+	handler := NewWatchHandler(watched)
+	handler.heartbeat = 50 * time.Millisecond
+	router := gin.New()
+	router.GET("/api/projects/watch", handler.Watch)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return watched, server
+}
+
+// openStream connects to the watch endpoint and returns a channel of its
+// events, skipping comments
+func openStream(t *testing.T, url, lastEventID string) <-chan sseEvent {
+	t.Helper()
+	req, _ := http.NewRequest("GET", url+"/api/projects/watch", nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	events := make(chan sseEvent, 16)
+	go func() {
+		defer close(events)
+		scanner := bufio.NewScanner(resp.Body)
+		var e sseEvent
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case line == "":
+				if e.event != "" {
+					events <- e
+				}
+				e = sseEvent{}
+			case strings.HasPrefix(line, "id: "):
+				e.id = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "event: "):
+				e.event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				e.data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}()
+	return events
+}
+
+func nextEvent(t *testing.T, events <-chan sseEvent) sseEvent {
+	t.Helper()
+	select {
+	case e, ok := <-events:
+		require.True(t, ok, "Stream ended")
+		return e
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for an event")
+		return sseEvent{}
+	}
+}
+
+// waitForWatchers waits until n streams have subscribed
+func waitForWatchers(t *testing.T, watched *store.WatchedStore, n int) {
+	t.Helper()
+	require.Eventually(t, func() bool { return watched.Watchers() == n }, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestWatch_StreamsChanges(t *testing.T) {
+	watched, server := setupWatchServer(t)
+	events := openStream(t, server.URL, "")
+	waitForWatchers(t, watched, 1)
+
+	p := &models.Project{ID: "p1", Name: "Project"}
+	require.NoError(t, watched.Create(p))
+	require.NoError(t, watched.Update(&models.Project{ID: "p1", Name: "Renamed"}))
+	_, err := watched.Delete("p1", 0)
+	require.NoError(t, err)
+
+	created := nextEvent(t, events)
+	assert.Equal(t, "created", created.event)
+	assert.Equal(t, strconv.FormatInt(p.ResourceVersion, 10), created.id)
+	var project models.Project
+	require.NoError(t, json.Unmarshal([]byte(created.data), &project))
+	assert.Equal(t, "Project", project.Name)
+
+	updated := nextEvent(t, events)
+	assert.Equal(t, "updated", updated.event)
+	assert.Contains(t, updated.data, "Renamed")
+
+	deleted := nextEvent(t, events)
+	assert.Equal(t, "deleted", deleted.event)
+	assert.Contains(t, deleted.data, `"deletedAt"`)
+}
+
+func TestWatch_ResumeFromLastEventID(t *testing.T) {
+	watched, server := setupWatchServer(t)
+	first := &models.Project{ID: "p1", Name: "First"}
+	require.NoError(t, watched.Create(first))
+	require.NoError(t, watched.Create(&models.Project{ID: "p2", Name: "Second"}))
+
+	events := openStream(t, server.URL, strconv.FormatInt(first.ResourceVersion, 10))
+	e := nextEvent(t, events)
+	assert.Equal(t, "created", e.event)
+	assert.Contains(t, e.data, "Second")
+}
+
+func TestWatch_ResetWhenChangesExpired(t *testing.T) {
+	watched, server := setupWatchServer(t)
+	events := openStream(t, server.URL, "1000")
+	assert.Equal(t, resetEvent, nextEvent(t, events).event)
+
+	// The stream then continues with new changes
+	waitForWatchers(t, watched, 1)
+	require.NoError(t, watched.Create(&models.Project{ID: "p1", Name: "Project"}))
+	assert.Equal(t, "created", nextEvent(t, events).event)
+}
+
+func TestWatch_InvalidLastEventID(t *testing.T) {
+	_, server := setupWatchServer(t)
+	req, _ := http.NewRequest("GET", server.URL+"/api/projects/watch", nil)
+	req.Header.Set("Last-Event-ID", "abc")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestWatch_ClientDisconnect(t *testing.T) {
+	watched, server := setupWatchServer(t)
+	req, _ := http.NewRequest("GET", server.URL+"/api/projects/watch", nil)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	waitForWatchers(t, watched, 1)
+
+	resp.Body.Close()
+	waitForWatchers(t, watched, 0)
+}
+
+func TestWatch_OutlivesWriteTimeoutAndEndsOnShutdown(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	watched, err := store.NewWatchedStore(store.NewStore(), 0)
+	require.NoError(t, err)
+	handler := NewWatchHandler(watched)
+	router := gin.New()
+	router.GET("/api/projects/watch", handler.Watch)
+
+	server := httptest.NewUnstartedServer(router)
+	server.Config.WriteTimeout = 50 * time.Millisecond
+	server.Start()
+	t.Cleanup(server.Close)
+
+	events := openStream(t, server.URL, "")
+	waitForWatchers(t, watched, 1)
+	time.Sleep(150 * time.Millisecond)
+	require.NoError(t, watched.Create(&models.Project{ID: "1", Name: "Kubernetes"}))
+	assert.Equal(t, store.ChangeCreated, nextEvent(t, events).event, "the stream outlives the write timeout")
+
+	handler.Shutdown()
+	select {
+	case _, ok := <-events:
+		assert.False(t, ok, "Expected the stream to end")
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for the stream to end")
+	}
+	waitForWatchers(t, watched, 0)
+}
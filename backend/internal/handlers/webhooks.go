@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"0xhub/backend/internal/webhooks"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebhooksHandler manages webhook subscriptions and their deliveries
+type WebhooksHandler struct {
+	registry   *webhooks.Registry
+	dispatcher *webhooks.Dispatcher
+}
+
+// NewWebhooksHandler creates a new webhooks handler
+func NewWebhooksHandler(registry *webhooks.Registry, dispatcher *webhooks.Dispatcher) *WebhooksHandler {
+	return &WebhooksHandler{
+		registry:   registry,
+		dispatcher: dispatcher,
+	}
+}
+
+// ListWebhooks returns every webhook, without secrets
+func (h *WebhooksHandler) ListWebhooks(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"webhooks": h.registry.List(),
+	})
+}
+
+// GetWebhook returns a single webhook, without its secret
+func (h *WebhooksHandler) GetWebhook(c *gin.Context) {
+	webhook, err := h.registry.Get(c.Param("id"))
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, webhook)
+}
+
+// CreateWebhook adds a webhook. The response is the only one that includes
+// the secret, which is generated when the request omits it.
+func (h *WebhooksHandler) CreateWebhook(c *gin.Context) {
+	var webhook webhooks.Webhook
+	if err := c.ShouldBindJSON(&webhook); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err := webhook.Validate(); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	if err := h.registry.Create(&webhook); err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, webhook)
+}
+
+// UpdateWebhook replaces a webhook's URL and events, and its secret when the
+// request includes one
+func (h *WebhooksHandler) UpdateWebhook(c *gin.Context) {
+	var webhook webhooks.Webhook
+	if err := c.ShouldBindJSON(&webhook); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err := webhook.Validate(); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	webhook.ID = c.Param("id")
+	if err := h.registry.Update(&webhook); err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, webhook)
+}
+
+// DeleteWebhook removes a webhook and its delivery log
+func (h *WebhooksHandler) DeleteWebhook(c *gin.Context) {
+	if err := h.registry.Delete(c.Param("id")); err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListDeliveries returns a webhook's recent deliveries, newest first
+func (h *WebhooksHandler) ListDeliveries(c *gin.Context) {
+	deliveries, err := h.registry.Deliveries(c.Param("id"))
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"deliveries": deliveries,
+	})
+}
+
+// GetDelivery returns a single delivery, including its attempts
+func (h *WebhooksHandler) GetDelivery(c *gin.Context) {
+	delivery, err := h.registry.Delivery(c.Param("id"), c.Param("delivery"))
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, delivery)
+}
+
+// Redeliver sends an earlier delivery's payload again as a new delivery,
+// responding with 503 Service Unavailable while the delivery queue is full
+func (h *WebhooksHandler) Redeliver(c *gin.Context) {
+	delivery, err := h.dispatcher.Redeliver(c.Param("id"), c.Param("delivery"))
+	if err != nil {
+		respondWebhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+// respondWebhookError maps a webhooks error to an HTTP error response
+func respondWebhookError(c *gin.Context, err error) {
+	if errors.Is(err, webhooks.ErrNotFound) || errors.Is(err, webhooks.ErrDeliveryNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, webhooks.ErrQueueFull) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": err.Error(),
+	})
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"0xhub/backend/internal/webhooks"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupWebhooksRouter(t *testing.T) (*gin.Engine, *webhooks.Registry) {
+	gin.SetMode(gin.TestMode)
+	registry, err := webhooks.NewRegistry("")
+	require.NoError(t, err)
+	handler := NewWebhooksHandler(registry, webhooks.NewDispatcher(registry, webhooks.Options{}))
+
+	router := gin.New()
+	api := router.Group("/api")
+	{
+		api.GET("/webhooks", handler.ListWebhooks)
+		api.POST("/webhooks", handler.CreateWebhook)
+		api.GET("/webhooks/:id", handler.GetWebhook)
+		api.PUT("/webhooks/:id", handler.UpdateWebhook)
+		api.DELETE("/webhooks/:id", handler.DeleteWebhook)
+		api.GET("/webhooks/:id/deliveries", handler.ListDeliveries)
+		api.GET("/webhooks/:id/deliveries/:delivery", handler.GetDelivery)
+		api.POST("/webhooks/:id/deliveries/:delivery/redeliver", handler.Redeliver)
+	}
+	return router, registry
+}
+
+func sendWebhookRequest(router *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestWebhooks_CRUD(t *testing.T) {
+	router, _ := setupWebhooksRouter(t)
+
+	w := sendWebhookRequest(router, "POST", "/api/webhooks",
+		`{"url":"https://example.com/hook","events":["project.created"],"secret":"s3cret"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created webhooks.Webhook
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.NotEmpty(t, created.ID)
+	assert.Equal(t, "s3cret", created.Secret, "the secret is returned on creation")
+
+	// The secret is never returned again
+	w = sendWebhookRequest(router, "GET", "/api/webhooks/"+created.ID, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "s3cret")
+
+	w = sendWebhookRequest(router, "PUT", "/api/webhooks/"+created.ID,
+		`{"url":"https://example.com/other","events":["project.deleted"]}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var updated webhooks.Webhook
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated))
+	assert.Equal(t, "https://example.com/other", updated.URL)
+	assert.Equal(t, []string{webhooks.EventProjectDeleted}, updated.Events)
+	assert.Empty(t, updated.Secret)
+
+	w = sendWebhookRequest(router, "GET", "/api/webhooks", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Webhooks []webhooks.Webhook `json:"webhooks"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Webhooks, 1)
+	assert.Equal(t, created.ID, list.Webhooks[0].ID)
+
+	w = sendWebhookRequest(router, "DELETE", "/api/webhooks/"+created.ID, "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = sendWebhookRequest(router, "GET", "/api/webhooks/"+created.ID, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = sendWebhookRequest(router, "DELETE", "/api/webhooks/"+created.ID, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestWebhooks_GeneratesSecret(t *testing.T) {
+	router, _ := setupWebhooksRouter(t)
+
+	w := sendWebhookRequest(router, "POST", "/api/webhooks", `{"url":"https://example.com/hook"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created webhooks.Webhook
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Len(t, created.Secret, 64)
+}
+
+func TestWebhooks_Validation(t *testing.T) {
+	router, _ := setupWebhooksRouter(t)
+
+	w := sendWebhookRequest(router, "POST", "/api/webhooks", `{"url":"not a url","events":["project.renamed"]}`)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	var response struct {
+		Fields []struct {
+			Field string `json:"field"`
+		} `json:"fields"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Fields, 2)
+	assert.Equal(t, "url", response.Fields[0].Field)
+	assert.Equal(t, "events", response.Fields[1].Field)
+
+	w = sendWebhookRequest(router, "POST", "/api/webhooks", `{`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = sendWebhookRequest(router, "PUT", "/api/webhooks/missing", `{"url":"https://example.com/hook"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestWebhooks_Deliveries(t *testing.T) {
+	router, registry := setupWebhooksRouter(t)
+	webhook := &webhooks.Webhook{URL: "https://example.com/hook"}
+	require.NoError(t, registry.Create(webhook))
+
+	w := sendWebhookRequest(router, "GET", "/api/webhooks/"+webhook.ID+"/deliveries", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"deliveries":[]}`, w.Body.String())
+
+	w = sendWebhookRequest(router, "GET", "/api/webhooks/missing/deliveries", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = sendWebhookRequest(router, "GET", "/api/webhooks/"+webhook.ID+"/deliveries/missing", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = sendWebhookRequest(router, "POST", "/api/webhooks/"+webhook.ID+"/deliveries/missing/redeliver", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"0xhub/backend/internal/models"
+	"0xhub/backend/internal/store"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// WebSocket message types
+const (
+	// Sent by clients
+	wsSubscribe   = "subscribe"
+	wsUnsubscribe = "unsubscribe"
+	// Sent by the server
+	wsSubscribed   = "subscribed"
+	wsUnsubscribed = "unsubscribed"
+	wsChange       = "change"
+	wsHeartbeat    = "heartbeat"
+	wsError        = "error"
+)
+
+const (
+	// wsWriteTimeout bounds how long a single message may take to send
+	wsWriteTimeout = 10 * time.Second
+	// wsMaxMessageSize bounds the size of a client message
+	wsMaxMessageSize = 64 * 1024
+	// wsMaxSubscriptions bounds the subscriptions of a single connection
+	wsMaxSubscriptions = 100
+)
+
+// SubscriptionFilter selects the changes a subscription receives. Each
+// non-empty list must contain the changed project's value, ignoring case for
+// categories and statuses; an empty filter matches every change.
+type SubscriptionFilter struct {
+	Categories []string `json:"categories,omitempty"`
+	Statuses   []string `json:"statuses,omitempty"`
+	IDs        []string `json:"ids,omitempty"`
+}
+
+// Matches reports whether the filter selects changes to project
+func (f *SubscriptionFilter) Matches(project *models.Project) bool {
+	return matchesAny(f.Categories, project.Category, strings.EqualFold) &&
+		matchesAny(f.Statuses, project.Status, strings.EqualFold) &&
+		matchesAny(f.IDs, project.ID, func(a, b string) bool { return a == b })
+}
+
+func matchesAny(values []string, value string, equal func(a, b string) bool) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if equal(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// wsMessage is the envelope of every message in either direction
+type wsMessage struct {
+	Type string `json:"type"`
+	// ID names the subscription a subscribe, unsubscribe or acknowledgement
+	// refers to
+	ID     string              `json:"id,omitempty"`
+	Filter *SubscriptionFilter `json:"filter,omitempty"`
+	// Subscriptions lists the subscriptions a change matched
+	Subscriptions []string `json:"subscriptions,omitempty"`
+	// Removed lists the subscriptions an updated project matched before the
+	// change but no longer does, so clients showing it should drop it
+	Removed []string      `json:"removed,omitempty"`
+	Change  *store.Change `json:"change,omitempty"`
+	Time    *time.Time    `json:"time,omitempty"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// WebSocketHandler serves project changes over WebSocket connections, with
+// filters chosen by each client
+type WebSocketHandler struct {
+	streams
+	watcher   store.Watcher
+	upgrader  websocket.Upgrader
+	heartbeat time.Duration
+}
+
+// NewWebSocketHandler creates a new WebSocket handler accepting browser
+// connections from allowedOrigins. Clients that send no Origin header, which
+// browsers always do, are accepted too.
+func NewWebSocketHandler(watcher store.Watcher, allowedOrigins []string) *WebSocketHandler {
+	return &WebSocketHandler{
+		streams:   streams{shutdown: make(chan struct{})},
+		watcher:   watcher,
+		heartbeat: 30 * time.Second,
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				if origin == "" {
+					return true
+				}
+				if u, err := url.Parse(origin); err == nil && u.Host == r.Host {
+					return true
+				}
+				for _, allowed := range allowedOrigins {
+					if allowed == "*" || strings.EqualFold(allowed, origin) {
+						return true
+					}
+				}
+				return false
+			},
+		},
+	}
+}
+
+// Serve upgrades the request to a WebSocket and streams changes.
+//
+// Clients send {"type":"subscribe","id":"...","filter":{...}} to add or
+// replace a subscription and {"type":"unsubscribe","id":"..."} to remove
+// one; both are acknowledged. Each change matching at least one
+// subscription is sent once as {"type":"change","subscriptions":[...],
+// "change":{...}}. An update that makes a project stop matching a
+// subscription lists it under "removed" instead of "subscriptions". A
+// heartbeat message is sent periodically. The connection is closed if the
+// client falls too far behind, and with 1001 Going Away when the server
+// shuts down.
+func (h *WebSocketHandler) Serve(c *gin.Context) {
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already responded
+		return
+	}
+	defer conn.Close()
+
+	sub, err := h.watcher.Watch(0)
+	if err != nil {
+		conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseInternalServerErr, err.Error()),
+			time.Now().Add(wsWriteTimeout))
+		return
+	}
+	defer sub.Close()
+
+	// A client that misses two heartbeats without answering is gone
+	readTimeout := 2 * h.heartbeat
+	conn.SetReadLimit(wsMaxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(readTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(readTimeout))
+	})
+
+	// Reads happen in their own goroutine; every write happens below, as a
+	// connection allows only one concurrent writer
+	incoming := make(chan wsMessage)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		defer close(incoming)
+		for {
+			var msg wsMessage
+			if err := conn.ReadJSON(&msg); err != nil {
+				if !isJSONError(err) {
+					return
+				}
+				msg = wsMessage{Type: wsError, Error: "malformed message: " + err.Error()}
+			}
+			conn.SetReadDeadline(time.Now().Add(readTimeout))
+			select {
+			case incoming <- msg:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	subscriptions := make(map[string]*SubscriptionFilter)
+	for {
+		select {
+		case msg, ok := <-incoming:
+			if !ok {
+				return
+			}
+			if err := h.write(conn, handleClientMessage(subscriptions, msg)); err != nil {
+				return
+			}
+		case change, ok := <-sub.C:
+			if !ok {
+				conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "client fell behind"),
+					time.Now().Add(wsWriteTimeout))
+				return
+			}
+			var matched, removed []string
+			for id, filter := range subscriptions {
+				switch {
+				case filter.Matches(change.Project):
+					matched = append(matched, id)
+				case change.Previous != nil && filter.Matches(change.Previous):
+					removed = append(removed, id)
+				}
+			}
+			if len(matched) == 0 && len(removed) == 0 {
+				continue
+			}
+			sort.Strings(matched)
+			sort.Strings(removed)
+			msg := wsMessage{Type: wsChange, Subscriptions: matched, Removed: removed, Change: &change}
+			if err := h.write(conn, msg); err != nil {
+				return
+			}
+		case <-h.shutdown:
+			conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"),
+				time.Now().Add(wsWriteTimeout))
+			return
+		case now := <-heartbeat.C:
+			now = now.UTC()
+			if err := h.write(conn, wsMessage{Type: wsHeartbeat, Time: &now}); err != nil {
+				return
+			}
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (h *WebSocketHandler) write(conn *websocket.Conn, msg wsMessage) error {
+	conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	return conn.WriteJSON(msg)
+}
+
+// handleClientMessage applies a client message to subscriptions and returns
+// the reply
+func handleClientMessage(subscriptions map[string]*SubscriptionFilter, msg wsMessage) wsMessage {
+	switch msg.Type {
+	case wsError:
+		return msg
+	case wsSubscribe:
+		if msg.ID == "" {
+			return wsMessage{Type: wsError, Error: "subscribe requires an id"}
+		}
+		if _, exists := subscriptions[msg.ID]; !exists && len(subscriptions) >= wsMaxSubscriptions {
+			return wsMessage{Type: wsError, ID: msg.ID, Error: "too many subscriptions"}
+		}
+		filter := msg.Filter
+		if filter == nil {
+			filter = &SubscriptionFilter{}
+		}
+		subscriptions[msg.ID] = filter
+		return wsMessage{Type: wsSubscribed, ID: msg.ID, Filter: filter}
+	case wsUnsubscribe:
+		if _, exists := subscriptions[msg.ID]; !exists {
+			return wsMessage{Type: wsError, ID: msg.ID, Error: "unknown subscription"}
+		}
+		delete(subscriptions, msg.ID)
+		return wsMessage{Type: wsUnsubscribed, ID: msg.ID}
+	default:
+		return wsMessage{Type: wsError, Error: fmt.Sprintf("unknown message type %q", msg.Type)}
+	}
+}
+
+// isJSONError reports whether a read failed only because the message was not
+// valid JSON, leaving the connection usable. A message cut short, such as
+// "{", fails with io.ErrUnexpectedEOF; a closed connection never does.
+func isJSONError(err error) bool {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	return errors.As(err, &syntaxErr) || errors.As(err, &typeErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
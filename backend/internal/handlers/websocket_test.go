@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"0xhub/backend/internal/models"
+	"0xhub/backend/internal/store"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupWebSocketServer(t *testing.T, heartbeat time.Duration) (*store.WatchedStore, string) {
+	t.Helper()
+	watched, _, url := setupWebSocketHandler(t, heartbeat)
+	return watched, url
+}
+
+func setupWebSocketHandler(t *testing.T, heartbeat time.Duration) (*store.WatchedStore, *WebSocketHandler, string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	watched, err := store.NewWatchedStore(store.NewStore(), 0)
+	require.NoError(t, err)
+
+	handler := NewWebSocketHandler(watched, []string{"http://allowed.example.com"})
+	handler.heartbeat = heartbeat
+	router := gin.New()
+	router.GET("/api/projects/ws", handler.Serve)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return watched, handler, "ws" + strings.TrimPrefix(server.URL, "http") + "/api/projects/ws"
+}
+
+func dialWebSocket(t *testing.T, url string) *websocket.Conn {
+	t.Helper()
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// readMessage reads the next message other than a heartbeat
+func readMessage(t *testing.T, conn *websocket.Conn) wsMessage {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		var msg wsMessage
+		require.NoError(t, conn.ReadJSON(&msg))
+		if msg.Type != wsHeartbeat {
+			return msg
+		}
+	}
+}
+
+func TestWebSocket_SubscriptionFilters(t *testing.T) {
+	watched, url := setupWebSocketServer(t, time.Minute)
+	conn := dialWebSocket(t, url)
+
+	require.NoError(t, conn.WriteJSON(wsMessage{Type: wsSubscribe, ID: "monitoring",
+		Filter: &SubscriptionFilter{Categories: []string{"monitoring"}}}))
+	ack := readMessage(t, conn)
+	assert.Equal(t, wsSubscribed, ack.Type)
+	assert.Equal(t, "monitoring", ack.ID)
+
+	require.NoError(t, conn.WriteJSON(wsMessage{Type: wsSubscribe, ID: "p3",
+		Filter: &SubscriptionFilter{IDs: []string{"p3"}, Statuses: []string{"active"}}}))
+	assert.Equal(t, wsSubscribed, readMessage(t, conn).Type)
+
+	require.NoError(t, watched.Create(&models.Project{ID: "p1", Name: "Docker", Category: "Containers"}))
+	require.NoError(t, watched.Create(&models.Project{ID: "p2", Name: "Grafana", Category: "Monitoring"}))
+	require.NoError(t, watched.Create(&models.Project{ID: "p3", Name: "Prometheus", Category: "Monitoring", Status: "active"}))
+
+	// p1 matches nothing, so the first change received is p2
+	msg := readMessage(t, conn)
+	assert.Equal(t, wsChange, msg.Type)
+	require.NotNil(t, msg.Change)
+	assert.Equal(t, store.ChangeCreated, msg.Change.Type)
+	assert.Equal(t, "p2", msg.Change.Project.ID)
+	assert.Equal(t, []string{"monitoring"}, msg.Subscriptions)
+
+	// A change matching several subscriptions is sent once
+	msg = readMessage(t, conn)
+	assert.Equal(t, "p3", msg.Change.Project.ID)
+	assert.Equal(t, []string{"monitoring", "p3"}, msg.Subscriptions)
+
+	require.NoError(t, conn.WriteJSON(wsMessage{Type: wsUnsubscribe, ID: "monitoring"}))
+	assert.Equal(t, wsUnsubscribed, readMessage(t, conn).Type)
+
+	_, err := watched.Delete("p2", 0)
+	require.NoError(t, err)
+	_, err = watched.Delete("p3", 0)
+	require.NoError(t, err)
+	msg = readMessage(t, conn)
+	assert.Equal(t, store.ChangeDeleted, msg.Change.Type)
+	assert.Equal(t, "p3", msg.Change.Project.ID)
+	assert.Equal(t, []string{"p3"}, msg.Subscriptions)
+}
+
+func TestWebSocket_ProjectsLeavingFilters(t *testing.T) {
+	watched, url := setupWebSocketServer(t, time.Minute)
+	conn := dialWebSocket(t, url)
+	require.NoError(t, watched.Create(&models.Project{ID: "p1", Name: "Prometheus", Category: "Monitoring", Status: "active"}))
+
+	require.NoError(t, conn.WriteJSON(wsMessage{Type: wsSubscribe, ID: "active",
+		Filter: &SubscriptionFilter{Statuses: []string{"active"}}}))
+	assert.Equal(t, wsSubscribed, readMessage(t, conn).Type)
+	require.NoError(t, conn.WriteJSON(wsMessage{Type: wsSubscribe, ID: "monitoring",
+		Filter: &SubscriptionFilter{Categories: []string{"monitoring"}}}))
+	assert.Equal(t, wsSubscribed, readMessage(t, conn).Type)
+
+	// Archiving the project takes it out of "active" but not "monitoring"
+	require.NoError(t, watched.Update(&models.Project{ID: "p1", Name: "Prometheus", Category: "Monitoring", Status: "archived"}))
+	msg := readMessage(t, conn)
+	assert.Equal(t, store.ChangeUpdated, msg.Change.Type)
+	assert.Equal(t, "archived", msg.Change.Project.Status)
+	assert.Equal(t, []string{"monitoring"}, msg.Subscriptions)
+	assert.Equal(t, []string{"active"}, msg.Removed)
+
+	// Moving it to another category takes it out of "monitoring" too, with
+	// Put as with Update
+	_, err := watched.Put(&models.Project{ID: "p1", Name: "Prometheus", Category: "Observability", Status: "archived"})
+	require.NoError(t, err)
+	msg = readMessage(t, conn)
+	assert.Empty(t, msg.Subscriptions)
+	assert.Equal(t, []string{"monitoring"}, msg.Removed)
+
+	// Changes that matched neither before nor after are not sent, and
+	// batches report projects leaving a filter as well
+	_, err = watched.Apply([]store.Write{
+		{Project: &models.Project{ID: "p1", Name: "Renamed", Category: "Observability", Status: "archived"}},
+		{Project: &models.Project{ID: "p2", Name: "Grafana", Category: "Monitoring", Status: "active"}},
+	})
+	require.NoError(t, err)
+	_, err = watched.Apply([]store.Write{
+		{Project: &models.Project{ID: "p2", Name: "Grafana", Category: "Dashboards", Status: "active"}},
+	})
+	require.NoError(t, err)
+	msg = readMessage(t, conn)
+	assert.Equal(t, store.ChangeCreated, msg.Change.Type)
+	assert.Equal(t, "p2", msg.Change.Project.ID)
+	assert.Equal(t, []string{"active", "monitoring"}, msg.Subscriptions)
+	assert.Empty(t, msg.Removed)
+	msg = readMessage(t, conn)
+	assert.Equal(t, store.ChangeUpdated, msg.Change.Type)
+	assert.Equal(t, []string{"active"}, msg.Subscriptions)
+	assert.Equal(t, []string{"monitoring"}, msg.Removed)
+}
+
+func TestWebSocket_InvalidMessages(t *testing.T) {
+	_, url := setupWebSocketServer(t, time.Minute)
+	conn := dialWebSocket(t, url)
+
+	for _, raw := range []string{
+		`not json`,
+		`{"type":"subscribe"`,
+		``,
+		`{"type":"subscribe"}`,
+		`{"type":"unsubscribe","id":"missing"}`,
+		`{"type":"bogus"}`,
+	} {
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(raw)))
+		msg := readMessage(t, conn)
+		assert.Equal(t, wsError, msg.Type, raw)
+		assert.NotEmpty(t, msg.Error, raw)
+	}
+
+	// The connection stays usable
+	require.NoError(t, conn.WriteJSON(wsMessage{Type: wsSubscribe, ID: "all"}))
+	assert.Equal(t, wsSubscribed, readMessage(t, conn).Type)
+}
+
+func TestWebSocket_Heartbeat(t *testing.T) {
+	_, url := setupWebSocketServer(t, 20*time.Millisecond)
+	conn := dialWebSocket(t, url)
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var msg wsMessage
+	require.NoError(t, conn.ReadJSON(&msg))
+	assert.Equal(t, wsHeartbeat, msg.Type)
+	require.NotNil(t, msg.Time)
+}
+
+func TestWebSocket_Shutdown(t *testing.T) {
+	watched, handler, url := setupWebSocketHandler(t, time.Minute)
+	conn := dialWebSocket(t, url)
+	waitForWatchers(t, watched, 1)
+
+	handler.Shutdown()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err := conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "unexpected error %v", err)
+	waitForWatchers(t, watched, 0)
+}
+
+func TestWebSocket_CheckOrigin(t *testing.T) {
+	_, url := setupWebSocketServer(t, time.Minute)
+
+	header := http.Header{"Origin": []string{"http://evil.example.com"}}
+	_, resp, err := websocket.DefaultDialer.Dial(url, header)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	header.Set("Origin", "http://allowed.example.com")
+	conn, _, err := websocket.DefaultDialer.Dial(url, header)
+	require.NoError(t, err)
+	conn.Close()
+}
+
+func TestSubscriptionFilter_Matches(t *testing.T) {
+	project := &models.Project{ID: "p1", Category: "Monitoring", Status: "active"}
+	tests := []struct {
+		name   string
+		filter SubscriptionFilter
+		want   bool
+	}{
+		{name: "empty filter", filter: SubscriptionFilter{}, want: true},
+		{name: "category ignores case", filter: SubscriptionFilter{Categories: []string{"DevOps", "monitoring"}}, want: true},
+		{name: "status mismatch", filter: SubscriptionFilter{Statuses: []string{"archived"}}, want: false},
+		{name: "ids are exact", filter: SubscriptionFilter{IDs: []string{"P1"}}, want: false},
+		{name: "all lists must match", filter: SubscriptionFilter{IDs: []string{"p1"}, Statuses: []string{"archived"}}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.Matches(project))
+		})
+	}
+}
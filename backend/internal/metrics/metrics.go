@@ -0,0 +1,145 @@
+// Package metrics exposes the backend's Prometheus metrics
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"0xhub/backend/internal/store"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes every metric name. Names cannot start with a digit, so
+// the project's name is not used.
+const namespace = "hub"
+
+// unmatchedRoute labels requests that matched no route, so unknown paths do
+// not create new series
+const unmatchedRoute = "unmatched"
+
+// Metrics holds the backend's collectors in a registry of its own
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests         *prometheus.CounterVec
+	requestDuration  *prometheus.HistogramVec
+	requestsInFlight *prometheus.GaugeVec
+	storeDuration    *prometheus.HistogramVec
+}
+
+// New creates the HTTP and store metrics along with the Go runtime and
+// process collectors
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests handled, by method, route and status code.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time taken to handle HTTP requests, by method, route and status code. Change feeds are observed when they end.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		requestsInFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "http_requests_in_flight",
+			Help:      "HTTP requests being handled, including open SSE and WebSocket change feeds, by route.",
+		}, []string{"route"}),
+		storeDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "store_operation_duration_seconds",
+			Help:      "Time taken by the storage backend, by operation.",
+			Buckets:   []float64{.0001, .00025, .0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"operation"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.requestsInFlight,
+		m.storeDuration,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func (m *Metrics) Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+}
+
+// Middleware counts and times every request by its route pattern, such as
+// /api/projects/:id, rather than its path
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		inFlight := m.requestsInFlight.WithLabelValues(route)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		start := time.Now()
+		c.Next()
+
+		status := strconv.Itoa(c.Writer.Status())
+		m.requests.WithLabelValues(c.Request.Method, route, status).Inc()
+		m.requestDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// RegisterProjects reports the live projects in s by status and category,
+// counted when the metrics are scraped
+func (m *Metrics) RegisterProjects(s store.Store) {
+	m.registry.MustRegister(&projectsCollector{store: s})
+}
+
+// RegisterSubscribers reports the open change feed subscriptions of s, which
+// include the webhook dispatcher's as well as every SSE and WebSocket client
+func (m *Metrics) RegisterSubscribers(s *store.WatchedStore) {
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "watch_subscribers",
+		Help:      "Open subscriptions to the project change feed.",
+	}, func() float64 { return float64(s.Watchers()) }))
+}
+
+var projectsDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "projects"),
+	"Live projects, by status and category. Projects without one have an empty label.",
+	[]string{"status", "category"}, nil,
+)
+
+// projectsCollector counts the projects in a store on every scrape, so the
+// counts cannot drift from the store
+type projectsCollector struct {
+	store store.Store
+}
+
+func (c *projectsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- projectsDesc
+}
+
+func (c *projectsCollector) Collect(ch chan<- prometheus.Metric) {
+	projects, err := c.store.GetAll()
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(projectsDesc, err)
+		return
+	}
+	type key struct{ status, category string }
+	counts := make(map[key]int)
+	for _, p := range projects {
+		counts[key{p.Status, p.Category}]++
+	}
+	for k, n := range counts {
+		ch <- prometheus.MustNewConstMetric(projectsDesc, prometheus.GaugeValue, float64(n), k.status, k.category)
+	}
+}
@@ -0,0 +1,109 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"0xhub/backend/internal/models"
+	"0xhub/backend/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// scrape returns the metrics m serves
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	router := gin.New()
+	router.GET("/metrics", m.Handler())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 from /metrics, got %d", w.Code)
+	}
+	body, _ := io.ReadAll(w.Body)
+	return string(body)
+}
+
+func expectLines(t *testing.T, scraped string, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		if !strings.Contains(scraped, line+"\n") {
+			t.Errorf("Expected %q in the metrics:\n%s", line, scraped)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := New()
+	router := gin.New()
+	router.Use(m.Middleware())
+	router.GET("/api/projects/:id", func(c *gin.Context) {
+		if c.Param("id") == "missing" {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	for _, path := range []string{"/api/projects/a", "/api/projects/b", "/api/projects/missing", "/unknown/path"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	scraped := scrape(t, m)
+	expectLines(t, scraped,
+		`hub_http_requests_total{method="GET",route="/api/projects/:id",status="200"} 2`,
+		`hub_http_requests_total{method="GET",route="/api/projects/:id",status="404"} 1`,
+		`hub_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`hub_http_request_duration_seconds_count{method="GET",route="/api/projects/:id",status="200"} 2`,
+		`hub_http_requests_in_flight{route="/api/projects/:id"} 0`,
+	)
+	if strings.Contains(scraped, "/api/projects/a") {
+		t.Errorf("Expected requests to be labeled by route, not path:\n%s", scraped)
+	}
+}
+
+func TestStoreMetrics(t *testing.T) {
+	m := New()
+	s := m.InstrumentStore(store.NewStore())
+	watched, err := store.NewWatchedStore(s, store.DefaultChangeHistory)
+	if err != nil {
+		t.Fatalf("NewWatchedStore() failed: %v", err)
+	}
+	m.RegisterProjects(watched)
+	m.RegisterSubscribers(watched)
+
+	for _, p := range []*models.Project{
+		{ID: "a", Name: "A", URL: "https://a.example.com", Status: "active", Category: "tools"},
+		{ID: "b", Name: "B", URL: "https://b.example.com", Status: "active", Category: "tools"},
+		{ID: "c", Name: "C", URL: "https://c.example.com", Status: "archived"},
+		{ID: "d", Name: "D", URL: "https://d.example.com", Status: "active", Category: "tools"},
+	} {
+		if err := watched.Create(p); err != nil {
+			t.Fatalf("Create(%s) failed: %v", p.ID, err)
+		}
+	}
+	if _, err := watched.Delete("d", 0); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	}
+	if _, err := watched.GetByID("missing"); err == nil {
+		t.Fatal("Expected GetByID of a missing project to fail")
+	}
+	sub, err := watched.Watch(0)
+	if err != nil {
+		t.Fatalf("Watch() failed: %v", err)
+	}
+	defer sub.Close()
+
+	expectLines(t, scrape(t, m),
+		`hub_projects{category="tools",status="active"} 2`,
+		`hub_projects{category="",status="archived"} 1`,
+		`hub_store_operation_duration_seconds_count{operation="create"} 4`,
+		`hub_store_operation_duration_seconds_count{operation="delete"} 1`,
+		`hub_store_operation_duration_seconds_count{operation="get"} 1`,
+		`hub_watch_subscribers 1`,
+	)
+}
@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"time"
+
+	"0xhub/backend/internal/closer"
+	"0xhub/backend/internal/models"
+	"0xhub/backend/internal/store"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Store wraps a Store and times every operation. Wrap the storage backend
+// itself, so the in-memory decorators above it are not counted.
+type Store struct {
+	store.Store
+	duration *prometheus.HistogramVec
+}
+
+// InstrumentStore returns inner timed into the store operation histogram
+func (m *Metrics) InstrumentStore(inner store.Store) *Store {
+	return &Store{Store: inner, duration: m.storeDuration}
+}
+
+// time starts timing operation and returns the func that records it
+func (s *Store) time(operation string) func() {
+	start := time.Now()
+	return func() {
+		s.duration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	}
+}
+
+// GetAll returns all projects, timed as get_all
+func (s *Store) GetAll() ([]*models.Project, error) {
+	defer s.time("get_all")()
+	return s.Store.GetAll()
+}
+
+// List returns a filtered, sorted page of projects, timed as list
+func (s *Store) List(opts store.ListOptions) (*store.ListResult, error) {
+	defer s.time("list")()
+	return s.Store.List(opts)
+}
+
+// GetByID returns a project by ID, timed as get
+func (s *Store) GetByID(id string) (*models.Project, error) {
+	defer s.time("get")()
+	return s.Store.GetByID(id)
+}
+
+// Create creates a new project, timed as create
+func (s *Store) Create(project *models.Project) error {
+	defer s.time("create")()
+	return s.Store.Create(project)
+}
+
+// Put creates or replaces a project, timed as put
+func (s *Store) Put(project *models.Project) (bool, error) {
+	defer s.time("put")()
+	return s.Store.Put(project)
+}
+
+// Update updates an existing project, timed as update
+func (s *Store) Update(project *models.Project) error {
+	defer s.time("update")()
+	return s.Store.Update(project)
+}
+
+// Delete moves a project to the trash, timed as delete
+func (s *Store) Delete(id string, version int64) (*models.Project, error) {
+	defer s.time("delete")()
+	return s.Store.Delete(id, version)
+}
+
+// Trash returns the projects in the trash, timed as trash
+func (s *Store) Trash() ([]*models.Project, error) {
+	defer s.time("trash")()
+	return s.Store.Trash()
+}
+
+// Restore moves a project out of the trash, timed as restore
+func (s *Store) Restore(id string, version int64) (*models.Project, error) {
+	defer s.time("restore")()
+	return s.Store.Restore(id, version)
+}
+
+// Purge permanently deletes a project from the trash, timed as purge
+func (s *Store) Purge(id string, version int64) error {
+	defer s.time("purge")()
+	return s.Store.Purge(id, version)
+}
+
+// Apply performs writes atomically, timed as apply
+func (s *Store) Apply(writes []store.Write) ([]store.Change, error) {
+	defer s.time("apply")()
+	return s.Store.Apply(writes)
+}
+
+// Close closes the timed store
+func (s *Store) Close() error {
+	return closer.Close(s.Store)
+}
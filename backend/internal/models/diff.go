@@ -0,0 +1,55 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+// FieldChange is a field that differs between two versions of a project,
+// named by its JSON key. Before or After is omitted when the field is unset
+// in that version.
+type FieldChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// Diff returns the fields that differ between before and after, sorted by
+// field name. Either may be nil, for a project that did not exist.
+func Diff(before, after *Project) []FieldChange {
+	a, b := fieldValues(before), fieldValues(after)
+
+	fields := make([]string, 0, len(a)+len(b))
+	for field := range a {
+		fields = append(fields, field)
+	}
+	for field := range b {
+		if _, ok := a[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	changes := make([]FieldChange, 0)
+	for _, field := range fields {
+		if !reflect.DeepEqual(a[field], b[field]) {
+			changes = append(changes, FieldChange{Field: field, Before: a[field], After: b[field]})
+		}
+	}
+	return changes
+}
+
+// fieldValues returns the project's JSON fields, leaving out unset ones
+func fieldValues(p *Project) map[string]interface{} {
+	values := make(map[string]interface{})
+	if p == nil {
+		return values
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		return values
+	}
+	json.Unmarshal(data, &values)
+	return values
+}
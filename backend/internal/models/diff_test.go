@@ -0,0 +1,46 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	before := validProject()
+	after := before
+	after.Name = "Renamed"
+	after.Icon = ""
+	after.ResourceVersion = 7
+
+	want := []FieldChange{
+		{Field: "icon", Before: "https://example.com/favicon.ico"},
+		{Field: "name", Before: "My Project", After: "Renamed"},
+		{Field: "resourceVersion", After: float64(7)},
+	}
+	if got := Diff(&before, &after); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Expected %+v, got %+v", want, got)
+	}
+
+	if got := Diff(&before, &before); len(got) != 0 {
+		t.Fatalf("Expected no changes, got %+v", got)
+	}
+}
+
+func TestDiff_CreateAndDelete(t *testing.T) {
+	p := validProject()
+
+	created := Diff(nil, &p)
+	if len(created) != 7 {
+		t.Fatalf("Expected every set field to be added, got %+v", created)
+	}
+	for _, change := range created {
+		if change.Before != nil || change.After == nil {
+			t.Fatalf("Expected only additions, got %+v", change)
+		}
+	}
+
+	deleted := Diff(&p, nil)
+	if len(deleted) != 7 || deleted[0].Field != "category" || deleted[0].Before != "Tools" || deleted[0].After != nil {
+		t.Fatalf("Expected every set field to be removed, got %+v", deleted)
+	}
+}
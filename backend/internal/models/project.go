@@ -1,5 +1,11 @@
 package models
 
+import "time"
+
+// ManagedByOperator marks projects created by the operator from Project
+// resources
+const ManagedByOperator = "operator"
+
 // Project represents a project in the hub
 type Project struct {
 	ID          string `json:"id"`
@@ -9,4 +15,35 @@ type Project struct {
 	Icon        string `json:"icon,omitempty"`
 	Category    string `json:"category,omitempty"`
 	Status      string `json:"status,omitempty"`
+
+	// ResourceVersion is assigned by the store on every write and increases
+	// monotonically across all projects. It is served as the ETag.
+	ResourceVersion int64 `json:"resourceVersion,omitempty"`
+
+	// CreatedAt and UpdatedAt are set by the store when the project is first
+	// written and on every write. They are zero for projects stored before
+	// timestamps were recorded.
+	CreatedAt time.Time `json:"createdAt,omitzero"`
+	UpdatedAt time.Time `json:"updatedAt,omitzero"`
+	// UpdatedBy identifies the caller that made the last write
+	UpdatedBy string `json:"updatedBy,omitempty"`
+	// ManagedBy is ManagedByOperator for projects owned by the operator, and
+	// empty for projects managed through the API. It is fixed when the
+	// project is created.
+	ManagedBy string `json:"managedBy,omitempty"`
+	// DeletedAt is set by the store when the project is moved to the trash,
+	// and is zero for every live project
+	DeletedAt time.Time `json:"deletedAt,omitzero"`
+}
+
+// SameContent reports whether p and other have the same client-managed
+// fields, ignoring those the store manages
+func (p *Project) SameContent(other *Project) bool {
+	return p.ID == other.ID &&
+		p.Name == other.Name &&
+		p.Description == other.Description &&
+		p.URL == other.URL &&
+		p.Icon == other.Icon &&
+		p.Category == other.Category &&
+		p.Status == other.Status
 }
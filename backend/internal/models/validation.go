@@ -0,0 +1,105 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits mirror the Project CRD schema in operator/api/v1/project_types.go
+const (
+	MaxIDLength          = 253
+	MaxNameLength        = 100
+	MaxDescriptionLength = 1000
+	MaxCategoryLength    = 50
+)
+
+// ValidStatuses are the accepted values of Project.Status
+var ValidStatuses = []string{"active", "inactive", "archived", "maintenance"}
+
+// idPattern matches a Kubernetes resource name (DNS subdomain), which is what
+// the operator uses as the project ID
+var idPattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9.]*[a-z0-9])?$`)
+
+// FieldError describes why a single field is invalid
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors is the set of field errors found on a payload
+type ValidationErrors []FieldError
+
+// Error joins the field errors into a single message
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, fe := range e {
+		msgs = append(msgs, fe.Field+" "+fe.Message)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Validate checks the project against the same rules the CRD enforces. It
+// returns ValidationErrors, or nil if the project is valid.
+func (p *Project) Validate() error {
+	var errs ValidationErrors
+	add := func(field, format string, args ...interface{}) {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch {
+	case p.ID == "":
+		add("id", "is required")
+	case len(p.ID) > MaxIDLength:
+		add("id", "must be at most %d characters", MaxIDLength)
+	case !idPattern.MatchString(p.ID):
+		add("id", "must consist of lowercase alphanumeric characters, '-' or '.', and start and end with an alphanumeric character")
+	}
+
+	checkLength := func(field, value string, required bool, max int) {
+		n := utf8.RuneCountInString(value)
+		switch {
+		case n == 0 && required:
+			add(field, "is required")
+		case n > max:
+			add(field, "must be at most %d characters", max)
+		}
+	}
+	checkLength("name", p.Name, true, MaxNameLength)
+	checkLength("description", p.Description, true, MaxDescriptionLength)
+	checkLength("category", p.Category, false, MaxCategoryLength)
+
+	if p.URL == "" {
+		add("url", "is required")
+	} else if !isAbsoluteURI(p.URL) {
+		add("url", "must be an absolute URI")
+	}
+	if p.Icon != "" && !isAbsoluteURI(p.Icon) {
+		add("icon", "must be an absolute URI")
+	}
+
+	if p.Status != "" && !isValidStatus(p.Status) {
+		add("status", "must be one of %s", strings.Join(ValidStatuses, ", "))
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func isAbsoluteURI(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.IsAbs() && (u.Host != "" || u.Opaque != "")
+}
+
+func isValidStatus(status string) bool {
+	for _, s := range ValidStatuses {
+		if status == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func validProject() Project {
+	return Project{
+		ID:          "my-project.v2",
+		Name:        "My Project",
+		Description: "A valid project",
+		URL:         "https://example.com",
+		Icon:        "https://example.com/favicon.ico",
+		Category:    "Tools",
+		Status:      "active",
+	}
+}
+
+func TestProject_Validate_Valid(t *testing.T) {
+	p := validProject()
+	if err := p.Validate(); err != nil {
+		t.Fatalf("Expected valid project, got %v", err)
+	}
+
+	// Optional fields may be empty
+	p.Icon, p.Category, p.Status = "", "", ""
+	if err := p.Validate(); err != nil {
+		t.Fatalf("Expected valid project without optional fields, got %v", err)
+	}
+}
+
+func TestProject_Validate_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(p *Project)
+		field  string
+	}{
+		{"missing id", func(p *Project) { p.ID = "" }, "id"},
+		{"uppercase id", func(p *Project) { p.ID = "My-Project" }, "id"},
+		{"id with slash", func(p *Project) { p.ID = "a/b" }, "id"},
+		{"id ending in dash", func(p *Project) { p.ID = "project-" }, "id"},
+		{"id too long", func(p *Project) { p.ID = strings.Repeat("a", MaxIDLength+1) }, "id"},
+		{"missing name", func(p *Project) { p.Name = "" }, "name"},
+		{"name too long", func(p *Project) { p.Name = strings.Repeat("n", MaxNameLength+1) }, "name"},
+		{"missing description", func(p *Project) { p.Description = "" }, "description"},
+		{"description too long", func(p *Project) { p.Description = strings.Repeat("d", MaxDescriptionLength+1) }, "description"},
+		{"category too long", func(p *Project) { p.Category = strings.Repeat("c", MaxCategoryLength+1) }, "category"},
+		{"missing url", func(p *Project) { p.URL = "" }, "url"},
+		{"relative url", func(p *Project) { p.URL = "/projects/foo" }, "url"},
+		{"url without host", func(p *Project) { p.URL = "https://" }, "url"},
+		{"relative icon", func(p *Project) { p.Icon = "favicon.ico" }, "icon"},
+		{"unknown status", func(p *Project) { p.Status = "deleted" }, "status"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := validProject()
+			tt.mutate(&p)
+
+			err := p.Validate()
+			var fields ValidationErrors
+			if !errors.As(err, &fields) {
+				t.Fatalf("Expected ValidationErrors, got %v", err)
+			}
+			if len(fields) != 1 || fields[0].Field != tt.field {
+				t.Fatalf("Expected a single error on %s, got %v", tt.field, fields)
+			}
+		})
+	}
+}
+
+func TestProject_Validate_ReportsEveryField(t *testing.T) {
+	p := Project{}
+	err := p.Validate()
+
+	var fields ValidationErrors
+	if !errors.As(err, &fields) {
+		t.Fatalf("Expected ValidationErrors, got %v", err)
+	}
+	if len(fields) != 4 {
+		t.Fatalf("Expected errors for id, name, description and url, got %v", fields)
+	}
+	if !strings.Contains(err.Error(), "id is required") {
+		t.Fatalf("Expected summary to mention the id, got %q", err.Error())
+	}
+}
+
+func TestProject_Validate_CountsCharactersNotBytes(t *testing.T) {
+	p := validProject()
+	p.Name = strings.Repeat("é", MaxNameLength)
+	if err := p.Validate(); err != nil {
+		t.Fatalf("Expected %d multi-byte characters to be valid, got %v", MaxNameLength, err)
+	}
+}
@@ -0,0 +1,153 @@
+package seed
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"0xhub/backend/internal/models"
+	"0xhub/backend/internal/store"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Seed modes
+const (
+	// ModeIfEmpty seeds only a store with no projects, live or in the trash
+	ModeIfEmpty = "if-empty"
+	// ModeReconcile creates or updates every seeded project on each start,
+	// leaving projects missing from the file alone
+	ModeReconcile = "reconcile"
+)
+
+// UpdatedBy is recorded as the author of seeded writes
+const UpdatedBy = "seed"
+
+// file is the format of a seed file: an export of the projects API, or a
+// bare list of projects
+type file struct {
+	Projects []*models.Project `json:"projects"`
+}
+
+// Result summarizes a seeding run
+type Result struct {
+	// Skipped reports that ModeIfEmpty found projects and seeded nothing
+	Skipped   bool
+	Created   int
+	Updated   int
+	Unchanged int
+}
+
+// Load reads and validates the projects in the seed file at path. Every
+// project must pass the API's validation and IDs must be unique.
+func Load(path string) ([]*models.Project, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read seed file: %w", err)
+	}
+	projects, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid seed file %s: %w", path, err)
+	}
+	return projects, nil
+}
+
+// Parse decodes and validates a YAML or JSON seed document
+func Parse(data []byte) ([]*models.Project, error) {
+	data, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var projects []*models.Project
+	if data = bytes.TrimSpace(data); len(data) > 0 && data[0] == '[' {
+		err = json.Unmarshal(data, &projects)
+	} else {
+		var f file
+		err = json.Unmarshal(data, &f)
+		projects = f.Projects
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(projects))
+	for i, p := range projects {
+		if p == nil {
+			return nil, fmt.Errorf("project %d: must be an object", i)
+		}
+		if err := p.Validate(); err != nil {
+			return nil, fmt.Errorf("project %d (%s): %w", i, p.ID, err)
+		}
+		if seen[p.ID] {
+			return nil, fmt.Errorf("project %d: duplicate id %q", i, p.ID)
+		}
+		seen[p.ID] = true
+	}
+	return projects, nil
+}
+
+// Apply writes projects to s according to mode, as one atomic batch.
+// Projects already stored with the same content are left untouched, so
+// reconciling an unchanged file writes nothing.
+func Apply(s store.Store, projects []*models.Project, mode string) (*Result, error) {
+	if mode != ModeIfEmpty && mode != ModeReconcile {
+		return nil, fmt.Errorf("unknown seed mode %q, use %s or %s", mode, ModeIfEmpty, ModeReconcile)
+	}
+
+	current, err := s.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{}
+	if mode == ModeIfEmpty {
+		trash, err := s.Trash()
+		if err != nil {
+			return nil, err
+		}
+		if len(current)+len(trash) > 0 {
+			result.Skipped = true
+			return result, nil
+		}
+	}
+	existing := make(map[string]*models.Project, len(current))
+	for _, p := range current {
+		existing[p.ID] = p
+	}
+
+	var writes []store.Write
+	for _, p := range projects {
+		if previous := existing[p.ID]; previous != nil && previous.SameContent(p) {
+			result.Unchanged++
+			continue
+		}
+		project := &models.Project{
+			ID:          p.ID,
+			Name:        p.Name,
+			Description: p.Description,
+			URL:         p.URL,
+			Icon:        p.Icon,
+			Category:    p.Category,
+			Status:      p.Status,
+			UpdatedBy:   UpdatedBy,
+		}
+		writes = append(writes, store.Write{Project: project})
+	}
+	if len(writes) == 0 {
+		return result, nil
+	}
+
+	changes, err := s.Apply(writes)
+	if err != nil {
+		return nil, err
+	}
+	for _, change := range changes {
+		if change.Type == store.ChangeCreated {
+			result.Created++
+		} else {
+			result.Updated++
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,132 @@
+package seed
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"0xhub/backend/internal/models"
+	"0xhub/backend/internal/store"
+)
+
+const testSeed = `
+projects:
+  - id: k8s
+    name: Kubernetes
+    description: Container orchestration
+    url: https://kubernetes.io
+    status: active
+  - id: helm
+    name: Helm
+    description: Package manager
+    url: https://helm.sh
+`
+
+func TestParse(t *testing.T) {
+	projects, err := Parse([]byte(testSeed))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(projects) != 2 || projects[0].ID != "k8s" || projects[1].URL != "https://helm.sh" {
+		t.Fatalf("Unexpected projects %+v", projects)
+	}
+
+	// JSON, and a bare list, are accepted too
+	projects, err = Parse([]byte(`[{"id":"a","name":"A","description":"D","url":"https://a.io"}]`))
+	if err != nil || len(projects) != 1 {
+		t.Fatalf("Expected a bare JSON list to parse, got %v, %v", projects, err)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"invalid project", `[{"id":"a","name":"","description":"D","url":"https://a.io"}]`, "name"},
+		{"duplicate id", `[{"id":"a","name":"A","description":"D","url":"https://a.io"},{"id":"a","name":"B","description":"D","url":"https://b.io"}]`, "duplicate"},
+		{"null project", `[null]`, "must be an object"},
+		{"malformed", "projects: [", "yaml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Expected an error mentioning %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestLoad_Example(t *testing.T) {
+	projects, err := Load(filepath.Join("..", "..", "examples", "seed-projects.yaml"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(projects) != 6 {
+		t.Fatalf("Expected the 6 sample projects, got %d", len(projects))
+	}
+}
+
+func TestApply_IfEmpty(t *testing.T) {
+	projects, _ := Parse([]byte(testSeed))
+	s := store.NewStore()
+
+	result, err := Apply(s, projects, ModeIfEmpty)
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if result.Skipped || result.Created != 2 {
+		t.Fatalf("Expected 2 created projects, got %+v", result)
+	}
+	p, _ := s.GetByID("k8s")
+	if p.UpdatedBy != UpdatedBy {
+		t.Fatalf("Expected seeded projects to be written by %q, got %q", UpdatedBy, p.UpdatedBy)
+	}
+
+	// A store with only trashed projects is not empty either
+	s.Delete("k8s", 0)
+	s.Delete("helm", 0)
+	result, err = Apply(s, projects, ModeIfEmpty)
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if !result.Skipped {
+		t.Fatalf("Expected seeding to be skipped, got %+v", result)
+	}
+	if all, _ := s.GetAll(); len(all) != 0 {
+		t.Fatalf("Expected trashed projects to stay trashed, got %d live", len(all))
+	}
+}
+
+func TestApply_Reconcile(t *testing.T) {
+	projects, _ := Parse([]byte(testSeed))
+	s := store.NewStore()
+	s.Create(&models.Project{ID: "k8s", Name: "Kubernetes", Description: "Container orchestration", URL: "https://kubernetes.io", Status: "active"})
+	s.Create(&models.Project{ID: "helm", Name: "Old", Description: "D", URL: "https://helm.sh"})
+	s.Create(&models.Project{ID: "extra", Name: "Extra", Description: "D", URL: "https://extra.io"})
+	unchanged, _ := s.GetByID("k8s")
+	version := unchanged.ResourceVersion
+
+	result, err := Apply(s, projects, ModeReconcile)
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if result.Created != 0 || result.Updated != 1 || result.Unchanged != 1 {
+		t.Fatalf("Expected 1 updated and 1 unchanged project, got %+v", result)
+	}
+	if p, _ := s.GetByID("k8s"); p.ResourceVersion != version {
+		t.Fatalf("Expected the unchanged project not to be rewritten")
+	}
+	if p, _ := s.GetByID("helm"); p.Name != "Helm" {
+		t.Fatalf("Expected the project to be reconciled, got %+v", p)
+	}
+	if _, err := s.GetByID("extra"); err != nil {
+		t.Fatalf("Expected projects missing from the seed to be kept, got %v", err)
+	}
+
+	if _, err := Apply(s, projects, "always"); err == nil {
+		t.Fatal("Expected an unknown mode to fail")
+	}
+}
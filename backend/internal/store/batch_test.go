@@ -0,0 +1,166 @@
+package store
+
+import (
+	"errors"
+	"testing"
+
+	"0xhub/backend/internal/models"
+)
+
+func TestStore_Apply(t *testing.T) {
+	for storeName, s := range allStores(t) {
+		t.Run(storeName, func(t *testing.T) {
+			existing := &models.Project{ID: "1", Name: "Kubernetes"}
+			s.Create(existing)
+			s.Create(&models.Project{ID: "2", Name: "Docker"})
+
+			changes, err := s.Apply([]Write{
+				{Project: &models.Project{ID: "1", Name: "Kubernetes", Status: "maintenance"}},
+				{Project: &models.Project{ID: "3", Name: "Helm"}},
+				{Project: &models.Project{ID: "2"}, Delete: true},
+			})
+			if err != nil {
+				t.Fatalf("Apply failed: %v", err)
+			}
+			if got := changeTypes(changes); !equalStrings(got, []string{"updated:1", "created:3", "deleted:2"}) {
+				t.Fatalf("Unexpected changes %v", got)
+			}
+			for i, change := range changes {
+				if change.ID != change.Project.ResourceVersion || (i > 0 && change.ID <= changes[i-1].ID) {
+					t.Fatalf("Expected increasing versions matching the projects, got %+v", changes)
+				}
+			}
+
+			updated, _ := s.GetByID("1")
+			if updated.Status != "maintenance" || !updated.CreatedAt.Equal(existing.CreatedAt) {
+				t.Fatalf("Expected the update to keep CreatedAt, got %+v", updated)
+			}
+			if _, err := s.GetByID("3"); err != nil {
+				t.Fatalf("Expected the created project, got %v", err)
+			}
+			if trash, _ := s.Trash(); len(trash) != 1 || trash[0].ID != "2" {
+				t.Fatalf("Expected the deleted project in the trash, got %+v", trash)
+			}
+		})
+	}
+}
+
+func TestStore_ApplyIsAtomic(t *testing.T) {
+	for storeName, s := range allStores(t) {
+		t.Run(storeName, func(t *testing.T) {
+			s.Create(&models.Project{ID: "1", Name: "Kubernetes"})
+			before, _ := s.GetByID("1")
+			version := before.ResourceVersion
+
+			_, err := s.Apply([]Write{
+				{Project: &models.Project{ID: "1", Name: "Renamed"}},
+				{Project: &models.Project{ID: "2", Name: "Docker"}},
+				{Project: &models.Project{ID: "missing"}, Delete: true},
+			})
+			if !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Expected ErrNotFound, got %v", err)
+			}
+
+			after, _ := s.GetByID("1")
+			if after.Name != "Kubernetes" || after.ResourceVersion != version {
+				t.Fatalf("Expected the failed batch to change nothing, got %+v", after)
+			}
+			if _, err := s.GetByID("2"); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Expected no project created by the failed batch, got %v", err)
+			}
+		})
+	}
+}
+
+func TestStore_ApplyConditions(t *testing.T) {
+	for storeName, s := range allStores(t) {
+		t.Run(storeName, func(t *testing.T) {
+			s.Create(&models.Project{ID: "1", Name: "Kubernetes"})
+			current, _ := s.GetByID("1")
+			version := current.ResourceVersion
+
+			for _, tt := range []struct {
+				write Write
+				want  error
+			}{
+				{Write{Project: &models.Project{ID: "1", Name: "Renamed"}, Version: version + 1}, ErrVersionConflict},
+				{Write{Project: &models.Project{ID: "1"}, Delete: true, Version: version + 1}, ErrVersionConflict},
+				{Write{Project: &models.Project{ID: "1", Name: "Renamed"}, Create: true}, ErrAlreadyExists},
+				{Write{Project: &models.Project{ID: "2", Name: "Docker"}, Version: version}, ErrNotFound},
+			} {
+				_, err := s.Apply([]Write{{Project: &models.Project{ID: "3", Name: "Helm"}}, tt.write})
+				if !errors.Is(err, tt.want) {
+					t.Fatalf("Expected %v for %+v, got %v", tt.want, tt.write, err)
+				}
+			}
+			if _, err := s.GetByID("3"); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Expected failed batches to write nothing, got %v", err)
+			}
+
+			changes, err := s.Apply([]Write{
+				{Project: &models.Project{ID: "1", Name: "Renamed"}, Version: version},
+				{Project: &models.Project{ID: "2", Name: "Docker"}, Create: true},
+			})
+			if err != nil {
+				t.Fatalf("Expected conditions that hold to pass, got %v", err)
+			}
+			if got := changeTypes(changes); !equalStrings(got, []string{"updated:1", "created:2"}) {
+				t.Fatalf("Unexpected changes %v", got)
+			}
+		})
+	}
+}
+
+func TestFileStore_ApplySurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewFileStore(dir, 0)
+	if err != nil {
+		t.Fatalf("NewFileStore() failed: %v", err)
+	}
+	s.Create(&models.Project{ID: "1", Name: "Kubernetes"})
+	if _, err := s.Apply([]Write{
+		{Project: &models.Project{ID: "2", Name: "Docker"}},
+		{Project: &models.Project{ID: "1"}, Delete: true},
+	}); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	// Reopen without closing, so the batch is replayed from the journal
+	reopened, err := NewFileStore(dir, 0)
+	if err != nil {
+		t.Fatalf("NewFileStore() failed on reopen: %v", err)
+	}
+	defer reopened.Close()
+
+	if _, err := reopened.GetByID("2"); err != nil {
+		t.Fatalf("Expected the created project after replay, got %v", err)
+	}
+	if trash, _ := reopened.Trash(); len(trash) != 1 || trash[0].ID != "1" {
+		t.Fatalf("Expected the deleted project in the trash after replay, got %+v", trash)
+	}
+}
+
+func TestDecorators_Apply(t *testing.T) {
+	history, _ := NewHistoryStore(NewStore(), nil, 0)
+	watched, _ := NewWatchedStore(history, 0)
+	indexed, _ := NewIndexedStore(watched)
+	indexed.Create(&models.Project{ID: "1", Name: "Kubernetes"})
+	sub, _ := watched.Watch(0)
+	defer sub.Close()
+
+	if _, err := indexed.Apply([]Write{
+		{Project: &models.Project{ID: "1", Name: "Kubernetes", Description: "Orchestration"}},
+		{Project: &models.Project{ID: "2", Name: "Docker"}},
+	}); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	if got := changeTypes(drain(sub)); !equalStrings(got, []string{"updated:1", "created:2"}) {
+		t.Fatalf("Expected each change to be published, got %v", got)
+	}
+	if revisions, _ := history.Revisions("1"); len(revisions) != 2 {
+		t.Fatalf("Expected the update to be recorded as a revision, got %d", len(revisions))
+	}
+	if results, _ := indexed.Search("docker", 0); len(results) != 1 {
+		t.Fatalf("Expected the created project to be indexed, got %d results", len(results))
+	}
+}
@@ -0,0 +1,294 @@
+package store
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"0xhub/backend/internal/closer"
+	"0xhub/backend/internal/models"
+)
+
+// DefaultHistorySize is the number of revisions kept per project by default
+const DefaultHistorySize = 20
+
+// Revision is a project as it was written at one resource version
+type Revision struct {
+	// Revision is the project's resource version at this revision
+	Revision int64          `json:"revision"`
+	Project  models.Project `json:"project"`
+	// Changes lists the fields that differ from the previous revision, other
+	// than resourceVersion and updatedAt. It is empty for a project that was
+	// stored before its history began.
+	Changes []models.FieldChange `json:"changes"`
+}
+
+// History looks up earlier revisions of projects
+type History interface {
+	// Revisions returns the retained revisions of a project, newest first,
+	// or ErrNotFound when none are known
+	Revisions(id string) ([]Revision, error)
+	// Revision returns a single revision of a project, or ErrNotFound
+	Revision(id string, revision int64) (*Revision, error)
+}
+
+// RevisionLog persists the revisions a HistoryStore records, so history
+// survives a restart. FileStore and SQLiteStore implement it.
+type RevisionLog interface {
+	// LoadRevisions returns the persisted revisions of every project by
+	// project ID, oldest first
+	LoadRevisions() (map[string][]Revision, error)
+	// AppendRevision persists revision as the newest of its project's,
+	// keeping only the newest keep of them
+	AppendRevision(revision Revision, keep int) error
+	// DropRevisions deletes every persisted revision of a project
+	DropRevisions(id string) error
+}
+
+// HistoryStore wraps a Store and keeps the most recent revisions of every
+// project, in memory and in a RevisionLog when one is given. Writes that
+// leave a project's content unchanged are not revisions. History of a
+// project is kept while it is in the trash and ends when it is purged or
+// replaced by a new project with its ID.
+type HistoryStore struct {
+	Store
+	persisted RevisionLog
+	// mu serializes writers so revisions are recorded in store order
+	mu        sync.RWMutex
+	size      int
+	revisions map[string][]Revision // project ID -> revisions, oldest first
+}
+
+// NewHistoryStore loads the revisions persisted in revisionLog, which may be
+// nil to keep history in memory only. A stored project without history, as
+// every project has on the first start, gets its current contents as the
+// first revision. It keeps size revisions per project, or DefaultHistorySize
+// when size is zero or less.
+func NewHistoryStore(inner Store, revisionLog RevisionLog, size int) (*HistoryStore, error) {
+	if size <= 0 {
+		size = DefaultHistorySize
+	}
+	s := &HistoryStore{Store: inner, persisted: revisionLog, size: size, revisions: make(map[string][]Revision)}
+	if revisionLog != nil {
+		revisions, err := revisionLog.LoadRevisions()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load revisions: %w", err)
+		}
+		for id, r := range revisions {
+			if len(r) > size {
+				r = r[len(r)-size:]
+			}
+			s.revisions[id] = r
+		}
+	}
+
+	projects, err := inner.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	trash, err := inner.Trash()
+	if err != nil {
+		return nil, err
+	}
+	stored := make(map[string]bool, len(projects)+len(trash))
+	for _, p := range append(projects, trash...) {
+		stored[p.ID] = true
+		if _, ok := s.revisions[p.ID]; ok {
+			// Catch up on a write whose revision was not persisted
+			s.recordLocked(p)
+		} else {
+			s.appendLocked(Revision{Revision: p.ResourceVersion, Project: *p})
+		}
+	}
+	// Drop the history of projects purged without it being dropped too
+	for id := range s.revisions {
+		if !stored[id] {
+			s.dropLocked(id)
+		}
+	}
+	return s, nil
+}
+
+// Create creates a new project and records its first revision
+func (s *HistoryStore) Create(project *models.Project) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.Store.Create(project); err != nil {
+		return err
+	}
+	// A new project may replace a trashed one with the same ID
+	s.dropLocked(project.ID)
+	s.recordLocked(project)
+	return nil
+}
+
+// Put creates or replaces a project and records the revision
+func (s *HistoryStore) Put(project *models.Project) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	created, err := s.Store.Put(project)
+	if err != nil {
+		return false, err
+	}
+	if created {
+		s.dropLocked(project.ID)
+	}
+	s.recordLocked(project)
+	return created, nil
+}
+
+// Update updates an existing project and records the revision
+func (s *HistoryStore) Update(project *models.Project) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.Store.Update(project); err != nil {
+		return err
+	}
+	s.recordLocked(project)
+	return nil
+}
+
+// Restore moves a project out of the trash and records the revision
+func (s *HistoryStore) Restore(id string, version int64) (*models.Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project, err := s.Store.Restore(id, version)
+	if err != nil {
+		return nil, err
+	}
+	s.recordLocked(project)
+	return project, nil
+}
+
+// Apply performs writes atomically and records the revisions they make
+func (s *HistoryStore) Apply(writes []Write) ([]Change, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changes, err := s.Store.Apply(writes)
+	if err != nil {
+		return nil, err
+	}
+	for _, change := range changes {
+		switch change.Type {
+		case ChangeCreated:
+			s.dropLocked(change.Project.ID)
+			s.recordLocked(change.Project)
+		case ChangeUpdated:
+			s.recordLocked(change.Project)
+		}
+	}
+	return changes, nil
+}
+
+// Purge permanently deletes a project from the trash and drops its history
+func (s *HistoryStore) Purge(id string, version int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.Store.Purge(id, version); err != nil {
+		return err
+	}
+	s.dropLocked(id)
+	return nil
+}
+
+// recordLocked adds project as its newest revision unless its content is
+// that of the newest one already
+func (s *HistoryStore) recordLocked(project *models.Project) {
+	var previous *models.Project
+	if revisions := s.revisions[project.ID]; len(revisions) > 0 {
+		previous = &revisions[len(revisions)-1].Project
+		if previous.SameContent(project) {
+			return
+		}
+	}
+	s.appendLocked(Revision{
+		Revision: project.ResourceVersion,
+		Project:  *project,
+		Changes:  revisionChanges(previous, project),
+	})
+}
+
+// appendLocked adds a revision and persists it. The write it records has
+// already been stored, so failing to persist the revision is logged rather
+// than returned; the next start records the project again if it was lost.
+func (s *HistoryStore) appendLocked(revision Revision) {
+	id := revision.Project.ID
+	if s.persisted != nil {
+		if err := s.persisted.AppendRevision(revision, s.size); err != nil {
+			log.Printf("Failed to persist revision %d of project %s: %v", revision.Revision, id, err)
+		}
+	}
+	revisions := s.revisions[id]
+	if len(revisions) >= s.size {
+		revisions = append(revisions[:0], revisions[len(revisions)-s.size+1:]...)
+	}
+	s.revisions[id] = append(revisions, revision)
+}
+
+// dropLocked forgets the history of a project
+func (s *HistoryStore) dropLocked(id string) {
+	if _, ok := s.revisions[id]; !ok {
+		return
+	}
+	delete(s.revisions, id)
+	if s.persisted != nil {
+		if err := s.persisted.DropRevisions(id); err != nil {
+			log.Printf("Failed to drop the revisions of project %s: %v", id, err)
+		}
+	}
+}
+
+// revisionChanges returns the fields that differ between two revisions,
+// leaving out those every write changes
+func revisionChanges(previous, project *models.Project) []models.FieldChange {
+	changes := models.Diff(previous, project)
+	kept := changes[:0]
+	for _, change := range changes {
+		if change.Field != "resourceVersion" && change.Field != "updatedAt" {
+			kept = append(kept, change)
+		}
+	}
+	return kept
+}
+
+// Revisions returns the retained revisions of a project, newest first
+func (s *HistoryStore) Revisions(id string) ([]Revision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	revisions, ok := s.revisions[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	result := make([]Revision, len(revisions))
+	copy(result, revisions)
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Revision > result[j].Revision
+	})
+	return result, nil
+}
+
+// Revision returns a single revision of a project
+func (s *HistoryStore) Revision(id string, revision int64) (*Revision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, r := range s.revisions[id] {
+		if r.Revision == revision {
+			return &r, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// Close closes the store whose history is kept
+func (s *HistoryStore) Close() error {
+	return closer.Close(s.Store)
+}
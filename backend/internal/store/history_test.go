@@ -0,0 +1,243 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"0xhub/backend/internal/models"
+)
+
+func TestHistoryStore_Revisions(t *testing.T) {
+	s, err := NewHistoryStore(NewStore(), nil, 0)
+	if err != nil {
+		t.Fatalf("NewHistoryStore() failed: %v", err)
+	}
+
+	p := &models.Project{ID: "1", Name: "Kubernetes", Status: "active"}
+	if err := s.Create(p); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	created := p.ResourceVersion
+	if err := s.Update(&models.Project{ID: "1", Name: "Kubernetes", Status: "archived"}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	// A failed write records nothing
+	if err := s.Update(&models.Project{ID: "1", ResourceVersion: created}); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("Expected ErrVersionConflict, got %v", err)
+	}
+
+	revisions, err := s.Revisions("1")
+	if err != nil {
+		t.Fatalf("Revisions failed: %v", err)
+	}
+	if len(revisions) != 2 {
+		t.Fatalf("Expected 2 revisions, got %d", len(revisions))
+	}
+	if revisions[0].Project.Status != "archived" || revisions[1].Revision != created {
+		t.Fatalf("Expected newest revision first, got %+v", revisions)
+	}
+
+	fields := make(map[string]bool)
+	for _, change := range revisions[0].Changes {
+		fields[change.Field] = true
+	}
+	if !fields["status"] || fields["name"] {
+		t.Fatalf("Expected status but not name to change, got %+v", revisions[0].Changes)
+	}
+	if fields["resourceVersion"] || fields["updatedAt"] {
+		t.Fatalf("Expected no changes to fields every write sets, got %+v", revisions[0].Changes)
+	}
+
+	r, err := s.Revision("1", created)
+	if err != nil {
+		t.Fatalf("Revision failed: %v", err)
+	}
+	if r.Project.Status != "active" {
+		t.Fatalf("Expected the created revision, got %+v", r.Project)
+	}
+	if _, err := s.Revision("1", created+100); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound for an unknown revision, got %v", err)
+	}
+}
+
+func TestHistoryStore_Bounded(t *testing.T) {
+	s, err := NewHistoryStore(NewStore(), nil, 3)
+	if err != nil {
+		t.Fatalf("NewHistoryStore() failed: %v", err)
+	}
+	var versions []int64
+	for i := 0; i < 5; i++ {
+		p := &models.Project{ID: "1", Name: fmt.Sprintf("Kubernetes %d", i)}
+		if _, err := s.Put(p); err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+		versions = append(versions, p.ResourceVersion)
+	}
+
+	revisions, err := s.Revisions("1")
+	if err != nil {
+		t.Fatalf("Revisions failed: %v", err)
+	}
+	if len(revisions) != 3 {
+		t.Fatalf("Expected 3 revisions, got %d", len(revisions))
+	}
+	for i, r := range revisions {
+		if want := versions[4-i]; r.Revision != want {
+			t.Fatalf("Expected revision %d at %d, got %d", want, i, r.Revision)
+		}
+	}
+}
+
+func TestHistoryStore_PurgeDropsHistory(t *testing.T) {
+	inner := NewStore()
+	inner.Create(&models.Project{ID: "1", Name: "Kubernetes"})
+	s, err := NewHistoryStore(inner, nil, 0)
+	if err != nil {
+		t.Fatalf("NewHistoryStore() failed: %v", err)
+	}
+
+	revisions, err := s.Revisions("1")
+	if err != nil || len(revisions) != 1 || len(revisions[0].Changes) != 0 {
+		t.Fatalf("Expected the stored project as a revision without changes, got %+v (%v)", revisions, err)
+	}
+
+	if _, err := s.Delete("1", 0); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := s.Restore("1", 0); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	// The restored project has the content of the revision it was trashed at
+	if revisions, err := s.Revisions("1"); err != nil || len(revisions) != 1 {
+		t.Fatalf("Expected history to survive the trash, got %+v (%v)", revisions, err)
+	}
+
+	if _, err := s.Delete("1", 0); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err := s.Purge("1", 0); err != nil {
+		t.Fatalf("Purge failed: %v", err)
+	}
+	if _, err := s.Revisions("1"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound after purge, got %v", err)
+	}
+}
+
+func TestHistoryStore_SkipsUnchangedContent(t *testing.T) {
+	s, err := NewHistoryStore(NewStore(), nil, 0)
+	if err != nil {
+		t.Fatalf("NewHistoryStore() failed: %v", err)
+	}
+	p := &models.Project{ID: "1", Name: "Kubernetes"}
+	if err := s.Create(p); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	created := p.ResourceVersion
+	for i := 0; i < 3; i++ {
+		if _, err := s.Put(&models.Project{ID: "1", Name: "Kubernetes"}); err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+	}
+
+	revisions, err := s.Revisions("1")
+	if err != nil {
+		t.Fatalf("Revisions failed: %v", err)
+	}
+	if len(revisions) != 1 || revisions[0].Revision != created {
+		t.Fatalf("Expected only the created revision, got %+v", revisions)
+	}
+}
+
+// persistentStore is a backend that keeps revision history on disk
+type persistentStore interface {
+	Store
+	RevisionLog
+	Close() error
+}
+
+func TestHistoryStore_Persisted(t *testing.T) {
+	backends := map[string]func(t *testing.T, dir string) persistentStore{
+		"file": func(t *testing.T, dir string) persistentStore {
+			s, err := NewFileStore(dir, 0)
+			if err != nil {
+				t.Fatalf("NewFileStore() failed: %v", err)
+			}
+			return s
+		},
+		"sqlite": func(t *testing.T, dir string) persistentStore {
+			s, err := NewSQLiteStore(filepath.Join(dir, "projects.db"))
+			if err != nil {
+				t.Fatalf("NewSQLiteStore() failed: %v", err)
+			}
+			return s
+		},
+	}
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			inner := open(t, dir)
+			s, err := NewHistoryStore(inner, inner, 3)
+			if err != nil {
+				t.Fatalf("NewHistoryStore() failed: %v", err)
+			}
+			for i := 0; i < 5; i++ {
+				if _, err := s.Put(&models.Project{ID: "1", Name: fmt.Sprintf("Kubernetes %d", i)}); err != nil {
+					t.Fatalf("Put failed: %v", err)
+				}
+			}
+			s.Create(&models.Project{ID: "2", Name: "Prometheus"})
+			s.Delete("2", 0)
+			s.Purge("2", 0)
+			want, _ := s.Revisions("1")
+			if err := s.Close(); err != nil {
+				t.Fatalf("Close failed: %v", err)
+			}
+
+			inner = open(t, dir)
+			defer inner.Close()
+			reopened, err := NewHistoryStore(inner, inner, 3)
+			if err != nil {
+				t.Fatalf("NewHistoryStore() failed: %v", err)
+			}
+			got, err := reopened.Revisions("1")
+			if err != nil {
+				t.Fatalf("Revisions failed: %v", err)
+			}
+			if len(got) != 3 {
+				t.Fatalf("Expected 3 revisions after reopening, got %d", len(got))
+			}
+			for i := range want {
+				if got[i].Revision != want[i].Revision || got[i].Project.Name != want[i].Project.Name || len(got[i].Changes) != len(want[i].Changes) {
+					t.Fatalf("Expected revision %+v at %d, got %+v", want[i], i, got[i])
+				}
+			}
+			if _, err := reopened.Revisions("2"); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Expected no history for the purged project, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDecorators_CloseFileStore(t *testing.T) {
+	dir := t.TempDir()
+	inner, err := NewFileStore(dir, 0)
+	if err != nil {
+		t.Fatalf("NewFileStore() failed: %v", err)
+	}
+	history, _ := NewHistoryStore(inner, nil, 0)
+	watched, _ := NewWatchedStore(history, 0)
+	indexed, _ := NewIndexedStore(watched)
+	indexed.Create(&models.Project{ID: "1", Name: "Kubernetes"})
+
+	if err := indexed.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	// Closing the file store compacts the journal into the snapshot
+	info, err := os.Stat(filepath.Join(dir, journalFile))
+	if err != nil || info.Size() != 0 {
+		t.Fatalf("Expected an empty journal after Close, got %v (%v)", info, err)
+	}
+}
@@ -0,0 +1,498 @@
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"0xhub/backend/internal/models"
+)
+
+const (
+	snapshotFile = "snapshot.json"
+	journalFile  = "journal.log"
+
+	// DefaultCompactThreshold is the number of journal entries after which the
+	// journal is folded into a fresh snapshot
+	DefaultCompactThreshold = 1000
+)
+
+// journal operations
+const (
+	opCreate = "create"
+	opPut    = "put"
+	opUpdate = "update"
+	// opTrash and opRestore carry the project moved into or out of the trash
+	opTrash   = "trash"
+	opRestore = "restore"
+	// opDelete permanently deletes a project, whether live or trashed
+	opDelete = "delete"
+	// opBatch carries every project written by an Apply, live or trashed, in
+	// one entry so a crash cannot leave part of the batch applied
+	opBatch = "batch"
+	// opRevision and opDropRevisions record the revision history kept by a
+	// HistoryStore
+	opRevision      = "revision"
+	opDropRevisions = "dropRevisions"
+)
+
+// journalEntry is a single line of the write-ahead log
+type journalEntry struct {
+	Op      string          `json:"op"`
+	ID      string          `json:"id,omitempty"`
+	Project *models.Project `json:"project,omitempty"`
+	// Projects are the projects written by a batch
+	Projects []*models.Project `json:"projects,omitempty"`
+	// Version is the resource version consumed by a delete
+	Version int64 `json:"version,omitempty"`
+	// Revision is the revision appended and Keep the number of revisions of
+	// its project retained after it
+	Revision *Revision `json:"revision,omitempty"`
+	Keep     int       `json:"keep,omitempty"`
+}
+
+// snapshot is the on-disk form of the compacted store
+type snapshot struct {
+	// ResourceVersion is the last version handed out, which may be newer
+	// than every remaining project if the latest writes were deletes
+	ResourceVersion int64             `json:"resourceVersion"`
+	Projects        []*models.Project `json:"projects"`
+	Trash           []*models.Project `json:"trash,omitempty"`
+	// Revisions is the revision history by project ID, oldest first
+	Revisions map[string][]Revision `json:"revisions,omitempty"`
+}
+
+// FileStore is an in-memory store that persists every mutation to a
+// write-ahead journal on disk and periodically compacts it into a JSON snapshot
+type FileStore struct {
+	*MemoryStore
+
+	// mu serializes writers so journal order matches the order mutations
+	// are applied in memory
+	mu  sync.Mutex
+	dir string
+	// journal is nil after a failed write until a compaction replaces it
+	journal          *os.File
+	entries          int
+	compactThreshold int
+	// revisions is the history persisted for a HistoryStore, by project ID
+	// and oldest first
+	revisions map[string][]Revision
+}
+
+// NewFileStore opens the snapshot and journal in dir, replays them into
+// memory and starts a fresh journal. A compactThreshold of zero or less
+// uses DefaultCompactThreshold.
+func NewFileStore(dir string, compactThreshold int) (*FileStore, error) {
+	if compactThreshold <= 0 {
+		compactThreshold = DefaultCompactThreshold
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	s := &FileStore{
+		MemoryStore:      NewStore(),
+		dir:              dir,
+		compactThreshold: compactThreshold,
+		revisions:        make(map[string][]Revision),
+	}
+	if err := s.loadSnapshot(); err != nil {
+		return nil, err
+	}
+	if err := s.replayJournal(); err != nil {
+		return nil, err
+	}
+
+	// Fold whatever was replayed into a new snapshot so startup cost stays
+	// bounded and a torn final journal line is discarded
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.compactLocked(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Create creates a new project
+func (s *FileStore) Create(project *models.Project) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.MemoryStore.GetByID(project.ID); err == nil {
+		return ErrAlreadyExists
+	}
+	touch(project, nil)
+	project.ResourceVersion = s.MemoryStore.nextVersion()
+	return s.commitLocked(journalEntry{Op: opCreate, Project: project}, func() {
+		s.MemoryStore.load(project)
+	})
+}
+
+// Put creates or replaces a project
+func (s *FileStore) Put(project *models.Project) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, err := s.MemoryStore.GetByID(project.ID)
+	created := errors.Is(err, ErrNotFound)
+	touch(project, existing)
+	project.ResourceVersion = s.MemoryStore.nextVersion()
+	if err := s.commitLocked(journalEntry{Op: opPut, Project: project}, func() {
+		s.MemoryStore.load(project)
+	}); err != nil {
+		return false, err
+	}
+	return created, nil
+}
+
+// Update updates an existing project
+func (s *FileStore) Update(project *models.Project) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.MemoryStore.check(project.ID, project.ResourceVersion); err != nil {
+		return err
+	}
+	existing, _ := s.MemoryStore.GetByID(project.ID)
+	touch(project, existing)
+	project.ResourceVersion = s.MemoryStore.nextVersion()
+	return s.commitLocked(journalEntry{Op: opUpdate, Project: project}, func() {
+		s.MemoryStore.load(project)
+	})
+}
+
+// Delete moves a project to the trash
+func (s *FileStore) Delete(id string, version int64) (*models.Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.MemoryStore.check(id, version); err != nil {
+		return nil, err
+	}
+	existing, _ := s.MemoryStore.GetByID(id)
+	project := trashed(existing, s.MemoryStore.nextVersion())
+	if err := s.commitLocked(journalEntry{Op: opTrash, Project: project}, func() {
+		s.MemoryStore.load(project)
+	}); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// Restore moves a project out of the trash
+func (s *FileStore) Restore(id string, version int64) (*models.Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, err := s.MemoryStore.getTrashed(id, version)
+	if err != nil {
+		return nil, err
+	}
+	project := restored(existing, s.MemoryStore.nextVersion())
+	if err := s.commitLocked(journalEntry{Op: opRestore, Project: project}, func() {
+		s.MemoryStore.load(project)
+	}); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// Purge permanently deletes a project from the trash
+func (s *FileStore) Purge(id string, version int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.MemoryStore.getTrashed(id, version); err != nil {
+		return err
+	}
+	next := s.MemoryStore.nextVersion()
+	return s.commitLocked(journalEntry{Op: opDelete, ID: id, Version: next}, func() {
+		s.MemoryStore.remove(id, next)
+	})
+}
+
+// Apply performs writes atomically
+func (s *FileStore) Apply(writes []Write) ([]Change, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changes, err := planWrites(writes, s.MemoryStore.nextVersion(), func(id string) *models.Project {
+		p, _ := s.MemoryStore.GetByID(id)
+		return p
+	})
+	if err != nil {
+		return nil, err
+	}
+	projects := make([]*models.Project, len(changes))
+	for i, change := range changes {
+		projects[i] = change.Project
+	}
+	if err := s.commitLocked(journalEntry{Op: opBatch, Projects: projects}, func() {
+		for _, p := range projects {
+			s.MemoryStore.load(p)
+		}
+	}); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// LoadRevisions returns the persisted revision history
+func (s *FileStore) LoadRevisions() (map[string][]Revision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make(map[string][]Revision, len(s.revisions))
+	for id, revisions := range s.revisions {
+		result[id] = append([]Revision(nil), revisions...)
+	}
+	return result, nil
+}
+
+// AppendRevision persists a revision, keeping the newest keep of its project
+func (s *FileStore) AppendRevision(revision Revision, keep int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.commitLocked(journalEntry{Op: opRevision, Revision: &revision, Keep: keep}, func() {
+		s.appendRevision(revision, keep)
+	})
+}
+
+// DropRevisions deletes the persisted history of a project
+func (s *FileStore) DropRevisions(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.revisions[id]; !ok {
+		return nil
+	}
+	return s.commitLocked(journalEntry{Op: opDropRevisions, ID: id}, func() {
+		delete(s.revisions, id)
+	})
+}
+
+// appendRevision adds a revision in memory and trims its project's history.
+// A revision no newer than the last one is already known, from a journal
+// replayed over a snapshot that includes it.
+func (s *FileStore) appendRevision(revision Revision, keep int) {
+	id := revision.Project.ID
+	revisions := s.revisions[id]
+	if n := len(revisions); n > 0 && revisions[n-1].Revision >= revision.Revision {
+		return
+	}
+	revisions = append(revisions, revision)
+	if keep > 0 && len(revisions) > keep {
+		revisions = append([]Revision(nil), revisions[len(revisions)-keep:]...)
+	}
+	s.revisions[id] = revisions
+}
+
+// Compact writes the current state to a new snapshot and truncates the journal
+func (s *FileStore) Compact() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.compactLocked()
+}
+
+// Close compacts the journal and releases the file handle
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.compactLocked()
+	if s.journal != nil {
+		if closeErr := s.journal.Close(); err == nil {
+			err = closeErr
+		}
+		s.journal = nil
+	}
+	return err
+}
+
+// commitLocked durably writes entry to the journal, applies it in memory and
+// compacts once the journal has grown past the threshold. Callers must hold
+// s.mu, which also guarantees the in-memory state checked before calling is
+// still current.
+//
+// A failed write may leave part of the entry in the journal, or all of it
+// without knowing whether it is durable, so the journal is abandoned and a
+// compaction starts a new one from the in-memory state, which never saw the
+// entry. Writes fail until that compaction succeeds.
+func (s *FileStore) commitLocked(entry journalEntry, apply func()) error {
+	if s.journal == nil {
+		if err := s.compactLocked(); err != nil {
+			return fmt.Errorf("journal unavailable after a failed write: %w", err)
+		}
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode journal entry: %w", err)
+	}
+	if _, err := s.journal.Write(append(data, '\n')); err != nil {
+		s.abandonJournalLocked()
+		return fmt.Errorf("failed to write journal entry: %w", err)
+	}
+	if err := s.journal.Sync(); err != nil {
+		s.abandonJournalLocked()
+		return fmt.Errorf("failed to sync journal: %w", err)
+	}
+	apply()
+
+	s.entries++
+	if s.entries >= s.compactThreshold {
+		// The entry is already durable; a failed compaction only means the
+		// journal keeps growing until the next attempt
+		_ = s.compactLocked()
+	}
+	return nil
+}
+
+// abandonJournalLocked stops writing to a journal after a failed write and
+// tries to replace it right away. Callers must hold s.mu.
+func (s *FileStore) abandonJournalLocked() {
+	s.journal.Close()
+	s.journal = nil
+	// Until it succeeds, commitLocked retries before every write
+	_ = s.compactLocked()
+}
+
+// compactLocked atomically replaces the snapshot with the in-memory state and
+// starts an empty journal. If the new journal cannot be opened, the previous
+// one stays in use: replaying it over the new snapshot ends in the same
+// state. Callers must hold s.mu.
+func (s *FileStore) compactLocked() error {
+	projects, _ := s.MemoryStore.GetAll()
+	trash, _ := s.MemoryStore.Trash()
+	data, err := json.MarshalIndent(snapshot{
+		ResourceVersion: s.MemoryStore.nextVersion() - 1,
+		Projects:        projects,
+		Trash:           trash,
+		Revisions:       s.revisions,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(s.dir, snapshotFile), data); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	journal, err := os.OpenFile(filepath.Join(s.dir, journalFile), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open journal: %w", err)
+	}
+	if s.journal != nil {
+		s.journal.Close()
+	}
+	s.journal = journal
+	s.entries = 0
+	return nil
+}
+
+// loadSnapshot reads the last snapshot, if any, into memory
+func (s *FileStore) loadSnapshot() error {
+	data, err := os.ReadFile(filepath.Join(s.dir, snapshotFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var snap snapshot
+	if len(data) > 0 && data[0] == '[' {
+		// Snapshots written before resource versions were a bare array
+		err = json.Unmarshal(data, &snap.Projects)
+	} else {
+		err = json.Unmarshal(data, &snap)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+	for _, p := range append(snap.Projects, snap.Trash...) {
+		s.MemoryStore.load(p)
+	}
+	s.MemoryStore.observeVersion(snap.ResourceVersion)
+	for id, revisions := range snap.Revisions {
+		s.revisions[id] = revisions
+	}
+	return nil
+}
+
+// replayJournal applies journal entries written after the last snapshot
+func (s *FileStore) replayJournal() error {
+	f, err := os.Open(filepath.Join(s.dir, journalFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	var pending error
+	line := 0
+	for scanner.Scan() {
+		line++
+		// Only the final line may be torn by a crash mid-write; an
+		// undecodable line followed by more entries means real corruption
+		if pending != nil {
+			return pending
+		}
+		var entry journalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			pending = fmt.Errorf("failed to decode journal line %d: %w", line, err)
+			continue
+		}
+		switch entry.Op {
+		case opCreate, opPut, opUpdate, opTrash, opRestore:
+			s.MemoryStore.load(entry.Project)
+		case opBatch:
+			for _, p := range entry.Projects {
+				s.MemoryStore.load(p)
+			}
+		case opDelete:
+			s.MemoryStore.remove(entry.ID, entry.Version)
+		case opRevision:
+			s.appendRevision(*entry.Revision, entry.Keep)
+		case opDropRevisions:
+			delete(s.revisions, entry.ID)
+		default:
+			return fmt.Errorf("unknown journal operation %q on line %d", entry.Op, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read journal: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file and renames it over path
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,153 @@
+package store
+
+import (
+	"0xhub/backend/internal/models"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileStore_ReplaysJournalOnReopen(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := NewFileStore(dir, 0)
+	if err != nil {
+		t.Fatalf("NewFileStore() failed: %v", err)
+	}
+	store.Create(&models.Project{ID: "test-1", Name: "Project 1", URL: "https://test1.com"})
+	store.Create(&models.Project{ID: "test-2", Name: "Project 2", URL: "https://test2.com"})
+	store.Update(&models.Project{ID: "test-1", Name: "Updated Project", URL: "https://test1.com"})
+	store.Delete("test-2", 0)
+	store.Put(&models.Project{ID: "test-3", Name: "Project 3", URL: "https://test3.com"})
+
+	// Simulate a crash: drop the store without compacting on Close
+	store.journal.Close()
+
+	reopened, err := NewFileStore(dir, 0)
+	if err != nil {
+		t.Fatalf("Reopening store failed: %v", err)
+	}
+	defer reopened.Close()
+
+	retrieved, err := reopened.GetByID("test-1")
+	if err != nil {
+		t.Fatalf("Project should survive restart: %v", err)
+	}
+	if retrieved.Name != "Updated Project" {
+		t.Fatalf("Expected Name 'Updated Project', got %s", retrieved.Name)
+	}
+	if _, err := reopened.GetByID("test-2"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Deleted project should stay deleted, got %v", err)
+	}
+	if _, err := reopened.GetByID("test-3"); err != nil {
+		t.Fatalf("Put project should survive restart: %v", err)
+	}
+}
+
+func TestFileStore_CompactsAfterThreshold(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := NewFileStore(dir, 2)
+	if err != nil {
+		t.Fatalf("NewFileStore() failed: %v", err)
+	}
+	defer store.Close()
+
+	store.Create(&models.Project{ID: "test-1", Name: "Project 1", URL: "https://test1.com"})
+	store.Create(&models.Project{ID: "test-2", Name: "Project 2", URL: "https://test2.com"})
+
+	info, err := os.Stat(filepath.Join(dir, journalFile))
+	if err != nil {
+		t.Fatalf("Journal should exist: %v", err)
+	}
+	if info.Size() != 0 {
+		t.Fatalf("Journal should be truncated after compaction, got %d bytes", info.Size())
+	}
+
+	snapshot, err := NewFileStore(dir, 0)
+	if err != nil {
+		t.Fatalf("Loading snapshot failed: %v", err)
+	}
+	defer snapshot.Close()
+	projects, _ := snapshot.GetAll()
+	if len(projects) != 2 {
+		t.Fatalf("Expected 2 projects in snapshot, got %d", len(projects))
+	}
+}
+
+func TestFileStore_IgnoresTornFinalLine(t *testing.T) {
+	dir := t.TempDir()
+	journal := `{"op":"create","project":{"id":"test-1","name":"Project 1","description":"","url":"https://test1.com"}}
+{"op":"create","project":{"id":"te`
+	if err := os.WriteFile(filepath.Join(dir, journalFile), []byte(journal), 0o644); err != nil {
+		t.Fatalf("Failed to write journal: %v", err)
+	}
+
+	store, err := NewFileStore(dir, 0)
+	if err != nil {
+		t.Fatalf("A torn final journal line should not prevent startup: %v", err)
+	}
+	defer store.Close()
+
+	if _, err := store.GetByID("test-1"); err != nil {
+		t.Fatalf("Complete journal entries should be replayed: %v", err)
+	}
+}
+
+func TestFileStore_UpdateMissingIsNotJournaled(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := NewFileStore(dir, 0)
+	if err != nil {
+		t.Fatalf("NewFileStore() failed: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.Update(&models.Project{ID: "non-existent"}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound, got %v", err)
+	}
+	if store.entries != 0 {
+		t.Fatalf("Failed update should not be journaled, got %d entries", store.entries)
+	}
+}
+
+func TestFileStore_RecoversFromFailedJournalWrite(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(dir, 0)
+	if err != nil {
+		t.Fatalf("NewFileStore() failed: %v", err)
+	}
+	store.Create(&models.Project{ID: "test-1", Name: "Project 1", URL: "https://test1.com"})
+
+	// Fail the next journal write, and the compactions meant to replace the
+	// journal, by closing it and pointing the store at a missing directory
+	store.journal.Close()
+	store.dir = filepath.Join(dir, "missing")
+	if err := store.Create(&models.Project{ID: "test-2", Name: "Project 2", URL: "https://test2.com"}); err == nil {
+		t.Fatal("Expected the failed journal write to be reported")
+	}
+	if err := store.Create(&models.Project{ID: "test-3", Name: "Project 3", URL: "https://test3.com"}); err == nil {
+		t.Fatal("Expected writes to fail until the journal is replaced")
+	}
+	if _, err := store.GetByID("test-2"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected the failed write not to be applied, got %v", err)
+	}
+
+	store.dir = dir
+	if err := store.Create(&models.Project{ID: "test-4", Name: "Project 4", URL: "https://test4.com"}); err != nil {
+		t.Fatalf("Expected writes to resume once the journal is replaced, got %v", err)
+	}
+	// Simulate a crash
+	store.journal.Close()
+
+	reopened, err := NewFileStore(dir, 0)
+	if err != nil {
+		t.Fatalf("Reopening store failed: %v", err)
+	}
+	defer reopened.Close()
+	projects, _ := reopened.GetAll()
+	if len(projects) != 2 || projects[0].ID != "test-1" || projects[1].ID != "test-4" {
+		t.Fatalf("Expected only the successful writes after reopening, got %+v", projects)
+	}
+}
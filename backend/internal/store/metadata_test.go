@@ -0,0 +1,124 @@
+package store
+
+import (
+	"0xhub/backend/internal/models"
+	"testing"
+	"time"
+)
+
+func TestStore_Timestamps(t *testing.T) {
+	for storeName, s := range allStores(t) {
+		t.Run(storeName, func(t *testing.T) {
+			before := time.Now().Add(-time.Millisecond)
+			p := &models.Project{ID: "test-1", Name: "Project 1", URL: "https://test1.com", UpdatedBy: "alice"}
+			if err := s.Create(p); err != nil {
+				t.Fatalf("Create failed: %v", err)
+			}
+			if p.CreatedAt.Before(before) || !p.UpdatedAt.Equal(p.CreatedAt) {
+				t.Fatalf("Expected fresh, equal timestamps, got createdAt %v updatedAt %v", p.CreatedAt, p.UpdatedAt)
+			}
+
+			time.Sleep(2 * time.Millisecond)
+			// Timestamps sent by the caller are ignored
+			updated := &models.Project{ID: "test-1", Name: "Updated", URL: "https://test1.com", UpdatedBy: "bob", CreatedAt: before.Add(-time.Hour)}
+			if err := s.Update(updated); err != nil {
+				t.Fatalf("Update failed: %v", err)
+			}
+			if !updated.CreatedAt.Equal(p.CreatedAt) {
+				t.Fatalf("Expected createdAt %v to be kept, got %v", p.CreatedAt, updated.CreatedAt)
+			}
+			if !updated.UpdatedAt.After(p.UpdatedAt) {
+				t.Fatalf("Expected updatedAt to advance past %v, got %v", p.UpdatedAt, updated.UpdatedAt)
+			}
+
+			time.Sleep(2 * time.Millisecond)
+			replaced := &models.Project{ID: "test-1", Name: "Replaced", URL: "https://test1.com"}
+			if _, err := s.Put(replaced); err != nil {
+				t.Fatalf("Put failed: %v", err)
+			}
+			if !replaced.CreatedAt.Equal(p.CreatedAt) || !replaced.UpdatedAt.After(updated.UpdatedAt) {
+				t.Fatalf("Unexpected timestamps after Put: createdAt %v updatedAt %v", replaced.CreatedAt, replaced.UpdatedAt)
+			}
+
+			stored, err := s.GetByID("test-1")
+			if err != nil {
+				t.Fatalf("GetByID failed: %v", err)
+			}
+			if !stored.CreatedAt.Equal(p.CreatedAt) || !stored.UpdatedAt.Equal(replaced.UpdatedAt) || stored.UpdatedBy != "" {
+				t.Fatalf("Stored metadata %v %v %q does not match the last write", stored.CreatedAt, stored.UpdatedAt, stored.UpdatedBy)
+			}
+		})
+	}
+}
+
+func TestStore_ListSortByTimestamps(t *testing.T) {
+	for storeName, s := range listStores(t) {
+		t.Run(storeName, func(t *testing.T) {
+			for _, id := range []string{"b", "a", "c"} {
+				s.Create(&models.Project{ID: id, Name: id, URL: "https://test.com"})
+				time.Sleep(2 * time.Millisecond)
+			}
+			// Touch the oldest project so it becomes the most recently updated
+			s.Update(&models.Project{ID: "b", Name: "b", URL: "https://test.com"})
+
+			tests := []struct {
+				sort string
+				want []string
+			}{
+				{SortCreated, []string{"b", "a", "c"}},
+				{"-" + SortCreated, []string{"c", "a", "b"}},
+				{"-" + SortUpdated, []string{"b", "c", "a"}},
+			}
+			for _, tt := range tests {
+				result, err := s.List(ListOptions{Sort: tt.sort})
+				if err != nil {
+					t.Fatalf("List failed: %v", err)
+				}
+				if got := listIDs(result); !equalIDs(got, tt.want) {
+					t.Fatalf("Sort %s: expected %v, got %v", tt.sort, tt.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestFileStore_TimestampsSurviveRestart(t *testing.T) {
+	dir := t.TempDir()
+
+	store, _ := NewFileStore(dir, 0)
+	p := &models.Project{ID: "test-1", Name: "Project 1", URL: "https://test1.com", UpdatedBy: "alice"}
+	store.Create(p)
+	store.Close()
+
+	reopened, err := NewFileStore(dir, 0)
+	if err != nil {
+		t.Fatalf("NewFileStore() failed: %v", err)
+	}
+	defer reopened.Close()
+
+	stored, _ := reopened.GetByID("test-1")
+	if !stored.CreatedAt.Equal(p.CreatedAt) || !stored.UpdatedAt.Equal(p.UpdatedAt) || stored.UpdatedBy != "alice" {
+		t.Fatalf("Expected metadata to survive restart, got %v %v %q", stored.CreatedAt, stored.UpdatedAt, stored.UpdatedBy)
+	}
+}
+
+func TestStore_ManagedByIsFixedAtCreation(t *testing.T) {
+	for storeName, s := range allStores(t) {
+		t.Run(storeName, func(t *testing.T) {
+			s.Create(&models.Project{ID: "test-1", Name: "Project 1", URL: "https://test1.com", ManagedBy: models.ManagedByOperator})
+
+			updated := &models.Project{ID: "test-1", Name: "Updated", URL: "https://test1.com"}
+			if err := s.Update(updated); err != nil {
+				t.Fatalf("Update failed: %v", err)
+			}
+			replaced := &models.Project{ID: "test-1", Name: "Replaced", URL: "https://test1.com"}
+			if _, err := s.Put(replaced); err != nil {
+				t.Fatalf("Put failed: %v", err)
+			}
+			stored, _ := s.GetByID("test-1")
+			if updated.ManagedBy != models.ManagedByOperator || stored.ManagedBy != models.ManagedByOperator {
+				t.Fatalf("Expected managedBy to be kept, got %q and %q", updated.ManagedBy, stored.ManagedBy)
+			}
+		})
+	}
+}
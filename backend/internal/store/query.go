@@ -0,0 +1,207 @@
+package store
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"0xhub/backend/internal/models"
+)
+
+// ErrInvalidListOptions is returned when list options cannot be applied
+var ErrInvalidListOptions = errors.New("invalid list options")
+
+const (
+	// DefaultListLimit is the page size of a List call that does not set one
+	DefaultListLimit = 100
+	// MaxListLimit caps the page size a single List call can return
+	MaxListLimit = 500
+)
+
+// Sort fields accepted by ListOptions.Sort. Prefix with "-" for descending.
+const (
+	SortID       = "id"
+	SortName     = "name"
+	SortCategory = "category"
+	SortStatus   = "status"
+	SortCreated  = "createdAt"
+	SortUpdated  = "updatedAt"
+)
+
+// ListOptions filters, sorts and paginates a List call
+type ListOptions struct {
+	// Category and Status match exactly, ignoring case
+	Category string
+	Status   string
+	// Query matches a case-insensitive substring of name, description or category
+	Query string
+	// Sort is one of the Sort* fields, optionally prefixed with "-"; defaults to name
+	Sort string
+	// Limit is the page size, DefaultListLimit when zero and at most
+	// MaxListLimit
+	Limit int
+	// Cursor is the NextCursor of a previous page
+	Cursor string
+}
+
+// ListResult is a single page of projects
+type ListResult struct {
+	Projects []*models.Project `json:"projects"`
+	// Total is the number of projects matching the filters across all pages
+	Total int `json:"total"`
+	// NextCursor fetches the following page; empty on the last page
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// sortSpec is a validated ListOptions.Sort
+type sortSpec struct {
+	field string
+	desc  bool
+}
+
+func parseSort(s string) (sortSpec, error) {
+	spec := sortSpec{field: SortName}
+	if s == "" {
+		return spec, nil
+	}
+	if strings.HasPrefix(s, "-") {
+		spec.desc = true
+		s = s[1:]
+	}
+	switch s {
+	case SortID, SortName, SortCategory, SortStatus, SortCreated, SortUpdated:
+		spec.field = s
+	default:
+		return spec, fmt.Errorf("%w: unknown sort field %q", ErrInvalidListOptions, s)
+	}
+	return spec, nil
+}
+
+// encodeCursor and decodeCursor make the page offset opaque to clients
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil {
+		if offset, convErr := strconv.Atoi(string(raw)); convErr == nil && offset >= 0 {
+			return offset, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: malformed cursor", ErrInvalidListOptions)
+}
+
+// normalizeLimit validates the page size and applies DefaultListLimit and
+// MaxListLimit
+func normalizeLimit(limit int) (int, error) {
+	if limit < 0 {
+		return 0, fmt.Errorf("%w: limit must not be negative", ErrInvalidListOptions)
+	}
+	if limit == 0 {
+		limit = DefaultListLimit
+	}
+	if limit > MaxListLimit {
+		limit = MaxListLimit
+	}
+	return limit, nil
+}
+
+// nextCursor returns the cursor for the page after one starting at offset
+func nextCursor(offset, pageLen, total int) string {
+	if offset+pageLen >= total || pageLen == 0 {
+		return ""
+	}
+	return encodeCursor(offset + pageLen)
+}
+
+// listProjects applies opts to an unordered slice of projects. Stores that
+// cannot push filtering down to their backend share this implementation.
+func listProjects(all []*models.Project, opts ListOptions) (*ListResult, error) {
+	spec, err := parseSort(opts.Sort)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := decodeCursor(opts.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := normalizeLimit(opts.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	query := strings.ToLower(opts.Query)
+	matched := make([]*models.Project, 0, len(all))
+	for _, p := range all {
+		if opts.Category != "" && !strings.EqualFold(p.Category, opts.Category) {
+			continue
+		}
+		if opts.Status != "" && !strings.EqualFold(p.Status, opts.Status) {
+			continue
+		}
+		if query != "" &&
+			!strings.Contains(strings.ToLower(p.Name), query) &&
+			!strings.Contains(strings.ToLower(p.Description), query) &&
+			!strings.Contains(strings.ToLower(p.Category), query) {
+			continue
+		}
+		matched = append(matched, p)
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		cmp := compareField(matched[i], matched[j], spec.field)
+		if cmp == 0 {
+			// Tie-break on ID so pages are stable
+			return matched[i].ID < matched[j].ID
+		}
+		if spec.desc {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+
+	total := len(matched)
+	if offset > total {
+		offset = total
+	}
+	end := min(offset+limit, total)
+	page := matched[offset:end]
+
+	return &ListResult{
+		Projects:   page,
+		Total:      total,
+		NextCursor: nextCursor(offset, len(page), total),
+	}, nil
+}
+
+// compareField orders two projects by field, ignoring case for text fields
+func compareField(a, b *models.Project, field string) int {
+	switch field {
+	case SortID:
+		return strings.Compare(a.ID, b.ID)
+	case SortCategory:
+		return compareFolded(a.Category, b.Category)
+	case SortStatus:
+		return compareFolded(a.Status, b.Status)
+	case SortCreated:
+		return a.CreatedAt.Compare(b.CreatedAt)
+	case SortUpdated:
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	default:
+		return compareFolded(a.Name, b.Name)
+	}
+}
+
+// compareFolded orders two strings ignoring case, including outside ASCII.
+// SQLiteStore sorts with it as the foldCollation collation, so every store
+// orders text the same way.
+func compareFolded(a, b string) int {
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
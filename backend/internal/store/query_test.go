@@ -0,0 +1,199 @@
+package store
+
+import (
+	"0xhub/backend/internal/models"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func seedListProjects(t *testing.T, s Store) {
+	t.Helper()
+	projects := []*models.Project{
+		{ID: "1", Name: "Kubernetes", Description: "Container orchestration", Category: "Infrastructure", Status: "active"},
+		{ID: "2", Name: "docker", Description: "Containers for developers", Category: "Containerization", Status: "active"},
+		{ID: "3", Name: "Prometheus", Description: "Monitoring toolkit", Category: "Monitoring", Status: "archived"},
+		{ID: "4", Name: "Grafana", Description: "Observability 100% open", Category: "Monitoring", Status: "active"},
+	}
+	for _, p := range projects {
+		if err := s.Create(p); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+}
+
+func listIDs(result *ListResult) []string {
+	ids := make([]string, 0, len(result.Projects))
+	for _, p := range result.Projects {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
+
+func equalIDs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// listStores returns every Store implementation that must honor ListOptions
+func listStores(t *testing.T) map[string]Store {
+	return map[string]Store{
+		"memory": NewStore(),
+		"sqlite": newTestSQLiteStore(t),
+	}
+}
+
+// allStores returns every Store implementation, for behavior they must share
+func allStores(t *testing.T) map[string]Store {
+	stores := listStores(t)
+	fileStore, err := NewFileStore(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("NewFileStore() failed: %v", err)
+	}
+	t.Cleanup(func() { fileStore.Close() })
+	stores["file"] = fileStore
+	return stores
+}
+
+func TestStore_List(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  ListOptions
+		want  []string
+		total int
+	}{
+		{name: "default sorts by name", opts: ListOptions{}, want: []string{"2", "4", "1", "3"}, total: 4},
+		{name: "descending sort", opts: ListOptions{Sort: "-name"}, want: []string{"3", "1", "4", "2"}, total: 4},
+		{name: "sort by category breaks ties on id", opts: ListOptions{Sort: "category"}, want: []string{"2", "1", "3", "4"}, total: 4},
+		{name: "category filter ignores case", opts: ListOptions{Category: "monitoring"}, want: []string{"4", "3"}, total: 2},
+		{name: "status filter", opts: ListOptions{Status: "archived"}, want: []string{"3"}, total: 1},
+		{name: "free text query", opts: ListOptions{Query: "CONTAINER"}, want: []string{"2", "1"}, total: 2},
+		{name: "query wildcards match literally", opts: ListOptions{Query: "100%"}, want: []string{"4"}, total: 1},
+		{name: "limit", opts: ListOptions{Limit: 2}, want: []string{"2", "4"}, total: 4},
+	}
+
+	for storeName, s := range listStores(t) {
+		seedListProjects(t, s)
+		for _, tt := range tests {
+			t.Run(storeName+"/"+tt.name, func(t *testing.T) {
+				result, err := s.List(tt.opts)
+				if err != nil {
+					t.Fatalf("List failed: %v", err)
+				}
+				if got := listIDs(result); !equalIDs(got, tt.want) {
+					t.Fatalf("Expected %v, got %v", tt.want, got)
+				}
+				if result.Total != tt.total {
+					t.Fatalf("Expected total %d, got %d", tt.total, result.Total)
+				}
+			})
+		}
+	}
+}
+
+func TestStore_ListPagination(t *testing.T) {
+	for storeName, s := range listStores(t) {
+		t.Run(storeName, func(t *testing.T) {
+			seedListProjects(t, s)
+
+			var ids []string
+			cursor := ""
+			for pages := 0; ; pages++ {
+				if pages > 4 {
+					t.Fatal("Pagination did not terminate")
+				}
+				result, err := s.List(ListOptions{Limit: 3, Cursor: cursor})
+				if err != nil {
+					t.Fatalf("List failed: %v", err)
+				}
+				ids = append(ids, listIDs(result)...)
+				if result.NextCursor == "" {
+					break
+				}
+				cursor = result.NextCursor
+			}
+
+			if want := []string{"2", "4", "1", "3"}; !equalIDs(ids, want) {
+				t.Fatalf("Expected %v across pages, got %v", want, ids)
+			}
+		})
+	}
+}
+
+func TestStore_ListDefaultLimit(t *testing.T) {
+	for storeName, s := range listStores(t) {
+		t.Run(storeName, func(t *testing.T) {
+			for i := 0; i < DefaultListLimit+1; i++ {
+				if err := s.Create(&models.Project{ID: fmt.Sprintf("%03d", i), Name: "Project"}); err != nil {
+					t.Fatalf("Create failed: %v", err)
+				}
+			}
+
+			result, err := s.List(ListOptions{})
+			if err != nil {
+				t.Fatalf("List failed: %v", err)
+			}
+			if len(result.Projects) != DefaultListLimit || result.Total != DefaultListLimit+1 || result.NextCursor == "" {
+				t.Fatalf("Expected a first page of %d of %d projects, got %d of %d", DefaultListLimit, DefaultListLimit+1, len(result.Projects), result.Total)
+			}
+		})
+	}
+}
+
+func TestStore_ListSortsNonASCIIAlike(t *testing.T) {
+	for storeName, s := range listStores(t) {
+		t.Run(storeName, func(t *testing.T) {
+			for _, p := range []*models.Project{
+				{ID: "1", Name: "Ölmühle"},
+				{ID: "2", Name: "öffnen"},
+				{ID: "3", Name: "Zebra"},
+				{ID: "4", Name: "Äpfel", Category: "Früchte"},
+				{ID: "5", Name: "apple", Category: "FRÜCHTE"},
+			} {
+				if err := s.Create(p); err != nil {
+					t.Fatalf("Create failed: %v", err)
+				}
+			}
+
+			result, err := s.List(ListOptions{})
+			if err != nil {
+				t.Fatalf("List failed: %v", err)
+			}
+			// Case is ignored: "öffnen" sorts before "Ölmühle" and both after "Zebra"
+			if want := []string{"5", "3", "4", "2", "1"}; !equalIDs(listIDs(result), want) {
+				t.Fatalf("Expected %v, got %v", want, listIDs(result))
+			}
+			result, err = s.List(ListOptions{Category: "früchte"})
+			if err != nil {
+				t.Fatalf("List failed: %v", err)
+			}
+			if want := []string{"5", "4"}; !equalIDs(listIDs(result), want) {
+				t.Fatalf("Expected %v for a category differing in case, got %v", want, listIDs(result))
+			}
+		})
+	}
+}
+
+func TestStore_ListInvalidOptions(t *testing.T) {
+	for storeName, s := range listStores(t) {
+		t.Run(storeName, func(t *testing.T) {
+			invalid := []ListOptions{
+				{Sort: "url"},
+				{Cursor: "not-a-cursor"},
+				{Limit: -1},
+			}
+			for _, opts := range invalid {
+				if _, err := s.List(opts); !errors.Is(err, ErrInvalidListOptions) {
+					t.Fatalf("Expected ErrInvalidListOptions for %+v, got %v", opts, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,382 @@
+package store
+
+import (
+	"html"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"0xhub/backend/internal/closer"
+	"0xhub/backend/internal/models"
+)
+
+// Field weights used when ranking search results
+const (
+	nameWeight        = 3.0
+	categoryWeight    = 2.0
+	descriptionWeight = 1.0
+	// prefixPenalty scales the score of a term that only matched as a prefix
+	prefixPenalty = 0.5
+)
+
+// SearchResult is a single ranked match
+type SearchResult struct {
+	Project *models.Project `json:"project"`
+	Score   float64         `json:"score"`
+	// Highlights holds HTML-escaped name and description with matched terms
+	// wrapped in <mark> tags; fields without matches are omitted
+	Highlights map[string]string `json:"highlights,omitempty"`
+}
+
+// Searcher runs full-text queries over projects
+type Searcher interface {
+	Search(query string, limit int) ([]SearchResult, error)
+}
+
+// Index is an in-process inverted index over project name, description and
+// category
+type Index struct {
+	mu       sync.RWMutex
+	docs     map[string]*models.Project
+	postings map[string]map[string]float64 // term -> project ID -> weight
+	docTerms map[string][]string           // project ID -> indexed terms
+	// terms holds every key of postings in order, so the terms sharing a
+	// prefix are found by binary search
+	terms []string
+}
+
+// NewIndex creates an empty index
+func NewIndex() *Index {
+	return &Index{
+		docs:     make(map[string]*models.Project),
+		postings: make(map[string]map[string]float64),
+		docTerms: make(map[string][]string),
+	}
+}
+
+// Add indexes project, replacing any previous version with the same ID
+func (idx *Index) Add(project *models.Project) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.removeLocked(project.ID)
+
+	doc := *project
+	idx.docs[doc.ID] = &doc
+
+	weights := make(map[string]float64)
+	for _, field := range []struct {
+		text   string
+		weight float64
+	}{
+		{doc.Name, nameWeight},
+		{doc.Category, categoryWeight},
+		{doc.Description, descriptionWeight},
+	} {
+		for _, tok := range tokenize(field.text) {
+			weights[tok.term] += field.weight
+		}
+	}
+
+	terms := make([]string, 0, len(weights))
+	for term, weight := range weights {
+		if idx.postings[term] == nil {
+			idx.postings[term] = make(map[string]float64)
+			i, _ := slices.BinarySearch(idx.terms, term)
+			idx.terms = slices.Insert(idx.terms, i, term)
+		}
+		idx.postings[term][doc.ID] = weight
+		terms = append(terms, term)
+	}
+	idx.docTerms[doc.ID] = terms
+}
+
+// Remove drops a project from the index
+func (idx *Index) Remove(id string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.removeLocked(id)
+}
+
+func (idx *Index) removeLocked(id string) {
+	for _, term := range idx.docTerms[id] {
+		delete(idx.postings[term], id)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+			if i, ok := slices.BinarySearch(idx.terms, term); ok {
+				idx.terms = slices.Delete(idx.terms, i, i+1)
+			}
+		}
+	}
+	delete(idx.docTerms, id)
+	delete(idx.docs, id)
+}
+
+// Search returns projects matching every term of query, best match first.
+// Terms also match as prefixes of indexed words at a reduced score. A limit
+// of zero or less returns every match.
+func (idx *Index) Search(query string, limit int) ([]SearchResult, error) {
+	terms := uniqueTerms(query)
+	if len(terms) == 0 {
+		return []SearchResult{}, nil
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	var scores map[string]float64
+	for _, term := range terms {
+		termScores := make(map[string]float64)
+		// The terms term is a prefix of start where it would be inserted
+		start, _ := slices.BinarySearch(idx.terms, term)
+		for _, indexed := range idx.terms[start:] {
+			if !strings.HasPrefix(indexed, term) {
+				break
+			}
+			factor := prefixPenalty
+			if indexed == term {
+				factor = 1
+			}
+			for id, weight := range idx.postings[indexed] {
+				if score := weight * factor; score > termScores[id] {
+					termScores[id] = score
+				}
+			}
+		}
+
+		// Every term must match, so intersect with the previous terms
+		if scores == nil {
+			scores = termScores
+			continue
+		}
+		for id := range scores {
+			if s, ok := termScores[id]; ok {
+				scores[id] += s
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+
+	results := make([]SearchResult, 0, len(scores))
+	for id, score := range scores {
+		doc := *idx.docs[id]
+		results = append(results, SearchResult{
+			Project:    &doc,
+			Score:      score,
+			Highlights: highlights(&doc, terms),
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Project.ID < results[j].Project.ID
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// token is a normalized word and its byte range in the source text
+type token struct {
+	term       string
+	start, end int
+}
+
+// tokenize splits text into lowercased letter/digit runs
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isWord && start < 0 {
+			start = i
+		} else if !isWord && start >= 0 {
+			tokens = append(tokens, token{strings.ToLower(text[start:i]), start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{strings.ToLower(text[start:]), start, len(text)})
+	}
+	return tokens
+}
+
+func uniqueTerms(query string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, tok := range tokenize(query) {
+		if !seen[tok.term] {
+			seen[tok.term] = true
+			terms = append(terms, tok.term)
+		}
+	}
+	return terms
+}
+
+func highlights(p *models.Project, terms []string) map[string]string {
+	result := make(map[string]string)
+	if h, ok := highlight(p.Name, terms); ok {
+		result["name"] = h
+	}
+	if h, ok := highlight(p.Description, terms); ok {
+		result["description"] = h
+	}
+	return result
+}
+
+// highlight wraps the parts of text matching terms in <mark> tags, escaping
+// everything else. ok is false when nothing matched.
+func highlight(text string, terms []string) (string, bool) {
+	var b strings.Builder
+	last := 0
+	matched := false
+	for _, tok := range tokenize(text) {
+		length := 0
+		for _, term := range terms {
+			if strings.HasPrefix(tok.term, term) && len(term) > length {
+				length = len(term)
+			}
+		}
+		if length == 0 {
+			continue
+		}
+		// Lowercasing can change byte lengths; only mark the prefix when it
+		// maps cleanly back onto the original text
+		end := tok.start + length
+		if end > tok.end || !strings.EqualFold(text[tok.start:end], tok.term[:length]) {
+			end = tok.end
+		}
+		b.WriteString(html.EscapeString(text[last:tok.start]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(text[tok.start:end]))
+		b.WriteString("</mark>")
+		last = end
+		matched = true
+	}
+	if !matched {
+		return "", false
+	}
+	b.WriteString(html.EscapeString(text[last:]))
+	return b.String(), true
+}
+
+// IndexedStore wraps a Store and keeps a search index of live projects in
+// sync with every successful write
+type IndexedStore struct {
+	Store
+	// mu serializes writers so the index applies mutations in store order
+	mu    sync.Mutex
+	index *Index
+}
+
+// NewIndexedStore builds an index from the current contents of inner
+func NewIndexedStore(inner Store) (*IndexedStore, error) {
+	projects, err := inner.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	index := NewIndex()
+	for _, p := range projects {
+		index.Add(p)
+	}
+	return &IndexedStore{Store: inner, index: index}, nil
+}
+
+// Create creates a new project and indexes it
+func (s *IndexedStore) Create(project *models.Project) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.Store.Create(project); err != nil {
+		return err
+	}
+	s.index.Add(project)
+	return nil
+}
+
+// Put creates or replaces a project and indexes it
+func (s *IndexedStore) Put(project *models.Project) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	created, err := s.Store.Put(project)
+	if err != nil {
+		return false, err
+	}
+	s.index.Add(project)
+	return created, nil
+}
+
+// Update updates an existing project and reindexes it
+func (s *IndexedStore) Update(project *models.Project) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.Store.Update(project); err != nil {
+		return err
+	}
+	s.index.Add(project)
+	return nil
+}
+
+// Delete moves a project to the trash and drops it from the index
+func (s *IndexedStore) Delete(id string, version int64) (*models.Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project, err := s.Store.Delete(id, version)
+	if err != nil {
+		return nil, err
+	}
+	s.index.Remove(id)
+	return project, nil
+}
+
+// Restore moves a project out of the trash and indexes it again
+func (s *IndexedStore) Restore(id string, version int64) (*models.Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project, err := s.Store.Restore(id, version)
+	if err != nil {
+		return nil, err
+	}
+	s.index.Add(project)
+	return project, nil
+}
+
+// Apply performs writes atomically and updates the index for each change
+func (s *IndexedStore) Apply(writes []Write) ([]Change, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changes, err := s.Store.Apply(writes)
+	if err != nil {
+		return nil, err
+	}
+	for _, change := range changes {
+		if change.Type == ChangeDeleted {
+			s.index.Remove(change.Project.ID)
+		} else {
+			s.index.Add(change.Project)
+		}
+	}
+	return changes, nil
+}
+
+// Search runs a full-text query against the index
+func (s *IndexedStore) Search(query string, limit int) ([]SearchResult, error) {
+	return s.index.Search(query, limit)
+}
+
+// Close closes the indexed store
+func (s *IndexedStore) Close() error {
+	return closer.Close(s.Store)
+}
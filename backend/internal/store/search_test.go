@@ -0,0 +1,121 @@
+package store
+
+import (
+	"0xhub/backend/internal/models"
+	"testing"
+)
+
+func newTestIndexedStore(t *testing.T) *IndexedStore {
+	t.Helper()
+	s, err := NewIndexedStore(NewStore())
+	if err != nil {
+		t.Fatalf("NewIndexedStore() failed: %v", err)
+	}
+	s.Create(&models.Project{ID: "k8s", Name: "Kubernetes", Description: "Production-Grade Container Orchestration", Category: "Infrastructure"})
+	s.Create(&models.Project{ID: "helm", Name: "Helm", Description: "The package manager for Kubernetes", Category: "DevOps"})
+	s.Create(&models.Project{ID: "grafana", Name: "Grafana", Description: "The open <observability> platform", Category: "Visualization"})
+	return s
+}
+
+func TestIndexedStore_SearchRanksNameAboveDescription(t *testing.T) {
+	s := newTestIndexedStore(t)
+
+	results, err := s.Search("kubernetes", 0)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	if results[0].Project.ID != "k8s" || results[1].Project.ID != "helm" {
+		t.Fatalf("Expected name match first, got %s then %s", results[0].Project.ID, results[1].Project.ID)
+	}
+	if results[0].Highlights["name"] != "<mark>Kubernetes</mark>" {
+		t.Fatalf("Unexpected name highlight %q", results[0].Highlights["name"])
+	}
+	if results[1].Highlights["description"] != "The package manager for <mark>Kubernetes</mark>" {
+		t.Fatalf("Unexpected description highlight %q", results[1].Highlights["description"])
+	}
+}
+
+func TestIndexedStore_SearchPrefixAndAllTerms(t *testing.T) {
+	s := newTestIndexedStore(t)
+
+	results, _ := s.Search("kube pack", 0)
+	if len(results) != 1 || results[0].Project.ID != "helm" {
+		t.Fatalf("Expected only helm to match every term, got %+v", results)
+	}
+	if got := results[0].Highlights["description"]; got != "The <mark>pack</mark>age manager for <mark>Kube</mark>rnetes" {
+		t.Fatalf("Unexpected prefix highlight %q", got)
+	}
+}
+
+func TestIndex_TermsFollowPostings(t *testing.T) {
+	idx := NewIndex()
+	idx.Add(&models.Project{ID: "1", Name: "Kube kubectl"})
+	idx.Add(&models.Project{ID: "2", Name: "Kubernetes kubelet"})
+	idx.Add(&models.Project{ID: "3", Name: "Kubernetes ku"})
+	idx.Remove("1")
+	// Replacing a project drops the terms only it used
+	idx.Add(&models.Project{ID: "3", Name: "Kubernetes"})
+
+	want := []string{"kubelet", "kubernetes"}
+	if !equalIDs(idx.terms, want) {
+		t.Fatalf("Expected sorted terms %v, got %v", want, idx.terms)
+	}
+	results, _ := idx.Search("kube", 0)
+	if len(results) != 2 || results[0].Score != nameWeight*prefixPenalty {
+		t.Fatalf("Expected both remaining projects as prefix matches, got %+v", results)
+	}
+	if results, _ := idx.Search("kubernetes", 0); len(results) != 2 || results[0].Score != nameWeight {
+		t.Fatalf("Expected exact matches at full weight, got %+v", results)
+	}
+}
+
+func TestIndexedStore_SearchEscapesHighlights(t *testing.T) {
+	s := newTestIndexedStore(t)
+
+	results, _ := s.Search("observability", 0)
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results))
+	}
+	if got := results[0].Highlights["description"]; got != "The open &lt;<mark>observability</mark>&gt; platform" {
+		t.Fatalf("Unexpected escaped highlight %q", got)
+	}
+}
+
+func TestIndexedStore_IndexFollowsMutations(t *testing.T) {
+	s := newTestIndexedStore(t)
+
+	s.Update(&models.Project{ID: "grafana", Name: "Loki", Description: "Log aggregation"})
+	if results, _ := s.Search("grafana", 0); len(results) != 0 {
+		t.Fatalf("Updated project should not match its old name, got %d results", len(results))
+	}
+	if results, _ := s.Search("loki", 0); len(results) != 1 {
+		t.Fatalf("Updated project should match its new name, got %d results", len(results))
+	}
+
+	s.Delete("grafana", 0)
+	if results, _ := s.Search("loki", 0); len(results) != 0 {
+		t.Fatalf("Deleted project should not match, got %d results", len(results))
+	}
+
+	// Failed mutations must not touch the index
+	s.Update(&models.Project{ID: "missing", Name: "Ghost"})
+	if results, _ := s.Search("ghost", 0); len(results) != 0 {
+		t.Fatal("Failed update should not be indexed")
+	}
+}
+
+func TestNewIndexedStore_IndexesExistingProjects(t *testing.T) {
+	inner := NewStore()
+	inner.Create(&models.Project{ID: "1", Name: "Prometheus"})
+
+	s, err := NewIndexedStore(inner)
+	if err != nil {
+		t.Fatalf("NewIndexedStore() failed: %v", err)
+	}
+	if results, _ := s.Search("prometheus", 0); len(results) != 1 {
+		t.Fatalf("Expected existing project to be indexed, got %d results", len(results))
+	}
+}
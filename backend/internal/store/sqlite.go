@@ -0,0 +1,676 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"0xhub/backend/internal/models"
+
+	"modernc.org/sqlite"
+)
+
+// migrations are applied in order; each entry becomes one schema version.
+// Never edit an existing entry, append a new one instead.
+var migrations = []string{
+	`CREATE TABLE projects (
+		id          TEXT PRIMARY KEY,
+		name        TEXT NOT NULL,
+		description TEXT NOT NULL DEFAULT '',
+		url         TEXT NOT NULL DEFAULT '',
+		icon        TEXT NOT NULL DEFAULT '',
+		category    TEXT NOT NULL DEFAULT '',
+		status      TEXT NOT NULL DEFAULT ''
+	)`,
+	`ALTER TABLE projects ADD COLUMN resource_version INTEGER NOT NULL DEFAULT 0;
+	UPDATE projects SET resource_version = rowid;
+	CREATE TABLE resource_version_seq (
+		id    INTEGER PRIMARY KEY CHECK (id = 1),
+		value INTEGER NOT NULL
+	);
+	INSERT INTO resource_version_seq (id, value) SELECT 1, COALESCE(MAX(resource_version), 0) FROM projects`,
+	// Timestamps are Unix milliseconds; zero means unknown
+	`ALTER TABLE projects ADD COLUMN created_at INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE projects ADD COLUMN updated_at INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE projects ADD COLUMN updated_by TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE projects ADD COLUMN managed_by TEXT NOT NULL DEFAULT ''`,
+	// Deleted projects move here until they are restored or purged
+	`CREATE TABLE trash (
+		id               TEXT PRIMARY KEY,
+		name             TEXT NOT NULL,
+		description      TEXT NOT NULL DEFAULT '',
+		url              TEXT NOT NULL DEFAULT '',
+		icon             TEXT NOT NULL DEFAULT '',
+		category         TEXT NOT NULL DEFAULT '',
+		status           TEXT NOT NULL DEFAULT '',
+		resource_version INTEGER NOT NULL,
+		created_at       INTEGER NOT NULL DEFAULT 0,
+		updated_at       INTEGER NOT NULL DEFAULT 0,
+		updated_by       TEXT NOT NULL DEFAULT '',
+		managed_by       TEXT NOT NULL DEFAULT '',
+		deleted_at       INTEGER NOT NULL
+	)`,
+	// Revision history kept by a HistoryStore; project and changes are JSON
+	`CREATE TABLE revisions (
+		project_id TEXT NOT NULL,
+		revision   INTEGER NOT NULL,
+		project    TEXT NOT NULL,
+		changes    TEXT NOT NULL,
+		PRIMARY KEY (project_id, revision)
+	)`,
+}
+
+// foldCollation compares text like the other stores do; SQLite's NOCASE only
+// folds ASCII letters
+const foldCollation = "FOLDCASE"
+
+func init() {
+	sqlite.MustRegisterCollationUtf8(foldCollation, compareFolded)
+}
+
+// SQLiteStore is a durable store for projects backed by SQLite
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore opens (or creates) the SQLite database at path and applies
+// any pending schema migrations
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+	// SQLite allows a single writer; serialize access through one connection
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(`PRAGMA journal_mode=WAL; PRAGMA busy_timeout=5000`); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to configure sqlite database: %w", err)
+	}
+
+	s := &SQLiteStore{db: db}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close closes the underlying database
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// migrate brings the schema up to the latest version
+func (s *SQLiteStore) migrate() error {
+	if _, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER PRIMARY KEY)`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	var current int
+	if err := s.db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	for i := current; i < len(migrations); i++ {
+		version := i + 1
+		tx, err := s.db.Begin()
+		if err != nil {
+			return fmt.Errorf("failed to begin migration %d: %w", version, err)
+		}
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to apply migration %d: %w", version, err)
+		}
+		if _, err := tx.Exec(`INSERT INTO schema_migrations (version) VALUES (?)`, version); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record migration %d: %w", version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit migration %d: %w", version, err)
+		}
+	}
+	return nil
+}
+
+const projectColumns = `id, name, description, url, icon, category, status, resource_version, created_at, updated_at, updated_by, managed_by`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// trashColumns are the columns of the trash table, in scanTrashed order
+const trashColumns = projectColumns + `, deleted_at`
+
+func scanTrashed(row rowScanner) (*models.Project, error) {
+	var p models.Project
+	var createdAt, updatedAt, deletedAt int64
+	if err := row.Scan(&p.ID, &p.Name, &p.Description, &p.URL, &p.Icon, &p.Category, &p.Status, &p.ResourceVersion,
+		&createdAt, &updatedAt, &p.UpdatedBy, &p.ManagedBy, &deletedAt); err != nil {
+		return nil, err
+	}
+	p.CreatedAt = fromUnixMilli(createdAt)
+	p.UpdatedAt = fromUnixMilli(updatedAt)
+	p.DeletedAt = fromUnixMilli(deletedAt)
+	return &p, nil
+}
+
+func scanProject(row rowScanner) (*models.Project, error) {
+	var p models.Project
+	var createdAt, updatedAt int64
+	if err := row.Scan(&p.ID, &p.Name, &p.Description, &p.URL, &p.Icon, &p.Category, &p.Status, &p.ResourceVersion,
+		&createdAt, &updatedAt, &p.UpdatedBy, &p.ManagedBy); err != nil {
+		return nil, err
+	}
+	p.CreatedAt = fromUnixMilli(createdAt)
+	p.UpdatedAt = fromUnixMilli(updatedAt)
+	return &p, nil
+}
+
+// toUnixMilli and fromUnixMilli convert timestamps to and from their column
+// representation, mapping the zero time to 0
+func toUnixMilli(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
+
+func fromUnixMilli(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms).UTC()
+}
+
+// GetAll returns all projects
+func (s *SQLiteStore) GetAll() ([]*models.Project, error) {
+	rows, err := s.db.Query(`SELECT ` + projectColumns + ` FROM projects ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query projects: %w", err)
+	}
+	defer rows.Close()
+
+	projects := make([]*models.Project, 0)
+	for rows.Next() {
+		p, err := scanProject(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan project: %w", err)
+		}
+		projects = append(projects, p)
+	}
+	return projects, rows.Err()
+}
+
+// sortColumns maps ListOptions sort fields to ORDER BY expressions
+var sortColumns = map[string]string{
+	SortID:       "id",
+	SortName:     "name COLLATE " + foldCollation,
+	SortCategory: "category COLLATE " + foldCollation,
+	SortStatus:   "status COLLATE " + foldCollation,
+	SortCreated:  "created_at",
+	SortUpdated:  "updated_at",
+}
+
+// List returns a filtered, sorted page of projects
+func (s *SQLiteStore) List(opts ListOptions) (*ListResult, error) {
+	spec, err := parseSort(opts.Sort)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := decodeCursor(opts.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := normalizeLimit(opts.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var where []string
+	var args []interface{}
+	if opts.Category != "" {
+		where = append(where, "category = ? COLLATE "+foldCollation)
+		args = append(args, opts.Category)
+	}
+	if opts.Status != "" {
+		where = append(where, "status = ? COLLATE "+foldCollation)
+		args = append(args, opts.Status)
+	}
+	if opts.Query != "" {
+		pattern := "%" + escapeLike(opts.Query) + "%"
+		where = append(where, `(name LIKE ? ESCAPE '\' OR description LIKE ? ESCAPE '\' OR category LIKE ? ESCAPE '\')`)
+		args = append(args, pattern, pattern, pattern)
+	}
+	whereClause := ""
+	if len(where) > 0 {
+		whereClause = " WHERE " + strings.Join(where, " AND ")
+	}
+
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM projects`+whereClause, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to count projects: %w", err)
+	}
+
+	direction := "ASC"
+	if spec.desc {
+		direction = "DESC"
+	}
+	query := `SELECT ` + projectColumns + ` FROM projects` + whereClause +
+		` ORDER BY ` + sortColumns[spec.field] + ` ` + direction + `, id ASC`
+	query += ` LIMIT ? OFFSET ?`
+	args = append(args, limit, offset)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query projects: %w", err)
+	}
+	defer rows.Close()
+
+	projects := make([]*models.Project, 0)
+	for rows.Next() {
+		p, err := scanProject(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan project: %w", err)
+		}
+		projects = append(projects, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &ListResult{
+		Projects:   projects,
+		Total:      total,
+		NextCursor: nextCursor(offset, len(projects), total),
+	}, nil
+}
+
+// escapeLike escapes LIKE wildcards so user input matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// GetByID returns a project by ID
+func (s *SQLiteStore) GetByID(id string) (*models.Project, error) {
+	row := s.db.QueryRow(`SELECT `+projectColumns+` FROM projects WHERE id = ?`, id)
+	p, err := scanProject(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	return p, nil
+}
+
+// Create creates a new project
+func (s *SQLiteStore) Create(project *models.Project) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		_, exists, err := storedVersion(tx, project.ID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return ErrAlreadyExists
+		}
+		if err := dropTrashed(tx, project.ID); err != nil {
+			return err
+		}
+		return writeProject(tx, project, false)
+	})
+}
+
+// Put creates or replaces a project
+func (s *SQLiteStore) Put(project *models.Project) (bool, error) {
+	created := false
+	err := s.inTx(func(tx *sql.Tx) error {
+		var err error
+		created, err = putProject(tx, project)
+		return err
+	})
+	return created, err
+}
+
+// putProject creates or replaces a project, reporting whether it was created
+func putProject(tx *sql.Tx, project *models.Project) (bool, error) {
+	_, exists, err := storedVersion(tx, project.ID)
+	if err != nil {
+		return false, err
+	}
+	if err := dropTrashed(tx, project.ID); err != nil {
+		return false, err
+	}
+	return !exists, writeProject(tx, project, exists)
+}
+
+// Update updates an existing project
+func (s *SQLiteStore) Update(project *models.Project) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		if err := checkVersion(tx, project.ID, project.ResourceVersion); err != nil {
+			return err
+		}
+		return writeProject(tx, project, true)
+	})
+}
+
+// Delete moves a project to the trash
+func (s *SQLiteStore) Delete(id string, version int64) (*models.Project, error) {
+	var project *models.Project
+	err := s.inTx(func(tx *sql.Tx) error {
+		var err error
+		project, err = trashProject(tx, id, version)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// trashProject moves a project to the trash after checkVersion and returns
+// the trashed project
+func trashProject(tx *sql.Tx, id string, version int64) (*models.Project, error) {
+	if err := checkVersion(tx, id, version); err != nil {
+		return nil, err
+	}
+	next, err := nextVersion(tx)
+	if err != nil {
+		return nil, err
+	}
+	project, err := scanProject(tx.QueryRow(`SELECT `+projectColumns+` FROM projects WHERE id = ?`, id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project: %w", err)
+	}
+	project = trashed(project, next)
+	if err := insertTrashed(tx, project); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(`DELETE FROM projects WHERE id = ?`, id); err != nil {
+		return nil, fmt.Errorf("failed to delete project: %w", err)
+	}
+	return project, nil
+}
+
+// Apply performs writes atomically in a single transaction
+func (s *SQLiteStore) Apply(writes []Write) ([]Change, error) {
+	var changes []Change
+	err := s.inTx(func(tx *sql.Tx) error {
+		changes = make([]Change, 0, len(writes))
+		for _, w := range writes {
+			version, exists, err := storedVersion(tx, w.Project.ID)
+			if err != nil {
+				return err
+			}
+			if err := w.check(exists, version); err != nil {
+				return err
+			}
+			if w.Delete {
+				project, err := trashProject(tx, w.Project.ID, 0)
+				if err != nil {
+					return err
+				}
+				changes = append(changes, Change{ID: project.ResourceVersion, Type: ChangeDeleted, Project: project})
+				continue
+			}
+			created, err := putProject(tx, w.Project)
+			if err != nil {
+				return err
+			}
+			changeType := ChangeUpdated
+			if created {
+				changeType = ChangeCreated
+			}
+			changes = append(changes, Change{ID: w.Project.ResourceVersion, Type: changeType, Project: w.Project})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// Trash returns the projects in the trash, most recently deleted first
+func (s *SQLiteStore) Trash() ([]*models.Project, error) {
+	rows, err := s.db.Query(`SELECT ` + trashColumns + ` FROM trash ORDER BY deleted_at DESC, id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trash: %w", err)
+	}
+	defer rows.Close()
+
+	projects := make([]*models.Project, 0)
+	for rows.Next() {
+		p, err := scanTrashed(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan project: %w", err)
+		}
+		projects = append(projects, p)
+	}
+	return projects, rows.Err()
+}
+
+// Restore moves a project out of the trash
+func (s *SQLiteStore) Restore(id string, version int64) (*models.Project, error) {
+	var project *models.Project
+	err := s.inTx(func(tx *sql.Tx) error {
+		existing, err := getTrashed(tx, id, version)
+		if err != nil {
+			return err
+		}
+		next, err := nextVersion(tx)
+		if err != nil {
+			return err
+		}
+		project = restored(existing, next)
+		if _, err := tx.Exec(`INSERT INTO projects (`+projectColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			project.ID, project.Name, project.Description, project.URL, project.Icon, project.Category, project.Status, project.ResourceVersion,
+			toUnixMilli(project.CreatedAt), toUnixMilli(project.UpdatedAt), project.UpdatedBy, project.ManagedBy); err != nil {
+			return fmt.Errorf("failed to restore project: %w", err)
+		}
+		return dropTrashed(tx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// Purge permanently deletes a project from the trash
+func (s *SQLiteStore) Purge(id string, version int64) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		if _, err := getTrashed(tx, id, version); err != nil {
+			return err
+		}
+		// Purges consume a version too, so a recreated project never reuses one
+		if _, err := nextVersion(tx); err != nil {
+			return err
+		}
+		return dropTrashed(tx, id)
+	})
+}
+
+// LoadRevisions returns the persisted revision history
+func (s *SQLiteStore) LoadRevisions() (map[string][]Revision, error) {
+	rows, err := s.db.Query(`SELECT project_id, revision, project, changes FROM revisions ORDER BY project_id, revision`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query revisions: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string][]Revision)
+	for rows.Next() {
+		var id string
+		var project, changes []byte
+		var r Revision
+		if err := rows.Scan(&id, &r.Revision, &project, &changes); err != nil {
+			return nil, fmt.Errorf("failed to scan revision: %w", err)
+		}
+		if err := json.Unmarshal(project, &r.Project); err != nil {
+			return nil, fmt.Errorf("failed to decode revision %d of project %s: %w", r.Revision, id, err)
+		}
+		if err := json.Unmarshal(changes, &r.Changes); err != nil {
+			return nil, fmt.Errorf("failed to decode revision %d of project %s: %w", r.Revision, id, err)
+		}
+		result[id] = append(result[id], r)
+	}
+	return result, rows.Err()
+}
+
+// AppendRevision persists a revision, keeping the newest keep of its project
+func (s *SQLiteStore) AppendRevision(revision Revision, keep int) error {
+	project, err := json.Marshal(revision.Project)
+	if err != nil {
+		return fmt.Errorf("failed to encode revision: %w", err)
+	}
+	changes, err := json.Marshal(revision.Changes)
+	if err != nil {
+		return fmt.Errorf("failed to encode revision: %w", err)
+	}
+	id := revision.Project.ID
+	return s.inTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`INSERT OR REPLACE INTO revisions (project_id, revision, project, changes) VALUES (?, ?, ?, ?)`,
+			id, revision.Revision, string(project), string(changes)); err != nil {
+			return fmt.Errorf("failed to write revision: %w", err)
+		}
+		if keep <= 0 {
+			return nil
+		}
+		if _, err := tx.Exec(`DELETE FROM revisions WHERE project_id = ? AND revision NOT IN (
+			SELECT revision FROM revisions WHERE project_id = ? ORDER BY revision DESC LIMIT ?)`, id, id, keep); err != nil {
+			return fmt.Errorf("failed to trim revisions: %w", err)
+		}
+		return nil
+	})
+}
+
+// DropRevisions deletes the persisted history of a project
+func (s *SQLiteStore) DropRevisions(id string) error {
+	if _, err := s.db.Exec(`DELETE FROM revisions WHERE project_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete revisions: %w", err)
+	}
+	return nil
+}
+
+// getTrashed returns a project in the trash after checking its version like
+// checkVersion
+func getTrashed(tx *sql.Tx, id string, version int64) (*models.Project, error) {
+	project, err := scanTrashed(tx.QueryRow(`SELECT `+trashColumns+` FROM trash WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trashed project: %w", err)
+	}
+	if version != 0 && project.ResourceVersion != version {
+		return nil, ErrVersionConflict
+	}
+	return project, nil
+}
+
+func insertTrashed(tx *sql.Tx, project *models.Project) error {
+	_, err := tx.Exec(`INSERT INTO trash (`+trashColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		project.ID, project.Name, project.Description, project.URL, project.Icon, project.Category, project.Status, project.ResourceVersion,
+		toUnixMilli(project.CreatedAt), toUnixMilli(project.UpdatedAt), project.UpdatedBy, project.ManagedBy, toUnixMilli(project.DeletedAt))
+	if err != nil {
+		return fmt.Errorf("failed to trash project: %w", err)
+	}
+	return nil
+}
+
+// dropTrashed removes any trashed project with the ID
+func dropTrashed(tx *sql.Tx, id string) error {
+	if _, err := tx.Exec(`DELETE FROM trash WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete trashed project: %w", err)
+	}
+	return nil
+}
+
+// inTx runs fn in a transaction, committing only if it returns nil
+func (s *SQLiteStore) inTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// storedVersion returns the resource version of a project and whether it exists
+func storedVersion(tx *sql.Tx, id string) (int64, bool, error) {
+	var version int64
+	err := tx.QueryRow(`SELECT resource_version FROM projects WHERE id = ?`, id).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to check project: %w", err)
+	}
+	return version, true, nil
+}
+
+// checkVersion verifies the project exists and, when version is non-zero,
+// that it is at that version
+func checkVersion(tx *sql.Tx, id string, version int64) error {
+	current, exists, err := storedVersion(tx, id)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrNotFound
+	}
+	if version != 0 && current != version {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+// nextVersion hands out the next resource version
+func nextVersion(tx *sql.Tx) (int64, error) {
+	var version int64
+	if err := tx.QueryRow(`UPDATE resource_version_seq SET value = value + 1 RETURNING value`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to allocate resource version: %w", err)
+	}
+	return version, nil
+}
+
+// writeProject assigns project a new resource version and server-managed
+// fields and inserts it, or updates the existing row when exists is true
+func writeProject(tx *sql.Tx, project *models.Project, exists bool) error {
+	version, err := nextVersion(tx)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		existing := &models.Project{}
+		var createdAt int64
+		if err := tx.QueryRow(`SELECT created_at, managed_by FROM projects WHERE id = ?`, project.ID).Scan(&createdAt, &existing.ManagedBy); err != nil {
+			return fmt.Errorf("failed to read project: %w", err)
+		}
+		existing.CreatedAt = fromUnixMilli(createdAt)
+		touch(project, existing)
+		_, err = tx.Exec(`UPDATE projects SET name = ?, description = ?, url = ?, icon = ?, category = ?, status = ?, resource_version = ?, updated_at = ?, updated_by = ? WHERE id = ?`,
+			project.Name, project.Description, project.URL, project.Icon, project.Category, project.Status, version,
+			toUnixMilli(project.UpdatedAt), project.UpdatedBy, project.ID)
+	} else {
+		touch(project, nil)
+		_, err = tx.Exec(`INSERT INTO projects (`+projectColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			project.ID, project.Name, project.Description, project.URL, project.Icon, project.Category, project.Status, version,
+			toUnixMilli(project.CreatedAt), toUnixMilli(project.UpdatedAt), project.UpdatedBy, project.ManagedBy)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write project: %w", err)
+	}
+	project.ResourceVersion = version
+	return nil
+}
@@ -0,0 +1,169 @@
+package store
+
+import (
+	"0xhub/backend/internal/models"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func newTestSQLiteStore(t *testing.T) *SQLiteStore {
+	t.Helper()
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteStore() failed: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestSQLiteStore_CRUD(t *testing.T) {
+	store := newTestSQLiteStore(t)
+	project := &models.Project{
+		ID:          "test-1",
+		Name:        "Test Project",
+		Description: "A test project",
+		URL:         "https://test.com",
+		Category:    "Testing",
+		Status:      "active",
+	}
+
+	if err := store.Create(project); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	retrieved, err := store.GetByID("test-1")
+	if err != nil {
+		t.Fatalf("GetByID failed: %v", err)
+	}
+	if *retrieved != *project {
+		t.Fatalf("Expected %+v, got %+v", project, retrieved)
+	}
+
+	updated := &models.Project{ID: "test-1", Name: "Updated Project", URL: "https://updated.com"}
+	if err := store.Update(updated); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	retrieved, _ = store.GetByID("test-1")
+	if retrieved.Name != "Updated Project" {
+		t.Fatalf("Expected Name 'Updated Project', got %s", retrieved.Name)
+	}
+
+	projects, err := store.GetAll()
+	if err != nil {
+		t.Fatalf("GetAll failed: %v", err)
+	}
+	if len(projects) != 1 {
+		t.Fatalf("Expected 1 project, got %d", len(projects))
+	}
+
+	if _, err := store.Delete("test-1", 0); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := store.GetByID("test-1"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound after deletion, got %v", err)
+	}
+}
+
+func TestSQLiteStore_NotFound(t *testing.T) {
+	store := newTestSQLiteStore(t)
+
+	if _, err := store.GetByID("non-existent"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound from GetByID, got %v", err)
+	}
+	if err := store.Update(&models.Project{ID: "non-existent"}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound from Update, got %v", err)
+	}
+	if _, err := store.Delete("non-existent", 0); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound from Delete, got %v", err)
+	}
+}
+
+func TestSQLiteStore_CreateDuplicateAndPut(t *testing.T) {
+	store := newTestSQLiteStore(t)
+	store.Create(&models.Project{ID: "test-1", Name: "Original", URL: "https://test.com"})
+
+	if err := store.Create(&models.Project{ID: "test-1", Name: "Duplicate"}); !errors.Is(err, ErrAlreadyExists) {
+		t.Fatalf("Expected ErrAlreadyExists, got %v", err)
+	}
+
+	created, err := store.Put(&models.Project{ID: "test-1", Name: "Replaced", URL: "https://test.com"})
+	if err != nil || created {
+		t.Fatalf("Expected Put to replace, got created=%v err=%v", created, err)
+	}
+	created, err = store.Put(&models.Project{ID: "test-2", Name: "New", URL: "https://test.com"})
+	if err != nil || !created {
+		t.Fatalf("Expected Put to create, got created=%v err=%v", created, err)
+	}
+
+	retrieved, _ := store.GetByID("test-1")
+	if retrieved.Name != "Replaced" {
+		t.Fatalf("Expected Name 'Replaced', got %s", retrieved.Name)
+	}
+}
+
+func TestSQLiteStore_PersistsAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	store, err := NewSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore() failed: %v", err)
+	}
+	if err := store.Create(&models.Project{ID: "test-1", Name: "Test Project", URL: "https://test.com"}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	store.Close()
+
+	// Reopening runs migrations again, which must be a no-op
+	store, err = NewSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("Reopening store failed: %v", err)
+	}
+	defer store.Close()
+
+	if _, err := store.GetByID("test-1"); err != nil {
+		t.Fatalf("Project should survive reopening the database: %v", err)
+	}
+}
+
+func TestSQLiteStore_MigratesExistingDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	// Build a database at schema version 1 holding one project
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE schema_migrations (version INTEGER PRIMARY KEY)`,
+		migrations[0],
+		`INSERT INTO schema_migrations (version) VALUES (1)`,
+		`INSERT INTO projects (id, name, url) VALUES ('old', 'Old Project', 'https://old.com')`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("Failed to prepare database: %v", err)
+		}
+	}
+	db.Close()
+
+	store, err := NewSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("Migrating database failed: %v", err)
+	}
+	defer store.Close()
+
+	old, err := store.GetByID("old")
+	if err != nil {
+		t.Fatalf("Existing project should survive migration: %v", err)
+	}
+	if old.ResourceVersion == 0 {
+		t.Fatal("Existing project should be assigned a resource version")
+	}
+
+	p := &models.Project{ID: "new", Name: "New Project", URL: "https://new.com"}
+	store.Create(p)
+	if p.ResourceVersion <= old.ResourceVersion {
+		t.Fatalf("Expected new version above %d, got %d", old.ResourceVersion, p.ResourceVersion)
+	}
+}
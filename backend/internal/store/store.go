@@ -1,26 +1,123 @@
 package store
 
 import (
+	"errors"
+	"sort"
 	"sync"
+	"time"
 
 	"0xhub/backend/internal/models"
 )
 
-// Store is an in-memory store for projects
-type Store struct {
+var (
+	// ErrNotFound is returned when a project does not exist in the store
+	ErrNotFound = errors.New("project not found")
+	// ErrAlreadyExists is returned when creating a project whose ID is taken
+	ErrAlreadyExists = errors.New("project already exists")
+	// ErrVersionConflict is returned when a write's expected resource version
+	// does not match the stored project
+	ErrVersionConflict = errors.New("project resource version conflict")
+)
+
+// Write is a single write in a batch passed to Store.Apply
+type Write struct {
+	// Project is created or replaced, or moved to the trash when Delete is
+	// set, in which case only its ID is used
+	Project *models.Project
+	Delete  bool
+
+	// Version, when non-zero, must be the ResourceVersion of the live
+	// project with the ID, or the batch fails with ErrVersionConflict
+	Version int64
+	// Create makes a Put fail the batch with ErrAlreadyExists when a live
+	// project has the ID
+	Create bool
+}
+
+// check verifies w's conditions against the live project with its ID,
+// given whether it exists and its resource version
+func (w Write) check(exists bool, version int64) error {
+	if w.Create && !w.Delete && exists {
+		return ErrAlreadyExists
+	}
+	if w.Version != 0 {
+		if !exists {
+			return ErrNotFound
+		}
+		if version != w.Version {
+			return ErrVersionConflict
+		}
+	}
+	return nil
+}
+
+// Store is the persistence interface for projects.
+//
+// Every write assigns the project a new ResourceVersion and UpdatedAt, and
+// keeps the CreatedAt and ManagedBy of the project it replaces. These are set
+// on the project passed in; UpdatedBy is stored as given. Deleted projects
+// are kept in a trash until they are restored or purged.
+type Store interface {
+	// GetAll returns all projects
+	GetAll() ([]*models.Project, error)
+	// List returns a filtered, sorted page of projects
+	List(opts ListOptions) (*ListResult, error)
+	// GetByID returns a project by ID, or ErrNotFound
+	GetByID(id string) (*models.Project, error)
+	// Create creates a new project, or returns ErrAlreadyExists
+	Create(project *models.Project) error
+	// Put creates or replaces a project, reporting whether it was created
+	Put(project *models.Project) (created bool, err error)
+	// Update updates an existing project, or returns ErrNotFound. A non-zero
+	// ResourceVersion must match the stored one, or ErrVersionConflict is
+	// returned.
+	Update(project *models.Project) error
+	// Delete moves a project to the trash, or returns ErrNotFound. A non-zero
+	// version must match the stored one, or ErrVersionConflict is returned.
+	// The trashed project is given a new ResourceVersion and its DeletedAt,
+	// and returned.
+	Delete(id string, version int64) (*models.Project, error)
+
+	// Trash returns the projects in the trash, most recently deleted first.
+	// Trashed projects are left out of every other read, and creating a
+	// project with the ID of a trashed one replaces it.
+	Trash() ([]*models.Project, error)
+	// Restore moves a project out of the trash and returns it, or returns
+	// ErrNotFound. A non-zero version must match the trashed one, or
+	// ErrVersionConflict is returned.
+	Restore(id string, version int64) (*models.Project, error)
+	// Purge permanently deletes a project from the trash, or returns
+	// ErrNotFound. A non-zero version must match the trashed one, or
+	// ErrVersionConflict is returned.
+	Purge(id string, version int64) error
+
+	// Apply performs writes in order as a single atomic operation: either
+	// every write succeeds or none takes effect. Each write is a Put, or a
+	// Delete of a live project that fails with ErrNotFound otherwise, and
+	// fails when its Version or Create condition does not hold. It returns
+	// the change each write made.
+	Apply(writes []Write) ([]Change, error)
+}
+
+// MemoryStore is an in-memory store for projects
+type MemoryStore struct {
 	mu       sync.RWMutex
 	projects map[string]*models.Project
+	trash    map[string]*models.Project
+	// version is the last resource version handed out
+	version int64
 }
 
 // NewStore creates a new in-memory store
-func NewStore() *Store {
-	return &Store{
+func NewStore() *MemoryStore {
+	return &MemoryStore{
 		projects: make(map[string]*models.Project),
+		trash:    make(map[string]*models.Project),
 	}
 }
 
 // GetAll returns all projects
-func (s *Store) GetAll() []*models.Project {
+func (s *MemoryStore) GetAll() ([]*models.Project, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -28,46 +125,322 @@ func (s *Store) GetAll() []*models.Project {
 	for _, p := range s.projects {
 		projects = append(projects, p)
 	}
-	return projects
+	return projects, nil
+}
+
+// List returns a filtered, sorted page of projects
+func (s *MemoryStore) List(opts ListOptions) (*ListResult, error) {
+	projects, _ := s.GetAll()
+	return listProjects(projects, opts)
 }
 
 // GetByID returns a project by ID
-func (s *Store) GetByID(id string) (*models.Project, bool) {
+func (s *MemoryStore) GetByID(id string) (*models.Project, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	project, exists := s.projects[id]
-	return project, exists
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return project, nil
 }
 
 // Create creates a new project
-func (s *Store) Create(project *models.Project) {
+func (s *MemoryStore) Create(project *models.Project) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if _, exists := s.projects[project.ID]; exists {
+		return ErrAlreadyExists
+	}
+	touch(project, nil)
+	s.version++
+	project.ResourceVersion = s.version
 	s.projects[project.ID] = project
+	delete(s.trash, project.ID)
+	return nil
+}
+
+// Put creates or replaces a project
+func (s *MemoryStore) Put(project *models.Project) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, exists := s.projects[project.ID]
+	touch(project, existing)
+	s.version++
+	project.ResourceVersion = s.version
+	s.projects[project.ID] = project
+	delete(s.trash, project.ID)
+	return !exists, nil
 }
 
 // Update updates an existing project
-func (s *Store) Update(project *models.Project) bool {
+func (s *MemoryStore) Update(project *models.Project) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, exists := s.projects[project.ID]; !exists {
-		return false
+	if err := s.checkLocked(project.ID, project.ResourceVersion); err != nil {
+		return err
 	}
+	touch(project, s.projects[project.ID])
+	s.version++
+	project.ResourceVersion = s.version
 	s.projects[project.ID] = project
-	return true
+	return nil
+}
+
+// Delete moves a project to the trash
+func (s *MemoryStore) Delete(id string, version int64) (*models.Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkLocked(id, version); err != nil {
+		return nil, err
+	}
+	s.version++
+	project := trashed(s.projects[id], s.version)
+	s.trash[id] = project
+	delete(s.projects, id)
+	return project, nil
+}
+
+// Trash returns the projects in the trash, most recently deleted first
+func (s *MemoryStore) Trash() ([]*models.Project, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	projects := make([]*models.Project, 0, len(s.trash))
+	for _, p := range s.trash {
+		projects = append(projects, p)
+	}
+	sortTrash(projects)
+	return projects, nil
+}
+
+// Restore moves a project out of the trash
+func (s *MemoryStore) Restore(id string, version int64) (*models.Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := checkTrashed(s.trash, id, version); err != nil {
+		return nil, err
+	}
+	s.version++
+	project := restored(s.trash[id], s.version)
+	s.projects[id] = project
+	delete(s.trash, id)
+	return project, nil
+}
+
+// Purge permanently deletes a project from the trash
+func (s *MemoryStore) Purge(id string, version int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := checkTrashed(s.trash, id, version); err != nil {
+		return err
+	}
+	// Purges consume a version too, so a recreated project never reuses one
+	s.version++
+	delete(s.trash, id)
+	return nil
+}
+
+// Apply performs writes atomically
+func (s *MemoryStore) Apply(writes []Write) ([]Change, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changes, err := planWrites(writes, s.version+1, func(id string) *models.Project {
+		return s.projects[id]
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, change := range changes {
+		s.loadLocked(change.Project)
+	}
+	return changes, nil
+}
+
+// planWrites works out the changes a batch of writes makes without applying
+// them, handing out versions from next onwards. get returns the stored live
+// project with an ID, or nil.
+func planWrites(writes []Write, next int64, get func(id string) *models.Project) ([]Change, error) {
+	// staged holds the projects earlier writes in the batch left live, or nil
+	// for those they trashed
+	staged := make(map[string]*models.Project)
+	current := func(id string) *models.Project {
+		if p, ok := staged[id]; ok {
+			return p
+		}
+		return get(id)
+	}
+
+	changes := make([]Change, 0, len(writes))
+	for _, w := range writes {
+		existing := current(w.Project.ID)
+		var version int64
+		if existing != nil {
+			version = existing.ResourceVersion
+		}
+		if err := w.check(existing != nil, version); err != nil {
+			return nil, err
+		}
+		if w.Delete {
+			if existing == nil {
+				return nil, ErrNotFound
+			}
+			project := trashed(existing, next)
+			changes = append(changes, Change{ID: next, Type: ChangeDeleted, Project: project})
+			staged[project.ID] = nil
+		} else {
+			touch(w.Project, existing)
+			w.Project.ResourceVersion = next
+			changeType := ChangeUpdated
+			if existing == nil {
+				changeType = ChangeCreated
+			}
+			changes = append(changes, Change{ID: next, Type: changeType, Project: w.Project})
+			staged[w.Project.ID] = w.Project
+		}
+		next++
+	}
+	return changes, nil
+}
+
+// touch sets the server-managed fields of project before it is written.
+// existing is the stored project it replaces, or nil for a new project.
+func touch(project, existing *models.Project) {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	if existing != nil {
+		project.CreatedAt = existing.CreatedAt
+		project.ManagedBy = existing.ManagedBy
+	} else {
+		project.CreatedAt = now
+	}
+	project.UpdatedAt = now
+	project.DeletedAt = time.Time{}
 }
 
-// Delete deletes a project by ID
-func (s *Store) Delete(id string) bool {
+// trashed returns a copy of project moved to the trash at version
+func trashed(project *models.Project, version int64) *models.Project {
+	p := *project
+	p.ResourceVersion = version
+	p.DeletedAt = time.Now().UTC().Truncate(time.Millisecond)
+	return &p
+}
+
+// restored returns a copy of a trashed project brought back at version
+func restored(project *models.Project, version int64) *models.Project {
+	p := *project
+	touch(&p, project)
+	p.ResourceVersion = version
+	return &p
+}
+
+// checkTrashed verifies the project is in trash and, when version is
+// non-zero, that it is at that version
+func checkTrashed(trash map[string]*models.Project, id string, version int64) error {
+	project, exists := trash[id]
+	if !exists {
+		return ErrNotFound
+	}
+	if version != 0 && project.ResourceVersion != version {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+// sortTrash orders trashed projects most recently deleted first
+func sortTrash(projects []*models.Project) {
+	sort.Slice(projects, func(i, j int) bool {
+		if !projects[i].DeletedAt.Equal(projects[j].DeletedAt) {
+			return projects[i].DeletedAt.After(projects[j].DeletedAt)
+		}
+		return projects[i].ID < projects[j].ID
+	})
+}
+
+// checkLocked verifies the project exists and, when version is non-zero,
+// that it is at that version. Callers must hold s.mu.
+func (s *MemoryStore) checkLocked(id string, version int64) error {
+	current, exists := s.projects[id]
+	if !exists {
+		return ErrNotFound
+	}
+	if version != 0 && current.ResourceVersion != version {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+// check is checkLocked for callers that serialize writes themselves
+func (s *MemoryStore) check(id string, version int64) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.checkLocked(id, version)
+}
+
+// nextVersion returns the version the next write will be assigned
+func (s *MemoryStore) nextVersion() int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.version + 1
+}
+
+// load stores project as-is, keeping its resource version, in the trash if
+// it has a DeletedAt and as a live project otherwise. It is used to replay
+// persisted state.
+func (s *MemoryStore) load(project *models.Project) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, exists := s.projects[id]; !exists {
-		return false
+	s.loadLocked(project)
+}
+
+// loadLocked is load for callers that hold s.mu
+func (s *MemoryStore) loadLocked(project *models.Project) {
+	if project.DeletedAt.IsZero() {
+		s.projects[project.ID] = project
+		delete(s.trash, project.ID)
+	} else {
+		s.trash[project.ID] = project
+		delete(s.projects, project.ID)
 	}
+	s.version = max(s.version, project.ResourceVersion)
+}
+
+// remove permanently deletes a project while replaying persisted state,
+// recording the version the delete consumed
+func (s *MemoryStore) remove(id string, version int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	delete(s.projects, id)
-	return true
+	delete(s.trash, id)
+	s.version = max(s.version, version)
+}
+
+// getTrashed returns a project in the trash after checkTrashed
+func (s *MemoryStore) getTrashed(id string, version int64) (*models.Project, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if err := checkTrashed(s.trash, id, version); err != nil {
+		return nil, err
+	}
+	return s.trash[id], nil
+}
+
+// observeVersion records that version has already been handed out
+func (s *MemoryStore) observeVersion(version int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.version = max(s.version, version)
 }
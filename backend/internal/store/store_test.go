@@ -2,6 +2,7 @@ package store
 
 import (
 	"0xhub/backend/internal/models"
+	"errors"
 	"testing"
 )
 
@@ -33,8 +34,8 @@ func TestStore_Create(t *testing.T) {
 		t.Fatalf("Expected 1 project, got %d", len(store.projects))
 	}
 
-	retrieved, exists := store.GetByID("test-1")
-	if !exists {
+	retrieved, err := store.GetByID("test-1")
+	if err != nil {
 		t.Fatal("Project should exist after creation")
 	}
 	if retrieved.ID != project.ID {
@@ -45,6 +46,40 @@ func TestStore_Create(t *testing.T) {
 	}
 }
 
+func TestStore_CreateDuplicate(t *testing.T) {
+	store := NewStore()
+	store.Create(&models.Project{ID: "test-1", Name: "Original"})
+
+	err := store.Create(&models.Project{ID: "test-1", Name: "Duplicate"})
+	if !errors.Is(err, ErrAlreadyExists) {
+		t.Fatalf("Expected ErrAlreadyExists, got %v", err)
+	}
+
+	retrieved, _ := store.GetByID("test-1")
+	if retrieved.Name != "Original" {
+		t.Fatalf("Duplicate create should not overwrite, got %s", retrieved.Name)
+	}
+}
+
+func TestStore_Put(t *testing.T) {
+	store := NewStore()
+
+	created, err := store.Put(&models.Project{ID: "test-1", Name: "Created"})
+	if err != nil || !created {
+		t.Fatalf("Expected first Put to create, got created=%v err=%v", created, err)
+	}
+
+	created, err = store.Put(&models.Project{ID: "test-1", Name: "Replaced"})
+	if err != nil || created {
+		t.Fatalf("Expected second Put to replace, got created=%v err=%v", created, err)
+	}
+
+	retrieved, _ := store.GetByID("test-1")
+	if retrieved.Name != "Replaced" {
+		t.Fatalf("Expected Name 'Replaced', got %s", retrieved.Name)
+	}
+}
+
 func TestStore_GetByID(t *testing.T) {
 	store := NewStore()
 	project := &models.Project{
@@ -57,8 +92,8 @@ func TestStore_GetByID(t *testing.T) {
 	store.Create(project)
 
 	// Test existing project
-	retrieved, exists := store.GetByID("test-1")
-	if !exists {
+	retrieved, err := store.GetByID("test-1")
+	if err != nil {
 		t.Fatal("Project should exist")
 	}
 	if retrieved.ID != project.ID {
@@ -66,8 +101,8 @@ func TestStore_GetByID(t *testing.T) {
 	}
 
 	// Test non-existent project
-	_, exists = store.GetByID("non-existent")
-	if exists {
+	_, err = store.GetByID("non-existent")
+	if err == nil {
 		t.Fatal("Non-existent project should not exist")
 	}
 }
@@ -76,7 +111,7 @@ func TestStore_GetAll(t *testing.T) {
 	store := NewStore()
 
 	// Test empty store
-	projects := store.GetAll()
+	projects, _ := store.GetAll()
 	if len(projects) != 0 {
 		t.Fatalf("Expected 0 projects, got %d", len(projects))
 	}
@@ -90,7 +125,7 @@ func TestStore_GetAll(t *testing.T) {
 	store.Create(project2)
 	store.Create(project3)
 
-	projects = store.GetAll()
+	projects, _ = store.GetAll()
 	if len(projects) != 3 {
 		t.Fatalf("Expected 3 projects, got %d", len(projects))
 	}
@@ -124,13 +159,13 @@ func TestStore_Update(t *testing.T) {
 		URL:         "https://updated.com",
 	}
 
-	success := store.Update(updated)
-	if !success {
+	err := store.Update(updated)
+	if err != nil {
 		t.Fatal("Update should succeed for existing project")
 	}
 
-	retrieved, exists := store.GetByID("test-1")
-	if !exists {
+	retrieved, err := store.GetByID("test-1")
+	if err != nil {
 		t.Fatal("Project should still exist after update")
 	}
 	if retrieved.Name != "Updated Project" {
@@ -139,8 +174,8 @@ func TestStore_Update(t *testing.T) {
 
 	// Try to update non-existent project
 	nonExistent := &models.Project{ID: "non-existent", Name: "Non Existent"}
-	success = store.Update(nonExistent)
-	if success {
+	err = store.Update(nonExistent)
+	if err == nil {
 		t.Fatal("Update should fail for non-existent project")
 	}
 }
@@ -157,13 +192,13 @@ func TestStore_Delete(t *testing.T) {
 	store.Create(project)
 
 	// Delete existing project
-	success := store.Delete("test-1")
-	if !success {
+	_, err := store.Delete("test-1", 0)
+	if err != nil {
 		t.Fatal("Delete should succeed for existing project")
 	}
 
-	_, exists := store.GetByID("test-1")
-	if exists {
+	_, err = store.GetByID("test-1")
+	if err == nil {
 		t.Fatal("Project should not exist after deletion")
 	}
 
@@ -172,8 +207,8 @@ func TestStore_Delete(t *testing.T) {
 	}
 
 	// Try to delete non-existent project
-	success = store.Delete("non-existent")
-	if success {
+	_, err = store.Delete("non-existent", 0)
+	if err == nil {
 		t.Fatal("Delete should fail for non-existent project")
 	}
 }
@@ -215,8 +250,8 @@ func TestStore_ConcurrentAccess(t *testing.T) {
 	<-done
 
 	// Verify final state
-	_, exists := store.GetByID("concurrent-1")
-	if !exists {
+	_, err := store.GetByID("concurrent-1")
+	if err != nil {
 		t.Fatal("Project should exist after concurrent operations")
 	}
 }
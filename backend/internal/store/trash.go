@@ -0,0 +1,67 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"0xhub/backend/internal/models"
+)
+
+// DefaultTrashRetention is how long deleted projects stay in the trash by
+// default before they are purged
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+// PurgerActor names the trash purger where a write records who made it
+const PurgerActor = "trash-purger"
+
+// PurgeTrash permanently deletes the projects trashed before cutoff and
+// returns how many were purged. A project restored or replaced meanwhile is
+// left alone. purged, if not nil, is called with each purged project.
+func PurgeTrash(s Store, cutoff time.Time, purged func(*models.Project)) (int, error) {
+	trash, err := s.Trash()
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, p := range trash {
+		if !p.DeletedAt.Before(cutoff) {
+			continue
+		}
+		err := s.Purge(p.ID, p.ResourceVersion)
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrVersionConflict) {
+			continue
+		}
+		if err != nil {
+			return n, err
+		}
+		n++
+		if purged != nil {
+			purged(p)
+		}
+	}
+	return n, nil
+}
+
+// RunPurger purges projects that have been in the trash for longer than
+// retention every interval until ctx is done, calling purged like PurgeTrash
+func RunPurger(ctx context.Context, s Store, retention, interval time.Duration, purged func(*models.Project)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := PurgeTrash(s, time.Now().Add(-retention), purged)
+		if err != nil {
+			log.Println("Failed to purge trash:", err)
+		} else if n > 0 {
+			log.Println("Purged", n, "projects from the trash")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
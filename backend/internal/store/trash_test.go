@@ -0,0 +1,200 @@
+package store
+
+import (
+	"errors"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"0xhub/backend/internal/models"
+)
+
+func TestStore_SoftDelete(t *testing.T) {
+	for storeName, s := range allStores(t) {
+		t.Run(storeName, func(t *testing.T) {
+			p := &models.Project{ID: "1", Name: "Kubernetes", Status: "active"}
+			if err := s.Create(p); err != nil {
+				t.Fatalf("Create failed: %v", err)
+			}
+			deleted, err := s.Delete("1", 0)
+			if err != nil {
+				t.Fatalf("Delete failed: %v", err)
+			}
+			if deleted.Name != "Kubernetes" || deleted.DeletedAt.IsZero() || deleted.ResourceVersion <= p.ResourceVersion {
+				t.Fatalf("Expected Delete to return the trashed project, got %+v", deleted)
+			}
+
+			// Trashed projects are left out of every read
+			if _, err := s.GetByID("1"); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Expected ErrNotFound for a trashed project, got %v", err)
+			}
+			if all, _ := s.GetAll(); len(all) != 0 {
+				t.Fatalf("Expected GetAll to skip the trash, got %d projects", len(all))
+			}
+			if result, _ := s.List(ListOptions{}); result.Total != 0 {
+				t.Fatalf("Expected List to skip the trash, got %d projects", result.Total)
+			}
+			if err := s.Update(&models.Project{ID: "1", Name: "Renamed"}); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Expected ErrNotFound updating a trashed project, got %v", err)
+			}
+			if _, err := s.Delete("1", 0); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Expected ErrNotFound deleting a trashed project, got %v", err)
+			}
+
+			trash, err := s.Trash()
+			if err != nil {
+				t.Fatalf("Trash failed: %v", err)
+			}
+			if len(trash) != 1 || trash[0].Name != "Kubernetes" || trash[0].DeletedAt.IsZero() {
+				t.Fatalf("Expected the trashed project with a DeletedAt, got %+v", trash)
+			}
+			trashedVersion := trash[0].ResourceVersion
+			if trashedVersion <= p.ResourceVersion {
+				t.Fatalf("Expected delete to assign a new version, got %d after %d", trashedVersion, p.ResourceVersion)
+			}
+
+			if _, err := s.Restore("1", p.ResourceVersion); !errors.Is(err, ErrVersionConflict) {
+				t.Fatalf("Expected ErrVersionConflict, got %v", err)
+			}
+			restored, err := s.Restore("1", trashedVersion)
+			if err != nil {
+				t.Fatalf("Restore failed: %v", err)
+			}
+			if !restored.DeletedAt.IsZero() || restored.ResourceVersion <= trashedVersion || !restored.CreatedAt.Equal(p.CreatedAt) {
+				t.Fatalf("Expected a live project at a new version keeping CreatedAt, got %+v", restored)
+			}
+			got, err := s.GetByID("1")
+			if err != nil || got.Name != "Kubernetes" {
+				t.Fatalf("Expected the restored project, got %+v (%v)", got, err)
+			}
+			if trash, _ := s.Trash(); len(trash) != 0 {
+				t.Fatalf("Expected an empty trash, got %+v", trash)
+			}
+			if _, err := s.Restore("1", 0); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Expected ErrNotFound restoring a live project, got %v", err)
+			}
+		})
+	}
+}
+
+func TestStore_PurgeAndReplaceTrashed(t *testing.T) {
+	for storeName, s := range allStores(t) {
+		t.Run(storeName, func(t *testing.T) {
+			for _, id := range []string{"1", "2"} {
+				if err := s.Create(&models.Project{ID: id, Name: "Project " + id}); err != nil {
+					t.Fatalf("Create failed: %v", err)
+				}
+				if _, err := s.Delete(id, 0); err != nil {
+					t.Fatalf("Delete failed: %v", err)
+				}
+			}
+
+			if err := s.Purge("1", 0); err != nil {
+				t.Fatalf("Purge failed: %v", err)
+			}
+			if err := s.Purge("1", 0); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Expected ErrNotFound purging twice, got %v", err)
+			}
+
+			// Creating a project with a trashed ID replaces the trashed one
+			p := &models.Project{ID: "2", Name: "Recreated"}
+			if err := s.Create(p); err != nil {
+				t.Fatalf("Create over a trashed project failed: %v", err)
+			}
+			if trash, _ := s.Trash(); len(trash) != 0 {
+				t.Fatalf("Expected an empty trash, got %+v", trash)
+			}
+			if err := s.Purge("2", 0); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Expected ErrNotFound purging a live project, got %v", err)
+			}
+		})
+	}
+}
+
+func TestPurgeTrash(t *testing.T) {
+	for storeName, s := range allStores(t) {
+		t.Run(storeName, func(t *testing.T) {
+			for _, id := range []string{"1", "2"} {
+				if err := s.Create(&models.Project{ID: id, Name: "Project " + id}); err != nil {
+					t.Fatalf("Create failed: %v", err)
+				}
+			}
+			s.Delete("1", 0)
+			cutoff := time.Now().Add(time.Second)
+			s.Delete("2", 0)
+
+			var purged []string
+			record := func(p *models.Project) { purged = append(purged, p.ID) }
+			if n, err := PurgeTrash(s, time.Now().Add(-time.Hour), record); err != nil || n != 0 {
+				t.Fatalf("Expected nothing past retention, purged %d (%v)", n, err)
+			}
+			// Both were deleted before the cutoff
+			if n, err := PurgeTrash(s, cutoff, record); err != nil || n != 2 {
+				t.Fatalf("Expected 2 purged, got %d (%v)", n, err)
+			}
+			if sort.Strings(purged); len(purged) != 2 || purged[0] != "1" || purged[1] != "2" {
+				t.Fatalf("Expected both purged projects to be reported, got %v", purged)
+			}
+			if trash, _ := s.Trash(); len(trash) != 0 {
+				t.Fatalf("Expected an empty trash, got %+v", trash)
+			}
+		})
+	}
+}
+
+func TestStore_TrashSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	fileStore, err := NewFileStore(dir, 0)
+	if err != nil {
+		t.Fatalf("NewFileStore() failed: %v", err)
+	}
+	sqlitePath := filepath.Join(t.TempDir(), "test.db")
+	sqliteStore, err := NewSQLiteStore(sqlitePath)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore() failed: %v", err)
+	}
+
+	reopen := map[string]func() (Store, error){
+		"file":   func() (Store, error) { return NewFileStore(dir, 0) },
+		"sqlite": func() (Store, error) { return NewSQLiteStore(sqlitePath) },
+	}
+	for storeName, s := range map[string]interface {
+		Store
+		Close() error
+	}{"file": fileStore, "sqlite": sqliteStore} {
+		t.Run(storeName, func(t *testing.T) {
+			for _, id := range []string{"1", "2"} {
+				if err := s.Create(&models.Project{ID: id, Name: "Project " + id}); err != nil {
+					t.Fatalf("Create failed: %v", err)
+				}
+			}
+			s.Delete("1", 0)
+			s.Delete("2", 0)
+			s.Purge("2", 0)
+			want, _ := s.Trash()
+			s.Close()
+
+			reopened, err := reopen[storeName]()
+			if err != nil {
+				t.Fatalf("Reopen failed: %v", err)
+			}
+			defer reopened.(interface{ Close() error }).Close()
+
+			trash, err := reopened.Trash()
+			if err != nil {
+				t.Fatalf("Trash failed: %v", err)
+			}
+			if len(trash) != 1 || trash[0].ID != "1" || !trash[0].DeletedAt.Equal(want[0].DeletedAt) ||
+				trash[0].ResourceVersion != want[0].ResourceVersion {
+				t.Fatalf("Expected %+v after reopening, got %+v", want, trash)
+			}
+			if _, err := reopened.GetByID("1"); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Expected the project to stay trashed, got %v", err)
+			}
+			if _, err := reopened.Restore("1", 0); err != nil {
+				t.Fatalf("Restore after reopening failed: %v", err)
+			}
+		})
+	}
+}
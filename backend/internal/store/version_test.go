@@ -0,0 +1,75 @@
+package store
+
+import (
+	"0xhub/backend/internal/models"
+	"errors"
+	"testing"
+)
+
+func TestStore_ResourceVersions(t *testing.T) {
+	for storeName, s := range allStores(t) {
+		t.Run(storeName, func(t *testing.T) {
+			p1 := &models.Project{ID: "test-1", Name: "Project 1", URL: "https://test1.com"}
+			p2 := &models.Project{ID: "test-2", Name: "Project 2", URL: "https://test2.com"}
+			s.Create(p1)
+			s.Create(p2)
+			if p1.ResourceVersion == 0 || p2.ResourceVersion <= p1.ResourceVersion {
+				t.Fatalf("Expected increasing versions, got %d then %d", p1.ResourceVersion, p2.ResourceVersion)
+			}
+
+			stale := p1.ResourceVersion
+			updated := &models.Project{ID: "test-1", Name: "Updated", URL: "https://test1.com", ResourceVersion: stale}
+			if err := s.Update(updated); err != nil {
+				t.Fatalf("Update at current version failed: %v", err)
+			}
+			if updated.ResourceVersion <= p2.ResourceVersion {
+				t.Fatalf("Expected update to get a newer version, got %d", updated.ResourceVersion)
+			}
+			stored, _ := s.GetByID("test-1")
+			if stored.ResourceVersion != updated.ResourceVersion {
+				t.Fatalf("Stored version %d does not match returned version %d", stored.ResourceVersion, updated.ResourceVersion)
+			}
+
+			conflicting := &models.Project{ID: "test-1", Name: "Lost update", URL: "https://test1.com", ResourceVersion: stale}
+			if err := s.Update(conflicting); !errors.Is(err, ErrVersionConflict) {
+				t.Fatalf("Expected ErrVersionConflict for stale update, got %v", err)
+			}
+			if _, err := s.Delete("test-1", stale); !errors.Is(err, ErrVersionConflict) {
+				t.Fatalf("Expected ErrVersionConflict for stale delete, got %v", err)
+			}
+			if _, err := s.Delete("test-1", updated.ResourceVersion); err != nil {
+				t.Fatalf("Delete at current version failed: %v", err)
+			}
+
+			// A recreated project never reuses an earlier version
+			recreated := &models.Project{ID: "test-1", Name: "Recreated", URL: "https://test1.com"}
+			s.Create(recreated)
+			if recreated.ResourceVersion <= updated.ResourceVersion+1 {
+				t.Fatalf("Expected recreated project to skip the version consumed by delete, got %d", recreated.ResourceVersion)
+			}
+		})
+	}
+}
+
+func TestFileStore_VersionSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+
+	store, _ := NewFileStore(dir, 0)
+	store.Create(&models.Project{ID: "test-1", Name: "Project 1", URL: "https://test1.com"})
+	p := &models.Project{ID: "test-2", Name: "Project 2", URL: "https://test2.com"}
+	store.Create(p)
+	store.Delete("test-2", 0)
+	store.Close()
+
+	reopened, err := NewFileStore(dir, 0)
+	if err != nil {
+		t.Fatalf("Reopening store failed: %v", err)
+	}
+	defer reopened.Close()
+
+	next := &models.Project{ID: "test-3", Name: "Project 3", URL: "https://test3.com"}
+	reopened.Create(next)
+	if next.ResourceVersion <= p.ResourceVersion+1 {
+		t.Fatalf("Expected version after restart to exceed %d, got %d", p.ResourceVersion+1, next.ResourceVersion)
+	}
+}
@@ -0,0 +1,338 @@
+package store
+
+import (
+	"errors"
+	"sync"
+
+	"0xhub/backend/internal/closer"
+	"0xhub/backend/internal/models"
+)
+
+// ErrChangesExpired is returned when resuming a watch from a change that is
+// no longer retained, so changes since then may have been missed
+var ErrChangesExpired = errors.New("changes since the requested version are no longer available")
+
+// Change types
+const (
+	ChangeCreated = "created"
+	ChangeUpdated = "updated"
+	ChangeDeleted = "deleted"
+)
+
+// Default sizes of the change feed buffers
+const (
+	// DefaultChangeHistory is the number of recent changes kept for resuming
+	DefaultChangeHistory = 1000
+	// subscriptionBuffer is the number of changes a subscriber may fall
+	// behind by before it is dropped
+	subscriptionBuffer = 64
+)
+
+// Change is a single write to a project
+type Change struct {
+	// ID is the resource version the write assigned, so changes are ordered
+	// by ID
+	ID      int64           `json:"id"`
+	Type    string          `json:"type"`
+	Project *models.Project `json:"project"`
+	// Previous is the project before an update, so subscribers filtering
+	// changes can tell when a project stops matching. It is nil for
+	// creations and deletions, and only set on published changes.
+	Previous *models.Project `json:"-"`
+}
+
+// Watcher streams changes to projects
+type Watcher interface {
+	// Watch subscribes to changes after the change with ID since, or to new
+	// changes only when since is zero. It returns ErrChangesExpired when
+	// changes after since are no longer retained.
+	Watch(since int64) (*Subscription, error)
+}
+
+// Subscription receives changes from a WatchedStore
+type Subscription struct {
+	// C delivers changes in order. It is closed when the subscription is
+	// closed, or when the subscriber fell too far behind, after which it can
+	// resume from the last change it received.
+	C <-chan Change
+
+	c       chan Change
+	feed    *changeFeed
+	dropped bool
+}
+
+// Close unsubscribes and closes C
+func (s *Subscription) Close() {
+	s.feed.unsubscribe(s)
+}
+
+// Dropped reports whether C was closed because the subscriber fell behind.
+// It is only meaningful once C is closed.
+func (s *Subscription) Dropped() bool {
+	s.feed.mu.Lock()
+	defer s.feed.mu.Unlock()
+
+	return s.dropped
+}
+
+// changeFeed fans changes out to subscribers and keeps the most recent ones
+// for resuming. Publishing never blocks: a subscriber whose buffer is full
+// is dropped instead.
+type changeFeed struct {
+	mu          sync.Mutex
+	subscribers map[*Subscription]struct{}
+	history     []Change
+	size        int
+	// horizon is the newest version whose changes may have been missed: the
+	// last version before the feed started, or the last evicted change
+	horizon int64
+	// latest is the newest version the feed knows of
+	latest int64
+}
+
+func (f *changeFeed) publish(change Change) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.history) >= f.size {
+		f.horizon = f.history[0].ID
+		f.history = append(f.history[:0], f.history[1:]...)
+	}
+	f.history = append(f.history, change)
+	f.latest = change.ID
+
+	for sub := range f.subscribers {
+		select {
+		case sub.c <- change:
+		default:
+			sub.dropped = true
+			f.closeLocked(sub)
+		}
+	}
+}
+
+func (f *changeFeed) subscribe(since int64) (*Subscription, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if since != 0 && (since < f.horizon || since > f.latest) {
+		return nil, ErrChangesExpired
+	}
+
+	var backlog []Change
+	if since != 0 {
+		for _, change := range f.history {
+			if change.ID > since {
+				backlog = append(backlog, change)
+			}
+		}
+	}
+	c := make(chan Change, len(backlog)+subscriptionBuffer)
+	for _, change := range backlog {
+		c <- change
+	}
+	sub := &Subscription{C: c, c: c, feed: f}
+	f.subscribers[sub] = struct{}{}
+	return sub, nil
+}
+
+func (f *changeFeed) unsubscribe(sub *Subscription) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.closeLocked(sub)
+}
+
+func (f *changeFeed) closeLocked(sub *Subscription) {
+	if _, ok := f.subscribers[sub]; ok {
+		delete(f.subscribers, sub)
+		close(sub.c)
+	}
+}
+
+// subscriberCount returns the number of active subscriptions
+func (f *changeFeed) subscriberCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.subscribers)
+}
+
+// WatchedStore wraps a Store and publishes every successful write to
+// subscribers. A delete publishes the trashed project; restoring it from the
+// trash publishes a creation, and purges publish nothing.
+type WatchedStore struct {
+	Store
+	// mu serializes writers so changes are published in store order
+	mu   sync.Mutex
+	feed *changeFeed
+}
+
+// NewWatchedStore wraps inner, keeping historySize recent changes for
+// resuming watches, or DefaultChangeHistory when historySize is zero or less
+func NewWatchedStore(inner Store, historySize int) (*WatchedStore, error) {
+	if historySize <= 0 {
+		historySize = DefaultChangeHistory
+	}
+	projects, err := inner.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	trash, err := inner.Trash()
+	if err != nil {
+		return nil, err
+	}
+	// Changes up to the newest stored version happened before the feed
+	// started and cannot be resumed from
+	var latest int64
+	for _, p := range append(projects, trash...) {
+		latest = max(latest, p.ResourceVersion)
+	}
+	return &WatchedStore{
+		Store: inner,
+		feed: &changeFeed{
+			subscribers: make(map[*Subscription]struct{}),
+			size:        historySize,
+			horizon:     latest,
+			latest:      latest,
+		},
+	}, nil
+}
+
+// Create creates a new project and publishes its creation
+func (s *WatchedStore) Create(project *models.Project) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.Store.Create(project); err != nil {
+		return err
+	}
+	s.publish(ChangeCreated, project, nil)
+	return nil
+}
+
+// Put creates or replaces a project and publishes the change
+func (s *WatchedStore) Put(project *models.Project) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, err := s.previous(project.ID)
+	if err != nil {
+		return false, err
+	}
+	created, err := s.Store.Put(project)
+	if err != nil {
+		return false, err
+	}
+	if created {
+		s.publish(ChangeCreated, project, nil)
+	} else {
+		s.publish(ChangeUpdated, project, previous)
+	}
+	return created, nil
+}
+
+// Update updates an existing project and publishes the change
+func (s *WatchedStore) Update(project *models.Project) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, err := s.previous(project.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.Store.Update(project); err != nil {
+		return err
+	}
+	s.publish(ChangeUpdated, project, previous)
+	return nil
+}
+
+// Delete moves a project to the trash and publishes its deletion
+func (s *WatchedStore) Delete(id string, version int64) (*models.Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project, err := s.Store.Delete(id, version)
+	if err != nil {
+		return nil, err
+	}
+	s.publish(ChangeDeleted, project, nil)
+	return project, nil
+}
+
+// Restore moves a project out of the trash and publishes its creation
+func (s *WatchedStore) Restore(id string, version int64) (*models.Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project, err := s.Store.Restore(id, version)
+	if err != nil {
+		return nil, err
+	}
+	s.publish(ChangeCreated, project, nil)
+	return project, nil
+}
+
+// Apply performs writes atomically and publishes each change they make
+func (s *WatchedStore) Apply(writes []Write) ([]Change, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous := make(map[string]*models.Project)
+	for _, w := range writes {
+		if w.Delete {
+			continue
+		}
+		p, err := s.previous(w.Project.ID)
+		if err != nil {
+			return nil, err
+		}
+		previous[w.Project.ID] = p
+	}
+	changes, err := s.Store.Apply(writes)
+	if err != nil {
+		return nil, err
+	}
+	for _, change := range changes {
+		if change.Type == ChangeUpdated {
+			s.publish(change.Type, change.Project, previous[change.Project.ID])
+		} else {
+			s.publish(change.Type, change.Project, nil)
+		}
+	}
+	return changes, nil
+}
+
+// Watch subscribes to changes after since
+func (s *WatchedStore) Watch(since int64) (*Subscription, error) {
+	return s.feed.subscribe(since)
+}
+
+// Watchers returns the number of active subscriptions
+func (s *WatchedStore) Watchers() int {
+	return s.feed.subscriberCount()
+}
+
+// Close closes the watched store
+func (s *WatchedStore) Close() error {
+	return closer.Close(s.Store)
+}
+
+// previous returns the stored project with the given ID, or nil when there
+// is none. Writes are serialized by s.mu, so it is the state a write
+// replaces.
+func (s *WatchedStore) previous(id string) (*models.Project, error) {
+	project, err := s.Store.GetByID(id)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	return project, err
+}
+
+func (s *WatchedStore) publish(changeType string, project, previous *models.Project) {
+	// Subscribers share the change, so give them a copy the writer's caller
+	// cannot modify
+	p := *project
+	s.feed.publish(Change{ID: p.ResourceVersion, Type: changeType, Project: &p, Previous: previous})
+}
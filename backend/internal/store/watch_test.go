@@ -0,0 +1,192 @@
+package store
+
+import (
+	"errors"
+	"testing"
+
+	"0xhub/backend/internal/models"
+)
+
+func newTestWatchedStore(t *testing.T, historySize int) *WatchedStore {
+	t.Helper()
+	s, err := NewWatchedStore(NewStore(), historySize)
+	if err != nil {
+		t.Fatalf("NewWatchedStore() failed: %v", err)
+	}
+	return s
+}
+
+// drain reads the changes already delivered to sub
+func drain(sub *Subscription) []Change {
+	var changes []Change
+	for {
+		select {
+		case change, ok := <-sub.C:
+			if !ok {
+				return changes
+			}
+			changes = append(changes, change)
+		default:
+			return changes
+		}
+	}
+}
+
+func changeTypes(changes []Change) []string {
+	types := make([]string, 0, len(changes))
+	for _, c := range changes {
+		types = append(types, c.Type+":"+c.Project.ID)
+	}
+	return types
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestWatchedStore_PublishesWrites(t *testing.T) {
+	s := newTestWatchedStore(t, 0)
+	sub, err := s.Watch(0)
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	defer sub.Close()
+
+	s.Create(&models.Project{ID: "1", Name: "Kubernetes"})
+	s.Put(&models.Project{ID: "2", Name: "Docker"})
+	s.Put(&models.Project{ID: "2", Name: "Docker"})
+	s.Update(&models.Project{ID: "1", Name: "Renamed"})
+	s.Delete("1", 0)
+	s.Restore("1", 0)
+	s.Delete("2", 0)
+	s.Purge("2", 0)
+	// Failed writes publish nothing
+	s.Create(&models.Project{ID: "1", Name: "Duplicate"})
+	s.Update(&models.Project{ID: "missing"})
+
+	changes := drain(sub)
+	want := []string{"created:1", "created:2", "updated:2", "updated:1", "deleted:1", "created:1", "deleted:2"}
+	if got := changeTypes(changes); !equalStrings(got, want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := 1; i < len(changes); i++ {
+		if changes[i].ID <= changes[i-1].ID {
+			t.Fatalf("Expected increasing change IDs, got %d after %d", changes[i].ID, changes[i-1].ID)
+		}
+	}
+	if deleted := changes[4]; deleted.Project.DeletedAt.IsZero() || deleted.ID != deleted.Project.ResourceVersion {
+		t.Fatalf("Expected the trashed project, got %+v", deleted.Project)
+	}
+	// Updates carry the project they replaced
+	if updated := changes[3]; updated.Previous == nil || updated.Previous.Name != "Kubernetes" {
+		t.Fatalf("Expected the previous project on an update, got %+v", updated.Previous)
+	}
+	if created := changes[0]; created.Previous != nil {
+		t.Fatalf("Expected no previous project on a creation, got %+v", created.Previous)
+	}
+}
+
+func TestWatchedStore_Resume(t *testing.T) {
+	s := newTestWatchedStore(t, 3)
+	var versions []int64
+	for _, id := range []string{"1", "2", "3", "4"} {
+		p := &models.Project{ID: id, Name: "Project " + id}
+		s.Create(p)
+		versions = append(versions, p.ResourceVersion)
+	}
+
+	sub, err := s.Watch(versions[1])
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	defer sub.Close()
+	if got := changeTypes(drain(sub)); !equalStrings(got, []string{"created:3", "created:4"}) {
+		t.Fatalf("Expected the changes after the resumed one, got %v", got)
+	}
+	s.Create(&models.Project{ID: "5", Name: "Project 5"})
+	if got := changeTypes(drain(sub)); !equalStrings(got, []string{"created:5"}) {
+		t.Fatalf("Expected live changes after the backlog, got %v", got)
+	}
+
+	// The changes after the first have been evicted, so resuming from it fails
+	if _, err := s.Watch(versions[0]); !errors.Is(err, ErrChangesExpired) {
+		t.Fatalf("Expected ErrChangesExpired, got %v", err)
+	}
+	if _, err := s.Watch(1000); !errors.Is(err, ErrChangesExpired) {
+		t.Fatalf("Expected ErrChangesExpired for an unknown future change, got %v", err)
+	}
+}
+
+func TestWatchedStore_ResumeBeforeStart(t *testing.T) {
+	inner := NewStore()
+	inner.Create(&models.Project{ID: "1", Name: "Kubernetes"})
+	s, err := NewWatchedStore(inner, 0)
+	if err != nil {
+		t.Fatalf("NewWatchedStore() failed: %v", err)
+	}
+
+	// Changes made before the feed started cannot be replayed
+	if _, err := s.Watch(0); err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	p, _ := inner.GetByID("1")
+	if sub, err := s.Watch(p.ResourceVersion); err != nil {
+		t.Fatalf("Expected to resume from the newest stored version, got %v", err)
+	} else {
+		sub.Close()
+	}
+}
+
+func TestWatchedStore_SlowSubscriberIsDropped(t *testing.T) {
+	s := newTestWatchedStore(t, 0)
+	slow, err := s.Watch(0)
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	fast, err := s.Watch(0)
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	defer fast.Close()
+
+	// Writers never wait for a subscriber that stopped reading
+	received := 0
+	for i := 0; i < subscriptionBuffer*2; i++ {
+		if _, err := s.Put(&models.Project{ID: "1", Name: "Kubernetes"}); err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+		received += len(drain(fast))
+	}
+
+	if received != subscriptionBuffer*2 {
+		t.Fatalf("Expected the reading subscriber to get %d changes, got %d", subscriptionBuffer*2, received)
+	}
+	changes := drain(slow)
+	if len(changes) != subscriptionBuffer {
+		t.Fatalf("Expected the slow subscriber's buffered %d changes, got %d", subscriptionBuffer, len(changes))
+	}
+	if _, ok := <-slow.C; ok || !slow.Dropped() {
+		t.Fatal("Expected the slow subscriber to be dropped")
+	}
+	if s.Watchers() != 1 {
+		t.Fatalf("Expected 1 watcher left, got %d", s.Watchers())
+	}
+
+	// It can resume from the last change it received
+	resumed, err := s.Watch(changes[len(changes)-1].ID)
+	if err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+	defer resumed.Close()
+	if got := len(drain(resumed)); got != subscriptionBuffer {
+		t.Fatalf("Expected %d missed changes, got %d", subscriptionBuffer, got)
+	}
+}
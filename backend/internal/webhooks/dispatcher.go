@@ -0,0 +1,303 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"0xhub/backend/internal/store"
+)
+
+// Default delivery settings
+const (
+	DefaultWorkers        = 4
+	DefaultMaxAttempts    = 8
+	DefaultInitialBackoff = 5 * time.Second
+	DefaultMaxBackoff     = 15 * time.Minute
+	DefaultTimeout        = 10 * time.Second
+	// queueSize bounds the deliveries waiting for a worker
+	queueSize = 1024
+)
+
+// Options configures a Dispatcher. Zero values select the defaults.
+type Options struct {
+	// Workers is the number of deliveries sent concurrently
+	Workers int
+	// MaxAttempts is the number of tries before a delivery fails
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry, doubling for each
+	// retry after it up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Timeout bounds a single attempt
+	Timeout time.Duration
+	Client  *http.Client
+}
+
+// Dispatcher sends signed deliveries to the webhooks subscribed to each
+// project change, retrying failures with exponential backoff. Deliveries may
+// arrive out of order; their payload's changeId orders them.
+type Dispatcher struct {
+	registry *Registry
+	opts     Options
+	queue    chan *Delivery
+
+	// ctx is set by Run and stops pending retries
+	mu  sync.Mutex
+	ctx context.Context
+}
+
+// NewDispatcher creates a dispatcher for the webhooks in registry
+func NewDispatcher(registry *Registry, opts Options) *Dispatcher {
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultWorkers
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultMaxAttempts
+	}
+	if opts.InitialBackoff <= 0 {
+		opts.InitialBackoff = DefaultInitialBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = DefaultMaxBackoff
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: opts.Timeout}
+	}
+	return &Dispatcher{
+		registry: registry,
+		opts:     opts,
+		queue:    make(chan *Delivery, queueSize),
+		ctx:      context.Background(),
+	}
+}
+
+// Run delivers every change published by watcher until ctx is done. If the
+// dispatcher falls behind the change feed it resumes from the last change it
+// handled, so no change is skipped unless the feed no longer retains it.
+func (d *Dispatcher) Run(ctx context.Context, watcher store.Watcher) error {
+	d.mu.Lock()
+	d.ctx = ctx
+	d.mu.Unlock()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for range d.opts.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.work(ctx)
+		}()
+	}
+
+	var last int64
+	for {
+		sub, err := watcher.Watch(last)
+		if errors.Is(err, store.ErrChangesExpired) {
+			log.Printf("Webhook deliveries for changes after %d were missed", last)
+			sub, err = watcher.Watch(0)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to watch projects: %w", err)
+		}
+
+	changes:
+		for {
+			select {
+			case <-ctx.Done():
+				sub.Close()
+				return nil
+			case change, ok := <-sub.C:
+				if !ok {
+					break changes
+				}
+				last = change.ID
+				d.dispatch(change)
+			}
+		}
+	}
+}
+
+// Redeliver queues a new delivery of an earlier delivery's payload. It
+// returns ErrQueueFull rather than wait when the queue has no room.
+func (d *Dispatcher) Redeliver(webhookID, deliveryID string) (*Delivery, error) {
+	original, err := d.registry.Delivery(webhookID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	delivery := &Delivery{
+		ID:           newID(),
+		WebhookID:    webhookID,
+		Event:        original.Event,
+		Payload:      original.Payload,
+		Status:       DeliveryPending,
+		Attempts:     []Attempt{},
+		RedeliveryOf: original.ID,
+		CreatedAt:    time.Now().UTC(),
+	}
+	if !d.registry.addDelivery(delivery) {
+		return nil, ErrNotFound
+	}
+	// A worker owns the queued delivery, so the caller gets a copy
+	queued := delivery.clone()
+	select {
+	case d.queue <- delivery:
+	default:
+		d.registry.removeDelivery(webhookID, delivery.ID)
+		return nil, ErrQueueFull
+	}
+	return queued, nil
+}
+
+// dispatch queues a delivery of change to every subscribed webhook
+func (d *Dispatcher) dispatch(change store.Change) {
+	event, ok := eventTypes[change.Type]
+	if !ok {
+		return
+	}
+	webhooks := d.registry.subscribers(event)
+	if len(webhooks) == 0 {
+		return
+	}
+
+	now := time.Now().UTC()
+	payload, err := json.Marshal(Payload{
+		Event:    event,
+		ChangeID: change.ID,
+		Time:     now,
+		Project:  change.Project,
+	})
+	if err != nil {
+		log.Printf("Failed to encode webhook payload: %v", err)
+		return
+	}
+	for _, w := range webhooks {
+		delivery := &Delivery{
+			ID:        newID(),
+			WebhookID: w.ID,
+			Event:     event,
+			Payload:   payload,
+			Status:    DeliveryPending,
+			Attempts:  []Attempt{},
+			CreatedAt: now,
+		}
+		if d.registry.addDelivery(delivery) {
+			d.enqueue(delivery)
+		}
+	}
+}
+
+func (d *Dispatcher) enqueue(delivery *Delivery) {
+	d.mu.Lock()
+	ctx := d.ctx
+	d.mu.Unlock()
+
+	select {
+	case d.queue <- delivery:
+	case <-ctx.Done():
+	}
+}
+
+func (d *Dispatcher) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case delivery := <-d.queue:
+			d.attempt(ctx, delivery)
+		}
+	}
+}
+
+// attempt sends a delivery once and records the outcome, scheduling a retry
+// when it fails and attempts remain
+func (d *Dispatcher) attempt(ctx context.Context, delivery *Delivery) {
+	webhook, ok := d.registry.webhook(delivery.WebhookID)
+	if !ok {
+		// The webhook was deleted while the delivery was queued
+		return
+	}
+
+	result := Attempt{Time: time.Now().UTC()}
+	statusCode, err := d.send(ctx, webhook, delivery)
+	result.StatusCode = statusCode
+	if err == nil && (statusCode < 200 || statusCode > 299) {
+		err = fmt.Errorf("receiver responded with status %d", statusCode)
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	var retry time.Duration
+	delivery.Attempts = append(delivery.Attempts, result)
+	delivery.NextAttemptAt = time.Time{}
+	switch {
+	case err == nil:
+		delivery.Status = DeliverySucceeded
+	case len(delivery.Attempts) >= d.opts.MaxAttempts:
+		delivery.Status = DeliveryFailed
+		log.Printf("Webhook delivery %s to %s failed after %d attempts: %v",
+			delivery.ID, webhook.URL, len(delivery.Attempts), err)
+	default:
+		retry = d.backoff(len(delivery.Attempts))
+		delivery.NextAttemptAt = result.Time.Add(retry)
+	}
+
+	recorded := delivery.clone()
+	d.registry.updateDelivery(delivery.WebhookID, delivery.ID, func(d *Delivery) {
+		*d = *recorded
+	})
+	// Only schedule the retry once this worker is done with the delivery
+	if retry > 0 {
+		time.AfterFunc(retry, func() { d.enqueue(delivery) })
+	}
+}
+
+// send posts the delivery's payload, signed with the webhook's secret, and
+// returns the response status
+func (d *Dispatcher) send(ctx context.Context, webhook *Webhook, delivery *Delivery) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.opts.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "0xhub-webhooks")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, delivery.ID)
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, delivery.Payload))
+
+	resp, err := d.opts.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Drain a little of the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	return resp.StatusCode, nil
+}
+
+// backoff returns the wait before the retry following the given number of
+// attempts
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.opts.InitialBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= d.opts.MaxBackoff {
+			return d.opts.MaxBackoff
+		}
+	}
+	return min(delay, d.opts.MaxBackoff)
+}
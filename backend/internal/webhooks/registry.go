@@ -0,0 +1,298 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultDeliveryHistory is the number of deliveries kept per webhook
+const DefaultDeliveryHistory = 100
+
+// Registry holds webhooks and their recent deliveries. Webhooks are saved to
+// a file when one is given; deliveries are only kept in memory.
+type Registry struct {
+	mu         sync.RWMutex
+	path       string
+	webhooks   map[string]*Webhook
+	deliveries map[string][]*Delivery // webhook ID -> deliveries, oldest first
+}
+
+// NewRegistry loads the webhooks saved at path, or keeps them in memory only
+// when path is empty
+func NewRegistry(path string) (*Registry, error) {
+	r := &Registry{
+		path:       path,
+		webhooks:   make(map[string]*Webhook),
+		deliveries: make(map[string][]*Delivery),
+	}
+	if path == "" {
+		return r, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhooks: %w", err)
+	}
+	var webhooks []*Webhook
+	if err := json.Unmarshal(data, &webhooks); err != nil {
+		return nil, fmt.Errorf("failed to decode webhooks: %w", err)
+	}
+	for _, w := range webhooks {
+		r.webhooks[w.ID] = w
+	}
+	return r, nil
+}
+
+// List returns every webhook, oldest first, without secrets
+func (r *Registry) List() []*Webhook {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	webhooks := make([]*Webhook, 0, len(r.webhooks))
+	for _, w := range r.webhooks {
+		webhooks = append(webhooks, w.redacted())
+	}
+	sort.Slice(webhooks, func(i, j int) bool {
+		if !webhooks[i].CreatedAt.Equal(webhooks[j].CreatedAt) {
+			return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt)
+		}
+		return webhooks[i].ID < webhooks[j].ID
+	})
+	return webhooks
+}
+
+// Get returns a webhook without its secret, or ErrNotFound
+func (r *Registry) Get(id string) (*Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	w, ok := r.webhooks[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return w.redacted(), nil
+}
+
+// Create adds a webhook, assigning its ID and timestamps, and a secret if it
+// has none
+func (r *Registry) Create(webhook *Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	webhook.ID = newID()
+	if webhook.Secret == "" {
+		webhook.Secret = newSecret()
+	}
+	webhook.CreatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	webhook.UpdatedAt = webhook.CreatedAt
+
+	stored := *webhook
+	stored.Events = slices.Clone(webhook.Events)
+	r.webhooks[stored.ID] = &stored
+	if err := r.saveLocked(); err != nil {
+		delete(r.webhooks, stored.ID)
+		return err
+	}
+	return nil
+}
+
+// Update replaces the URL and events of a webhook, and its secret when one
+// is given, or returns ErrNotFound. The webhook is updated without its
+// secret.
+func (r *Registry) Update(webhook *Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.webhooks[webhook.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	stored := *webhook
+	stored.Events = slices.Clone(webhook.Events)
+	if stored.Secret == "" {
+		stored.Secret = existing.Secret
+	}
+	stored.CreatedAt = existing.CreatedAt
+	stored.UpdatedAt = time.Now().UTC().Truncate(time.Millisecond)
+
+	r.webhooks[stored.ID] = &stored
+	if err := r.saveLocked(); err != nil {
+		r.webhooks[stored.ID] = existing
+		return err
+	}
+	*webhook = *stored.redacted()
+	return nil
+}
+
+// Delete removes a webhook and its deliveries, or returns ErrNotFound
+func (r *Registry) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.webhooks[id]
+	if !ok {
+		return ErrNotFound
+	}
+	delete(r.webhooks, id)
+	if err := r.saveLocked(); err != nil {
+		r.webhooks[id] = existing
+		return err
+	}
+	delete(r.deliveries, id)
+	return nil
+}
+
+// Deliveries returns the recent deliveries of a webhook, newest first
+func (r *Registry) Deliveries(webhookID string) ([]*Delivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.webhooks[webhookID]; !ok {
+		return nil, ErrNotFound
+	}
+	deliveries := r.deliveries[webhookID]
+	result := make([]*Delivery, 0, len(deliveries))
+	for i := len(deliveries) - 1; i >= 0; i-- {
+		result = append(result, deliveries[i].clone())
+	}
+	return result, nil
+}
+
+// Delivery returns a single delivery of a webhook. It returns ErrNotFound
+// for an unknown webhook and ErrDeliveryNotFound for a delivery it no longer
+// keeps.
+func (r *Registry) Delivery(webhookID, id string) (*Delivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.webhooks[webhookID]; !ok {
+		return nil, ErrNotFound
+	}
+	if d := r.findLocked(webhookID, id); d != nil {
+		return d.clone(), nil
+	}
+	return nil, ErrDeliveryNotFound
+}
+
+// subscribers returns the webhooks, with secrets, that receive event
+func (r *Registry) subscribers(event string) []*Webhook {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var webhooks []*Webhook
+	for _, w := range r.webhooks {
+		if w.Subscribes(event) {
+			c := *w
+			webhooks = append(webhooks, &c)
+		}
+	}
+	return webhooks
+}
+
+// webhook returns a webhook with its secret
+func (r *Registry) webhook(id string) (*Webhook, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	w, ok := r.webhooks[id]
+	if !ok {
+		return nil, false
+	}
+	c := *w
+	return &c, true
+}
+
+// addDelivery records a new delivery, dropping the webhook's oldest beyond
+// DefaultDeliveryHistory. It returns false if the webhook no longer exists.
+func (r *Registry) addDelivery(d *Delivery) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.webhooks[d.WebhookID]; !ok {
+		return false
+	}
+	deliveries := r.deliveries[d.WebhookID]
+	if len(deliveries) >= DefaultDeliveryHistory {
+		deliveries = append(deliveries[:0], deliveries[len(deliveries)-DefaultDeliveryHistory+1:]...)
+	}
+	r.deliveries[d.WebhookID] = append(deliveries, d.clone())
+	return true
+}
+
+// updateDelivery applies fn to a recorded delivery, if it is still kept
+func (r *Registry) updateDelivery(webhookID, id string, fn func(d *Delivery)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if d := r.findLocked(webhookID, id); d != nil {
+		fn(d)
+	}
+}
+
+// removeDelivery forgets a recorded delivery
+func (r *Registry) removeDelivery(webhookID, id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.deliveries[webhookID] = slices.DeleteFunc(r.deliveries[webhookID], func(d *Delivery) bool {
+		return d.ID == id
+	})
+}
+
+func (r *Registry) findLocked(webhookID, id string) *Delivery {
+	for _, d := range r.deliveries[webhookID] {
+		if d.ID == id {
+			return d
+		}
+	}
+	return nil
+}
+
+// saveLocked writes every webhook to the registry's file, if any. Callers
+// must hold r.mu.
+func (r *Registry) saveLocked() error {
+	if r.path == "" {
+		return nil
+	}
+	webhooks := make([]*Webhook, 0, len(r.webhooks))
+	for _, w := range r.webhooks {
+		webhooks = append(webhooks, w)
+	}
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].ID < webhooks[j].ID })
+	data, err := json.MarshalIndent(webhooks, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode webhooks: %w", err)
+	}
+
+	// The file holds signing secrets, so only the owner may read it
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to save webhooks: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save webhooks: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save webhooks: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save webhooks: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), r.path); err != nil {
+		return fmt.Errorf("failed to save webhooks: %w", err)
+	}
+	return nil
+}
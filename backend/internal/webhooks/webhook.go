@@ -0,0 +1,176 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"0xhub/backend/internal/models"
+	"0xhub/backend/internal/store"
+)
+
+// Errors returned by Registry and Dispatcher
+var (
+	ErrNotFound         = errors.New("webhook not found")
+	ErrDeliveryNotFound = errors.New("delivery not found")
+	// ErrQueueFull is returned when too many deliveries wait to be sent to
+	// queue another one
+	ErrQueueFull = errors.New("webhook delivery queue is full")
+)
+
+// Event types a webhook can subscribe to
+const (
+	EventProjectCreated = "project.created"
+	EventProjectUpdated = "project.updated"
+	EventProjectDeleted = "project.deleted"
+)
+
+// EventTypes lists every event type
+var EventTypes = []string{EventProjectCreated, EventProjectUpdated, EventProjectDeleted}
+
+// Headers sent with every delivery
+const (
+	// SignatureHeader carries "sha256=" and the hex HMAC-SHA256 of the body,
+	// keyed with the webhook's secret
+	SignatureHeader = "X-0xHub-Signature-256"
+	EventHeader     = "X-0xHub-Event"
+	DeliveryHeader  = "X-0xHub-Delivery"
+)
+
+// eventTypes maps store change types to webhook event types
+var eventTypes = map[string]string{
+	store.ChangeCreated: EventProjectCreated,
+	store.ChangeUpdated: EventProjectUpdated,
+	store.ChangeDeleted: EventProjectDeleted,
+}
+
+// Webhook is a subscription to project change events
+type Webhook struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+	// Events lists the subscribed event types; empty subscribes to all
+	Events []string `json:"events"`
+	// Secret keys the signature of every delivery. It is only returned when
+	// the webhook is created.
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"createdAt,omitzero"`
+	UpdatedAt time.Time `json:"updatedAt,omitzero"`
+}
+
+// Validate checks the webhook's URL and event types. It returns
+// models.ValidationErrors, or nil if the webhook is valid.
+func (w *Webhook) Validate() error {
+	var errs models.ValidationErrors
+	if u, err := url.Parse(w.URL); w.URL == "" {
+		errs = append(errs, models.FieldError{Field: "url", Message: "is required"})
+	} else if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, models.FieldError{Field: "url", Message: "must be an absolute http or https URL"})
+	}
+	for _, event := range w.Events {
+		if !slices.Contains(EventTypes, event) {
+			errs = append(errs, models.FieldError{
+				Field:   "events",
+				Message: "must only contain " + strings.Join(EventTypes, ", "),
+			})
+			break
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Subscribes reports whether the webhook receives events of the given type
+func (w *Webhook) Subscribes(event string) bool {
+	return len(w.Events) == 0 || slices.Contains(w.Events, event)
+}
+
+// redacted returns a copy of the webhook without its secret
+func (w *Webhook) redacted() *Webhook {
+	c := *w
+	c.Events = slices.Clone(w.Events)
+	c.Secret = ""
+	return &c
+}
+
+// Payload is the JSON body of a delivery
+type Payload struct {
+	Event string `json:"event"`
+	// ChangeID is the resource version the change assigned, which orders
+	// events that arrive out of order
+	ChangeID int64           `json:"changeId"`
+	Time     time.Time       `json:"time"`
+	Project  *models.Project `json:"project"`
+}
+
+// Delivery statuses
+const (
+	// DeliveryPending deliveries are queued or waiting to be retried
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	// DeliveryFailed deliveries ran out of attempts
+	DeliveryFailed = "failed"
+)
+
+// Attempt records a single try at a delivery
+type Attempt struct {
+	Time       time.Time `json:"time"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Delivery is an event sent, or to be sent, to a webhook
+type Delivery struct {
+	ID        string          `json:"id"`
+	WebhookID string          `json:"webhookId"`
+	Event     string          `json:"event"`
+	Payload   json.RawMessage `json:"payload"`
+	Status    string          `json:"status"`
+	Attempts  []Attempt       `json:"attempts"`
+	// NextAttemptAt is when a pending delivery is retried
+	NextAttemptAt time.Time `json:"nextAttemptAt,omitzero"`
+	// RedeliveryOf is the ID of the delivery this one repeats
+	RedeliveryOf string    `json:"redeliveryOf,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+func (d *Delivery) clone() *Delivery {
+	c := *d
+	c.Attempts = slices.Clone(d.Attempts)
+	return &c
+}
+
+// Sign returns the SignatureHeader value for body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the SignatureHeader value for body.
+// Receivers written in Go can use it to authenticate deliveries.
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// newID returns a random identifier
+func newID() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// newSecret returns a random signing secret
+func newSecret() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
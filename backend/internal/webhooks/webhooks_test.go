@@ -0,0 +1,346 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"0xhub/backend/internal/models"
+	"0xhub/backend/internal/store"
+)
+
+// received is a request captured by a test receiver
+type received struct {
+	header http.Header
+	body   []byte
+}
+
+// newReceiver starts a server that records every request and responds with
+// the status returned by respond
+func newReceiver(t *testing.T, respond func(n int) int) (*httptest.Server, func() []received) {
+	t.Helper()
+	var mu sync.Mutex
+	var requests []received
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, received{header: r.Header.Clone(), body: body})
+		n := len(requests)
+		mu.Unlock()
+		w.WriteHeader(respond(n))
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []received {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]received(nil), requests...)
+	}
+}
+
+// startDispatcher runs a dispatcher over a new watched store until the test
+// ends, returning once it is subscribed to changes
+func startDispatcher(t *testing.T, registry *Registry, opts Options) (*store.WatchedStore, *Dispatcher) {
+	t.Helper()
+	watched, err := store.NewWatchedStore(store.NewStore(), 0)
+	if err != nil {
+		t.Fatalf("NewWatchedStore() failed: %v", err)
+	}
+	dispatcher := NewDispatcher(registry, opts)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		dispatcher.Run(ctx, watched)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	waitFor(t, "the dispatcher to subscribe", func() bool { return watched.Watchers() == 1 })
+	return watched, dispatcher
+}
+
+func waitFor(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// waitForDelivery waits until the newest delivery of webhookID is no longer
+// pending and returns it
+func waitForDelivery(t *testing.T, registry *Registry, webhookID string) *Delivery {
+	t.Helper()
+	var delivery *Delivery
+	waitFor(t, "a finished delivery", func() bool {
+		deliveries, err := registry.Deliveries(webhookID)
+		if err != nil || len(deliveries) == 0 {
+			return false
+		}
+		delivery = deliveries[0]
+		return delivery.Status != DeliveryPending
+	})
+	return delivery
+}
+
+func newTestRegistry(t *testing.T) *Registry {
+	t.Helper()
+	registry, err := NewRegistry("")
+	if err != nil {
+		t.Fatalf("NewRegistry() failed: %v", err)
+	}
+	return registry
+}
+
+func TestDispatcher_DeliversSignedPayloads(t *testing.T) {
+	server, requests := newReceiver(t, func(int) int { return http.StatusNoContent })
+	registry := newTestRegistry(t)
+	webhook := &Webhook{URL: server.URL, Events: []string{EventProjectCreated}, Secret: "s3cret"}
+	if err := registry.Create(webhook); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	watched, _ := startDispatcher(t, registry, Options{})
+
+	project := &models.Project{ID: "1", Name: "Kubernetes", Status: "active"}
+	watched.Create(project)
+	// Updates are not subscribed to
+	watched.Update(&models.Project{ID: "1", Name: "Kubernetes", Status: "maintenance"})
+
+	delivery := waitForDelivery(t, registry, webhook.ID)
+	if delivery.Status != DeliverySucceeded || len(delivery.Attempts) != 1 || delivery.Attempts[0].StatusCode != http.StatusNoContent {
+		t.Fatalf("Expected a single successful attempt, got %+v", delivery)
+	}
+
+	got := requests()
+	if len(got) != 1 {
+		t.Fatalf("Expected 1 request, got %d", len(got))
+	}
+	req := got[0]
+	if !Verify("s3cret", req.body, req.header.Get(SignatureHeader)) {
+		t.Fatalf("Expected a valid signature, got %q", req.header.Get(SignatureHeader))
+	}
+	if Verify("other", req.body, req.header.Get(SignatureHeader)) {
+		t.Fatal("Expected the signature to depend on the secret")
+	}
+	if req.header.Get(EventHeader) != EventProjectCreated || req.header.Get(DeliveryHeader) != delivery.ID {
+		t.Fatalf("Unexpected headers: %v", req.header)
+	}
+
+	var payload Payload
+	if err := json.Unmarshal(req.body, &payload); err != nil {
+		t.Fatalf("Failed to decode payload: %v", err)
+	}
+	if payload.Event != EventProjectCreated || payload.ChangeID != project.ResourceVersion || payload.Project.Name != "Kubernetes" {
+		t.Fatalf("Unexpected payload: %+v", payload)
+	}
+}
+
+func TestDispatcher_RetriesWithBackoff(t *testing.T) {
+	// Fail twice, then succeed
+	server, requests := newReceiver(t, func(n int) int {
+		if n <= 2 {
+			return http.StatusServiceUnavailable
+		}
+		return http.StatusOK
+	})
+	registry := newTestRegistry(t)
+	webhook := &Webhook{URL: server.URL}
+	registry.Create(webhook)
+	watched, _ := startDispatcher(t, registry, Options{InitialBackoff: 10 * time.Millisecond})
+
+	watched.Create(&models.Project{ID: "1", Name: "Kubernetes"})
+
+	delivery := waitForDelivery(t, registry, webhook.ID)
+	if delivery.Status != DeliverySucceeded || len(delivery.Attempts) != 3 {
+		t.Fatalf("Expected success on the third attempt, got %+v", delivery)
+	}
+	if delivery.Attempts[0].StatusCode != http.StatusServiceUnavailable || delivery.Attempts[0].Error == "" {
+		t.Fatalf("Expected the failed attempt to be recorded, got %+v", delivery.Attempts[0])
+	}
+	if gap := delivery.Attempts[2].Time.Sub(delivery.Attempts[1].Time); gap < 20*time.Millisecond {
+		t.Fatalf("Expected the second retry to wait twice the initial backoff, waited %v", gap)
+	}
+	got := requests()
+	if len(got) != 3 || got[0].header.Get(DeliveryHeader) != got[2].header.Get(DeliveryHeader) {
+		t.Fatalf("Expected 3 tries of the same delivery, got %d", len(got))
+	}
+}
+
+func TestDispatcher_GivesUp(t *testing.T) {
+	server, requests := newReceiver(t, func(int) int { return http.StatusInternalServerError })
+	registry := newTestRegistry(t)
+	webhook := &Webhook{URL: server.URL}
+	registry.Create(webhook)
+	watched, _ := startDispatcher(t, registry, Options{MaxAttempts: 2, InitialBackoff: time.Millisecond})
+
+	watched.Create(&models.Project{ID: "1", Name: "Kubernetes"})
+
+	delivery := waitForDelivery(t, registry, webhook.ID)
+	if delivery.Status != DeliveryFailed || len(delivery.Attempts) != 2 || !delivery.NextAttemptAt.IsZero() {
+		t.Fatalf("Expected the delivery to fail after 2 attempts, got %+v", delivery)
+	}
+	if n := len(requests()); n != 2 {
+		t.Fatalf("Expected 2 requests, got %d", n)
+	}
+}
+
+func TestDispatcher_Redeliver(t *testing.T) {
+	server, requests := newReceiver(t, func(n int) int {
+		if n == 1 {
+			return http.StatusBadGateway
+		}
+		return http.StatusOK
+	})
+	registry := newTestRegistry(t)
+	webhook := &Webhook{URL: server.URL}
+	registry.Create(webhook)
+	watched, dispatcher := startDispatcher(t, registry, Options{MaxAttempts: 1})
+
+	watched.Create(&models.Project{ID: "1", Name: "Kubernetes"})
+	failed := waitForDelivery(t, registry, webhook.ID)
+	if failed.Status != DeliveryFailed {
+		t.Fatalf("Expected the first delivery to fail, got %+v", failed)
+	}
+
+	redelivery, err := dispatcher.Redeliver(webhook.ID, failed.ID)
+	if err != nil {
+		t.Fatalf("Redeliver failed: %v", err)
+	}
+	if redelivery.ID == failed.ID || redelivery.RedeliveryOf != failed.ID {
+		t.Fatalf("Expected a new delivery of %s, got %+v", failed.ID, redelivery)
+	}
+	if done := waitForDelivery(t, registry, webhook.ID); done.ID != redelivery.ID || done.Status != DeliverySucceeded {
+		t.Fatalf("Expected the redelivery to succeed, got %+v", done)
+	}
+	got := requests()
+	if len(got) != 2 || string(got[0].body) != string(got[1].body) {
+		t.Fatalf("Expected the same payload twice, got %d requests", len(got))
+	}
+
+	deliveries, _ := registry.Deliveries(webhook.ID)
+	if len(deliveries) != 2 {
+		t.Fatalf("Expected both deliveries in the log, got %d", len(deliveries))
+	}
+	if _, err := dispatcher.Redeliver(webhook.ID, "missing"); !errors.Is(err, ErrDeliveryNotFound) {
+		t.Fatalf("Expected ErrDeliveryNotFound, got %v", err)
+	}
+	if _, err := dispatcher.Redeliver("missing", failed.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestDispatcher_RedeliverQueueFull(t *testing.T) {
+	registry := newTestRegistry(t)
+	webhook := &Webhook{URL: "https://example.com/hook"}
+	registry.Create(webhook)
+	original := &Delivery{ID: newID(), WebhookID: webhook.ID, Status: DeliveryFailed}
+	registry.addDelivery(original)
+
+	// Without workers running, nothing drains the queue
+	dispatcher := NewDispatcher(registry, Options{})
+	for len(dispatcher.queue) < cap(dispatcher.queue) {
+		dispatcher.queue <- &Delivery{}
+	}
+	if _, err := dispatcher.Redeliver(webhook.ID, original.ID); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("Expected ErrQueueFull, got %v", err)
+	}
+	if deliveries, _ := registry.Deliveries(webhook.ID); len(deliveries) != 1 {
+		t.Fatalf("Expected the refused redelivery not to be recorded, got %d deliveries", len(deliveries))
+	}
+}
+
+func TestDispatcher_Backoff(t *testing.T) {
+	d := NewDispatcher(newTestRegistry(t), Options{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second})
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, w := range want {
+		if got := d.backoff(i + 1); got != w {
+			t.Errorf("backoff(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+}
+
+func TestRegistry_Persists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "webhooks.json")
+	registry, err := NewRegistry(path)
+	if err != nil {
+		t.Fatalf("NewRegistry() failed: %v", err)
+	}
+	webhook := &Webhook{URL: "https://example.com/hook", Events: []string{EventProjectDeleted}}
+	if err := registry.Create(webhook); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if webhook.ID == "" || webhook.Secret == "" {
+		t.Fatalf("Expected an ID and generated secret, got %+v", webhook)
+	}
+	secret := webhook.Secret
+
+	update := &Webhook{ID: webhook.ID, URL: "https://example.com/other"}
+	if err := registry.Update(update); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if update.Secret != "" || !update.CreatedAt.Equal(webhook.CreatedAt) {
+		t.Fatalf("Expected the redacted webhook with its creation time, got %+v", update)
+	}
+	if err := registry.Update(&Webhook{ID: "missing"}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound, got %v", err)
+	}
+
+	reopened, err := NewRegistry(path)
+	if err != nil {
+		t.Fatalf("NewRegistry() failed on reopen: %v", err)
+	}
+	list := reopened.List()
+	if len(list) != 1 || list[0].URL != "https://example.com/other" || list[0].Secret != "" {
+		t.Fatalf("Expected the updated webhook without its secret, got %+v", list)
+	}
+	// The secret is kept for signing even though it is never listed
+	if w, ok := reopened.webhook(webhook.ID); !ok || w.Secret != secret {
+		t.Fatalf("Expected the secret to survive an update and reopen")
+	}
+
+	if err := reopened.Delete(webhook.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := reopened.Get(webhook.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound after delete, got %v", err)
+	}
+}
+
+func TestWebhook_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		webhook Webhook
+		valid   bool
+	}{
+		{"valid", Webhook{URL: "https://example.com/hook", Events: []string{EventProjectCreated}}, true},
+		{"all events", Webhook{URL: "http://ci.local:8080/hook"}, true},
+		{"missing URL", Webhook{}, false},
+		{"relative URL", Webhook{URL: "/hook"}, false},
+		{"unsupported scheme", Webhook{URL: "ftp://example.com/hook"}, false},
+		{"unknown event", Webhook{URL: "https://example.com/hook", Events: []string{"project.renamed"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.webhook.Validate()
+			if tt.valid && err != nil {
+				t.Fatalf("Expected valid, got %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Fatal("Expected a validation error")
+			}
+		})
+	}
+}
@@ -1,6 +1,6 @@
 import { useEffect, useState, useMemo } from 'react'
-import { Project } from './types'
-import { fetchProjects } from './api'
+import { Project, ProjectChangeType } from './types'
+import { fetchProjects, watchProjects } from './api'
 import ProjectCard from './components/ProjectCard'
 
 function App() {
@@ -14,6 +14,23 @@ function App() {
     loadProjects()
   }, [])
 
+  // Apply changes as they happen instead of showing a stale list
+  useEffect(() => {
+    return watchProjects(applyChange, loadProjects)
+  }, [])
+
+  const applyChange = (type: ProjectChangeType, project: Project) => {
+    setProjects((current) => {
+      if (type === 'deleted') {
+        return current.filter((p) => p.id !== project.id)
+      }
+      if (current.some((p) => p.id === project.id)) {
+        return current.map((p) => (p.id === project.id ? project : p))
+      }
+      return [...current, project]
+    })
+  }
+
   const loadProjects = async () => {
     try {
       setLoading(true)
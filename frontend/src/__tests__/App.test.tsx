@@ -6,6 +6,7 @@ import { Project } from '../types'
 // Mock the API module
 vi.mock('../api', () => ({
   fetchProjects: vi.fn(),
+  watchProjects: vi.fn(() => () => {}),
 }))
 
 const { fetchProjects } = await import('../api')
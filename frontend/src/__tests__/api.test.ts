@@ -1,5 +1,5 @@
 import { describe, it, expect, vi, beforeEach } from 'vitest'
-import { fetchProjects, fetchProject } from '../api'
+import { fetchProjects, fetchProject, watchProjects } from '../api'
 import { Project } from '../types'
 
 // Mock global fetch
@@ -35,6 +35,31 @@ describe('API', () => {
       expect(result).toEqual(mockProjects)
     })
 
+    it('should follow pages until the last one', async () => {
+      const page = (id: string): Project => ({
+        id,
+        name: `Project ${id}`,
+        description: 'A test project',
+        url: 'https://test.com',
+        category: 'testing',
+      })
+
+      mockFetch
+        .mockResolvedValueOnce({
+          ok: true,
+          json: async () => ({ projects: [page('1')], total: 2, nextCursor: 'MQ' }),
+        })
+        .mockResolvedValueOnce({
+          ok: true,
+          json: async () => ({ projects: [page('2')], total: 2 }),
+        })
+
+      const result = await fetchProjects()
+
+      expect(mockFetch).toHaveBeenNthCalledWith(2, 'http://localhost:8080/api/projects?cursor=MQ')
+      expect(result).toEqual([page('1'), page('2')])
+    })
+
     it('should throw error when fetch fails', async () => {
       mockFetch.mockResolvedValueOnce({
         ok: false,
@@ -80,5 +105,41 @@ describe('API', () => {
       await expect(fetchProject('non-existent')).rejects.toThrow('Failed to fetch project')
     })
   })
-})
 
+  describe('watchProjects', () => {
+    class FakeEventSource {
+      static last: FakeEventSource
+      listeners: Record<string, (event: any) => void> = {}
+      closed = false
+      constructor(public url: string) {
+        FakeEventSource.last = this
+      }
+      addEventListener(type: string, listener: (event: any) => void) {
+        this.listeners[type] = listener
+      }
+      close() {
+        this.closed = true
+      }
+    }
+
+    it('should deliver changes and resets until closed', () => {
+      vi.stubGlobal('EventSource', FakeEventSource)
+      const onChange = vi.fn()
+      const onReset = vi.fn()
+
+      const close = watchProjects(onChange, onReset)
+      const source = FakeEventSource.last
+      expect(source.url).toBe('http://localhost:8080/api/projects/watch')
+
+      source.listeners['updated']({ data: JSON.stringify({ id: '1', name: 'Renamed' }) })
+      expect(onChange).toHaveBeenCalledWith('updated', { id: '1', name: 'Renamed' })
+
+      source.listeners['reset']({ data: '{}' })
+      expect(onReset).toHaveBeenCalled()
+
+      close()
+      expect(source.closed).toBe(true)
+      vi.unstubAllGlobals()
+    })
+  })
+})
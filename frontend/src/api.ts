@@ -1,14 +1,23 @@
-import { Project, ProjectsResponse } from './types';
+import { Project, ProjectChangeType, ProjectsResponse } from './types';
 
 const API_BASE_URL = import.meta.env.VITE_API_URL || 'http://localhost:8080/api';
 
+// fetchProjects returns every project, following the backend's pages.
 export async function fetchProjects(): Promise<Project[]> {
-  const response = await fetch(`${API_BASE_URL}/projects`);
-  if (!response.ok) {
-    throw new Error('Failed to fetch projects');
+  const projects: Project[] = [];
+  let url = `${API_BASE_URL}/projects`;
+  for (;;) {
+    const response = await fetch(url);
+    if (!response.ok) {
+      throw new Error('Failed to fetch projects');
+    }
+    const data: ProjectsResponse = await response.json();
+    projects.push(...data.projects);
+    if (!data.nextCursor) {
+      return projects;
+    }
+    url = `${API_BASE_URL}/projects?cursor=${encodeURIComponent(data.nextCursor)}`;
   }
-  const data: ProjectsResponse = await response.json();
-  return data.projects;
 }
 
 export async function fetchProject(id: string): Promise<Project> {
@@ -19,3 +28,26 @@ export async function fetchProject(id: string): Promise<Project> {
   return response.json();
 }
 
+
+const CHANGE_TYPES: ProjectChangeType[] = ['created', 'updated', 'deleted'];
+
+// watchProjects subscribes to the backend's stream of project changes.
+// onReset is called when changes may have been missed and the full list
+// should be reloaded. The browser reconnects and resumes automatically.
+// Returns a function that closes the stream.
+export function watchProjects(
+  onChange: (type: ProjectChangeType, project: Project) => void,
+  onReset: () => void,
+): () => void {
+  if (typeof EventSource === 'undefined') {
+    return () => {};
+  }
+  const source = new EventSource(`${API_BASE_URL}/projects/watch`);
+  for (const type of CHANGE_TYPES) {
+    source.addEventListener(type, (event) => {
+      onChange(type, JSON.parse((event as MessageEvent).data));
+    });
+  }
+  source.addEventListener('reset', onReset);
+  return () => source.close();
+}
@@ -6,9 +6,18 @@ export interface Project {
   icon?: string;
   category?: string;
   status?: string;
+  resourceVersion?: number;
+  createdAt?: string;
+  updatedAt?: string;
+  updatedBy?: string;
+  deletedAt?: string;
 }
 
 export interface ProjectsResponse {
   projects: Project[];
+  total: number;
+  nextCursor?: string;
 }
 
+
+export type ProjectChangeType = 'created' | 'updated' | 'deleted';
@@ -78,6 +78,31 @@ Frontend service name
 {{- printf "%s-frontend" (include "0xhub.fullname" .) | trunc 63 | trimSuffix "-" }}
 {{- end }}
 
+{{/*
+Name of the Secret holding the backend API keys and the operator's token
+*/}}
+{{- define "0xhub.authSecretName" -}}
+{{- if .Values.backend.auth.existingSecret }}
+{{- .Values.backend.auth.existingSecret }}
+{{- else }}
+{{- printf "%s-auth" (include "0xhub.fullname" .) | trunc 63 | trimSuffix "-" }}
+{{- end }}
+{{- end }}
+
+{{/*
+Scheme the backend serves its API on
+*/}}
+{{- define "0xhub.backend.scheme" -}}
+{{- if .Values.backend.tls.secretName }}https{{ else }}http{{ end }}
+{{- end }}
+
+{{/*
+Whether the operator authenticates with a projected ServiceAccount token
+*/}}
+{{- define "0xhub.operatorUsesServiceAccountToken" -}}
+{{- if and .Values.backend.auth.enabled .Values.backend.auth.serviceAccountTokens.enabled }}true{{ end }}
+{{- end }}
+
 {{/*
 Operator fullname
 */}}
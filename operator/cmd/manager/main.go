@@ -31,6 +31,11 @@ func main() {
 	var enableLeaderElection bool
 	var probeAddr string
 	var backendURL string
+	var backendToken string
+	var backendTokenFile string
+	var backendCAFile string
+	var backendClientCertFile string
+	var backendClientKeyFile string
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -39,6 +44,16 @@ func main() {
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.StringVar(&backendURL, "backend-url", getEnv("BACKEND_URL", "http://localhost:8080"),
 		"The URL of the backend API server")
+	flag.StringVar(&backendToken, "backend-token", getEnv("BACKEND_TOKEN", ""),
+		"The bearer token used to authenticate to the backend API")
+	flag.StringVar(&backendTokenFile, "backend-token-file", getEnv("BACKEND_TOKEN_FILE", ""),
+		"A file holding the bearer token used to authenticate to the backend API, reread on every request")
+	flag.StringVar(&backendCAFile, "backend-ca-file", getEnv("BACKEND_CA_FILE", ""),
+		"A PEM CA bundle to verify the backend's certificate against instead of the system roots")
+	flag.StringVar(&backendClientCertFile, "backend-client-cert-file", getEnv("BACKEND_CLIENT_CERT_FILE", ""),
+		"A PEM client certificate presented to the backend for mutual TLS")
+	flag.StringVar(&backendClientKeyFile, "backend-client-key-file", getEnv("BACKEND_CLIENT_KEY_FILE", ""),
+		"The PEM private key of the backend client certificate")
 
 	zapOpts := zap.Options{
 		Development: true,
@@ -49,8 +64,24 @@ func main() {
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&zapOpts)))
 
 	// Create backend client
-	backendClient := backend.NewClient(backendURL)
-	setupLog.Info("Backend client configured", "url", backendURL)
+	var clientOpts []backend.Option
+	switch {
+	case backendTokenFile != "":
+		clientOpts = append(clientOpts, backend.WithTokenFile(backendTokenFile))
+	case backendToken != "":
+		clientOpts = append(clientOpts, backend.WithToken(backendToken))
+	}
+	authenticated := len(clientOpts) > 0
+	if (backendClientCertFile == "") != (backendClientKeyFile == "") {
+		setupLog.Error(nil, "A backend client certificate and key must be configured together")
+		os.Exit(1)
+	}
+	if backendCAFile != "" || backendClientCertFile != "" {
+		clientOpts = append(clientOpts, backend.WithTLS(backendCAFile, backendClientCertFile, backendClientKeyFile))
+	}
+	backendClient := backend.NewClient(backendURL, clientOpts...)
+	setupLog.Info("Backend client configured", "url", backendURL, "authenticated", authenticated,
+		"clientCertificate", backendClientCertFile != "")
 
 	// Test backend connection
 	if err := backendClient.HealthCheck(); err != nil {
@@ -92,55 +92,20 @@ func (r *ProjectReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		Status:      project.Spec.Status,
 	}
 
-	// Check if project exists in backend
-	existingProject, err := r.BackendClient.GetProject(req.Name)
-	if err != nil {
-		// Project doesn't exist, create it
-		logger.Info("Creating project in backend", "project", req.Name)
-		if err := r.BackendClient.CreateProject(backendProject); err != nil {
-			logger.Error(err, "Failed to create project in backend", "project", req.Name, "retryCount", project.Status.RetryCount)
-			// Update status with error and retry info
-			retryDelay := r.calculateRetryDelay(project.Status.RetryCount)
-			project.Status.Error = fmt.Sprintf("Failed to create: %v", err)
-			project.Status.Synced = false
-			project.Status.RetryCount++
-			now := time.Now()
-			project.Status.LastRetryAt = &metav1.Time{Time: now}
-			if updateErr := r.Status().Update(ctx, project); updateErr != nil {
-				return ctrl.Result{}, updateErr
-			}
-			logger.Info("Will retry creation", "project", req.Name, "retryCount", project.Status.RetryCount, "retryAfter", retryDelay)
-			return ctrl.Result{RequeueAfter: retryDelay}, nil
-		}
-	} else {
-		// Project exists, check if update is needed
-		needsUpdate := existingProject.Name != backendProject.Name ||
-			existingProject.Description != backendProject.Description ||
-			existingProject.URL != backendProject.URL ||
-			existingProject.Icon != backendProject.Icon ||
-			existingProject.Category != backendProject.Category ||
-			existingProject.Status != backendProject.Status
-
-		if needsUpdate {
-			logger.Info("Updating project in backend", "project", req.Name)
-			if err := r.BackendClient.UpdateProject(req.Name, backendProject); err != nil {
-				logger.Error(err, "Failed to update project in backend", "project", req.Name, "retryCount", project.Status.RetryCount)
-				// Update status with error and retry info
-				retryDelay := r.calculateRetryDelay(project.Status.RetryCount)
-				project.Status.Error = fmt.Sprintf("Failed to update: %v", err)
-				project.Status.Synced = false
-				project.Status.RetryCount++
-				now := time.Now()
-				project.Status.LastRetryAt = &metav1.Time{Time: now}
-				if updateErr := r.Status().Update(ctx, project); updateErr != nil {
-					return ctrl.Result{}, updateErr
-				}
-				logger.Info("Will retry update", "project", req.Name, "retryCount", project.Status.RetryCount, "retryAfter", retryDelay)
-				return ctrl.Result{RequeueAfter: retryDelay}, nil
-			}
-		} else {
-			logger.Info("Project already in sync", "project", req.Name)
+	if err := r.applyProject(ctx, backendProject); err != nil {
+		logger.Error(err, "Failed to apply project to backend", "project", req.Name, "retryCount", project.Status.RetryCount)
+		// Update status with error and retry info
+		retryDelay := r.calculateRetryDelay(project.Status.RetryCount)
+		project.Status.Error = fmt.Sprintf("Failed to apply: %v", err)
+		project.Status.Synced = false
+		project.Status.RetryCount++
+		now := time.Now()
+		project.Status.LastRetryAt = &metav1.Time{Time: now}
+		if updateErr := r.Status().Update(ctx, project); updateErr != nil {
+			return ctrl.Result{}, updateErr
 		}
+		logger.Info("Will retry apply", "project", req.Name, "retryCount", project.Status.RetryCount, "retryAfter", retryDelay)
+		return ctrl.Result{RequeueAfter: retryDelay}, nil
 	}
 
 	// Update status to indicate successful sync
@@ -161,6 +126,25 @@ func (r *ProjectReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	return ctrl.Result{}, nil
 }
 
+// applyProject creates or replaces the project in the backend, unless it
+// already holds the same content. Reconciles triggered by the operator's own
+// status updates then write nothing, instead of bumping the project's version
+// and sending change events for every one.
+func (r *ProjectReconciler) applyProject(ctx context.Context, project *backend.Project) error {
+	logger := log.FromContext(ctx)
+
+	existing, err := r.BackendClient.GetProject(project.ID)
+	if err != nil && !backend.IsNotFound(err) {
+		return err
+	}
+	if existing != nil && existing.SameContent(project) {
+		logger.Info("Project already in sync", "project", project.ID)
+		return nil
+	}
+	logger.Info("Applying project to backend", "project", project.ID)
+	return r.BackendClient.ApplyProject(project)
+}
+
 // calculateRetryDelay calculates exponential backoff delay with jitter
 func (r *ProjectReconciler) calculateRetryDelay(retryCount int) time.Duration {
 	if retryCount >= maxRetryCount {
@@ -29,6 +29,8 @@ type TestBackendServer struct {
 	updateError bool
 	deleteError bool
 	getError    bool
+	// puts counts the PUT requests received
+	puts int
 }
 
 func NewTestBackendServer() *TestBackendServer {
@@ -59,6 +61,7 @@ func NewTestBackendServer() *TestBackendServer {
 			w.WriteHeader(http.StatusOK)
 			json.NewEncoder(w).Encode(project)
 		case http.MethodPut:
+			tbs.puts++
 			if tbs.updateError {
 				w.WriteHeader(http.StatusInternalServerError)
 				return
@@ -329,9 +332,7 @@ func TestProjectReconciler_Reconcile_NotFound(t *testing.T) {
 func TestProjectReconciler_Reconcile_BackendError(t *testing.T) {
 	backendServer := NewTestBackendServer()
 	defer backendServer.Close()
-	backendServer.createError = true
-	// Also set getError so GetProject fails, triggering create path
-	backendServer.getError = true
+	backendServer.updateError = true
 
 	reconciler, k8sClient := setupTestReconciler(backendServer.URL())
 
@@ -384,3 +385,83 @@ func TestProjectReconciler_Reconcile_BackendError(t *testing.T) {
 		t.Error("Status.Error should contain error message")
 	}
 }
+
+func TestProjectReconciler_Reconcile_InSync(t *testing.T) {
+	backendServer := NewTestBackendServer()
+	defer backendServer.Close()
+	reconciler, k8sClient := setupTestReconciler(backendServer.URL())
+
+	project := &v1.Project{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-project",
+			Namespace: "default",
+		},
+		Spec: v1.ProjectSpec{
+			Name:   "Test Project",
+			URL:    "https://test.com",
+			Status: "active",
+		},
+	}
+	if err := k8sClient.Create(context.Background(), project); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	req := ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "test-project",
+			Namespace: "default",
+		},
+	}
+
+	// The first reconcile writes the project; the ones its status update
+	// triggers find it in sync and write nothing
+	for i := 0; i < 3; i++ {
+		if _, err := reconciler.Reconcile(context.Background(), req); err != nil {
+			t.Fatalf("Reconcile failed: %v", err)
+		}
+	}
+	if backendServer.puts != 1 {
+		t.Errorf("Expected 1 PUT, got %d", backendServer.puts)
+	}
+
+	var updatedProject v1.Project
+	if err := k8sClient.Get(context.Background(), req.NamespacedName, &updatedProject); err != nil {
+		t.Fatalf("Failed to get updated project: %v", err)
+	}
+	if !updatedProject.Status.Synced {
+		t.Error("Status.Synced should be true for a project already in sync")
+	}
+}
+
+func TestProjectReconciler_Reconcile_GetError(t *testing.T) {
+	backendServer := NewTestBackendServer()
+	defer backendServer.Close()
+	backendServer.getError = true
+	reconciler, k8sClient := setupTestReconciler(backendServer.URL())
+
+	project := &v1.Project{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-project",
+			Namespace: "default",
+		},
+		Spec: v1.ProjectSpec{
+			Name: "Test Project",
+			URL:  "https://test.com",
+		},
+	}
+	if err := k8sClient.Create(context.Background(), project); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	result, err := reconciler.Reconcile(context.Background(), ctrl.Request{
+		NamespacedName: types.NamespacedName{Name: "test-project", Namespace: "default"},
+	})
+	if err != nil {
+		t.Fatalf("Reconcile should handle backend errors gracefully: %v", err)
+	}
+	if result.RequeueAfter == 0 {
+		t.Error("Should retry when the backend project cannot be read")
+	}
+	if backendServer.puts != 0 {
+		t.Errorf("Expected no PUT when the backend project cannot be read, got %d", backendServer.puts)
+	}
+}
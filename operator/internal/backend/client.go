@@ -3,9 +3,13 @@ package backend
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -13,6 +17,26 @@ import (
 type Client struct {
 	baseURL    string
 	httpClient *http.Client
+	token      string
+	tokenFile  string
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithToken sends token as a bearer token on every request
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithTokenFile sends the contents of path as a bearer token. The file is
+// read on every request, so a rotated Secret takes effect without a restart.
+func WithTokenFile(path string) Option {
+	return func(c *Client) {
+		c.tokenFile = path
+	}
 }
 
 // Project represents a project in the backend API
@@ -24,41 +48,100 @@ type Project struct {
 	Icon        string `json:"icon,omitempty"`
 	Category    string `json:"category,omitempty"`
 	Status      string `json:"status,omitempty"`
+
+	// ResourceVersion is assigned by the backend on every write
+	ResourceVersion int64 `json:"resourceVersion,omitempty"`
+
+	// CreatedAt, UpdatedAt, UpdatedBy and ManagedBy are managed by the
+	// backend and ignored when sent
+	CreatedAt time.Time `json:"createdAt,omitzero"`
+	UpdatedAt time.Time `json:"updatedAt,omitzero"`
+	UpdatedBy string    `json:"updatedBy,omitempty"`
+	ManagedBy string    `json:"managedBy,omitempty"`
+}
+
+// SameContent reports whether p and other have the same client-managed
+// fields, ignoring those the backend manages
+func (p *Project) SameContent(other *Project) bool {
+	return p.ID == other.ID &&
+		p.Name == other.Name &&
+		p.Description == other.Description &&
+		p.URL == other.URL &&
+		p.Icon == other.Icon &&
+		p.Category == other.Category &&
+		p.Status == other.Status
+}
+
+// APIError is returned when the backend answers with a non-2xx status
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("backend API error: status %d, body: %s", e.StatusCode, e.Body)
+}
+
+// IsNotFound reports whether err is a backend 404 response
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
 }
 
+// updatedBy is recorded by the backend as the author of the operator's writes
+const updatedBy = "0xhub-operator"
+
 // NewClient creates a new backend client
-func NewClient(baseURL string) *Client {
-	return &Client{
+func NewClient(baseURL string, opts ...Option) *Client {
+	c := &Client{
 		baseURL: baseURL,
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
-// CreateProject creates a project in the backend
+// CreateProject creates a project in the backend, failing if the ID exists
 func (c *Client) CreateProject(project *Project) error {
 	url := fmt.Sprintf("%s/api/projects", c.baseURL)
-	return c.doRequest(http.MethodPost, url, project, nil)
+	return c.doRequest(http.MethodPost, url, nil, project, nil)
 }
 
-// UpdateProject updates a project in the backend
+// UpdateProject updates an existing project in the backend, failing if it
+// does not exist. If project.ResourceVersion is set, typically from a prior
+// GetProject, the update also fails if the project has changed since.
 func (c *Client) UpdateProject(id string, project *Project) error {
 	url := fmt.Sprintf("%s/api/projects/%s", c.baseURL, id)
-	return c.doRequest(http.MethodPut, url, project, nil)
+	ifMatch := "*"
+	if project.ResourceVersion != 0 {
+		ifMatch = fmt.Sprintf("%q", strconv.FormatInt(project.ResourceVersion, 10))
+	}
+	headers := http.Header{"If-Match": []string{ifMatch}}
+	return c.doRequest(http.MethodPut, url, headers, project, nil)
+}
+
+// ApplyProject creates the project in the backend or replaces it if it
+// already exists
+func (c *Client) ApplyProject(project *Project) error {
+	url := fmt.Sprintf("%s/api/projects/%s", c.baseURL, project.ID)
+	return c.doRequest(http.MethodPut, url, nil, project, nil)
 }
 
 // DeleteProject deletes a project from the backend
 func (c *Client) DeleteProject(id string) error {
 	url := fmt.Sprintf("%s/api/projects/%s", c.baseURL, id)
-	return c.doRequest(http.MethodDelete, url, nil, nil)
+	return c.doRequest(http.MethodDelete, url, nil, nil, nil)
 }
 
 // GetProject retrieves a project from the backend
 func (c *Client) GetProject(id string) (*Project, error) {
 	url := fmt.Sprintf("%s/api/projects/%s", c.baseURL, id)
 	var project Project
-	err := c.doRequest(http.MethodGet, url, nil, &project)
+	err := c.doRequest(http.MethodGet, url, nil, nil, &project)
 	if err != nil {
 		return nil, err
 	}
@@ -68,10 +151,10 @@ func (c *Client) GetProject(id string) (*Project, error) {
 // HealthCheck checks if the backend is healthy
 func (c *Client) HealthCheck() error {
 	url := fmt.Sprintf("%s/api/health", c.baseURL)
-	return c.doRequest(http.MethodGet, url, nil, nil)
+	return c.doRequest(http.MethodGet, url, nil, nil, nil)
 }
 
-func (c *Client) doRequest(method, url string, body interface{}, result interface{}) error {
+func (c *Client) doRequest(method, url string, headers http.Header, body interface{}, result interface{}) error {
 	var reqBody io.Reader
 	if body != nil {
 		jsonData, err := json.Marshal(body)
@@ -86,9 +169,22 @@ func (c *Client) doRequest(method, url string, body interface{}, result interfac
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
+	for key, values := range headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
+	req.Header.Set("X-Updated-By", updatedBy)
+	token, err := c.bearerToken()
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -98,7 +194,7 @@ func (c *Client) doRequest(method, url string, body interface{}, result interfac
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("backend API error: status %d, body: %s", resp.StatusCode, string(bodyBytes))
+		return &APIError{StatusCode: resp.StatusCode, Body: string(bodyBytes)}
 	}
 
 	if result != nil {
@@ -109,3 +205,16 @@ func (c *Client) doRequest(method, url string, body interface{}, result interfac
 
 	return nil
 }
+
+// bearerToken returns the token to authenticate with, preferring the token
+// file when one is configured
+func (c *Client) bearerToken() (string, error) {
+	if c.tokenFile == "" {
+		return c.token, nil
+	}
+	data, err := os.ReadFile(c.tokenFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read backend token: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
@@ -1,9 +1,19 @@
 package backend
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
+	"encoding/pem"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -56,6 +66,7 @@ func TestClient_CreateProject_Success(t *testing.T) {
 		assert.Equal(t, "/api/projects", r.URL.Path)
 		assert.Equal(t, http.MethodPost, r.Method)
 		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "0xhub-operator", r.Header.Get("X-Updated-By"))
 
 		err := json.NewDecoder(r.Body).Decode(&receivedProject)
 		require.NoError(t, err)
@@ -105,6 +116,7 @@ func TestClient_UpdateProject_Success(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "/api/projects/test-1", r.URL.Path)
 		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "*", r.Header.Get("If-Match"))
 
 		err := json.NewDecoder(r.Body).Decode(&receivedProject)
 		require.NoError(t, err)
@@ -146,6 +158,56 @@ func TestClient_UpdateProject_Error(t *testing.T) {
 	assert.Contains(t, err.Error(), "backend API error")
 }
 
+func TestClient_UpdateProject_SendsResourceVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-Match") != `"42"` {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			json.NewEncoder(w).Encode(map[string]string{"error": "If-Match does not match the current project"})
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	err := client.UpdateProject("test-1", &Project{ID: "test-1", Name: "Updated", ResourceVersion: 42})
+	assert.NoError(t, err)
+
+	err = client.UpdateProject("test-1", &Project{ID: "test-1", Name: "Updated", ResourceVersion: 41})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "status 412")
+}
+
+func TestClient_ApplyProject_Success(t *testing.T) {
+	var receivedProject Project
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/projects/test-1", r.URL.Path)
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Empty(t, r.Header.Get("If-Match"))
+		assert.Empty(t, r.Header.Get("If-None-Match"))
+
+		err := json.NewDecoder(r.Body).Decode(&receivedProject)
+		require.NoError(t, err)
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(receivedProject)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	project := &Project{
+		ID:          "test-1",
+		Name:        "Applied Project",
+		Description: "An applied project",
+		URL:         "https://applied.com",
+	}
+
+	err := client.ApplyProject(project)
+	assert.NoError(t, err)
+	assert.Equal(t, "Applied Project", receivedProject.Name)
+}
+
 func TestClient_GetProject_Success(t *testing.T) {
 	expectedProject := Project{
 		ID:          "test-1",
@@ -184,6 +246,7 @@ func TestClient_GetProject_NotFound(t *testing.T) {
 	client := NewClient(server.URL)
 	project, err := client.GetProject("non-existent")
 	assert.Error(t, err)
+	assert.True(t, IsNotFound(err))
 	assert.Nil(t, project)
 }
 
@@ -238,3 +301,104 @@ func TestClient_Timeout(t *testing.T) {
 			contains(err.Error(), "context deadline"),
 		"Error should contain timeout-related message, got: %s", err.Error())
 }
+
+func TestClient_BearerToken(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	err := NewClient(server.URL).DeleteProject("test-1")
+	require.NoError(t, err)
+	assert.Empty(t, authorization)
+
+	err = NewClient(server.URL, WithToken("static-token")).DeleteProject("test-1")
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer static-token", authorization)
+
+	// The token file is reread on every request
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("first-token\n"), 0o600))
+	client := NewClient(server.URL, WithTokenFile(tokenFile))
+
+	require.NoError(t, client.DeleteProject("test-1"))
+	assert.Equal(t, "Bearer first-token", authorization)
+
+	require.NoError(t, os.WriteFile(tokenFile, []byte("second-token"), 0o600))
+	require.NoError(t, client.DeleteProject("test-1"))
+	assert.Equal(t, "Bearer second-token", authorization)
+
+	require.NoError(t, os.Remove(tokenFile))
+	assert.Error(t, client.DeleteProject("test-1"))
+}
+
+// selfSignedClientCert returns a PEM client certificate, which is its own CA,
+// and its key
+func selfSignedClientCert(t *testing.T, commonName string) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestClient_TLS(t *testing.T) {
+	clientCert, clientKey := selfSignedClientCert(t, "0xhub-operator")
+	clientCAs := x509.NewCertPool()
+	require.True(t, clientCAs.AppendCertsFromPEM(clientCert))
+
+	var commonName string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		commonName = r.TLS.PeerCertificates[0].Subject.CommonName
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.crt")
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	serverCA := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caFile, serverCA, 0o600))
+	require.NoError(t, os.WriteFile(certFile, clientCert, 0o600))
+	require.NoError(t, os.WriteFile(keyFile, clientKey, 0o600))
+
+	// Without the CA bundle the server's certificate is not trusted
+	assert.Error(t, NewClient(server.URL).DeleteProject("test-1"))
+
+	// Without a client certificate the server rejects the handshake
+	assert.Error(t, NewClient(server.URL, WithTLS(caFile, "", "")).DeleteProject("test-1"))
+
+	client := NewClient(server.URL, WithTLS(caFile, certFile, keyFile))
+	require.NoError(t, client.DeleteProject("test-1"))
+	assert.Equal(t, "0xhub-operator", commonName)
+
+	// A rotated certificate is picked up on the next request
+	otherCert, otherKey := selfSignedClientCert(t, "intruder")
+	require.NoError(t, os.WriteFile(certFile, otherCert, 0o600))
+	require.NoError(t, os.WriteFile(keyFile, otherKey, 0o600))
+	assert.Error(t, client.DeleteProject("test-1"))
+
+	require.NoError(t, os.WriteFile(caFile, []byte("not a certificate"), 0o600))
+	err := client.DeleteProject("test-1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no certificates found in backend CA bundle")
+}
@@ -0,0 +1,106 @@
+package backend
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// WithTLS verifies the backend's certificate against the PEM CA bundle in
+// caFile, or the system roots when caFile is empty, and presents the PEM
+// client certificate and key in certFile and keyFile when they are set. The
+// files are reread on every request and connections are rebuilt when they
+// change, so rotated certificates take effect without a restart.
+func WithTLS(caFile, certFile, keyFile string) Option {
+	return func(c *Client) {
+		c.httpClient.Transport = &tlsTransport{caFile: caFile, certFile: certFile, keyFile: keyFile}
+	}
+}
+
+// tlsTransport sends requests through a transport built from the current
+// contents of the TLS files
+type tlsTransport struct {
+	caFile   string
+	certFile string
+	keyFile  string
+
+	mu        sync.Mutex
+	transport *http.Transport
+	// loaded holds the file contents transport was built from
+	loaded [][]byte
+}
+
+func (t *tlsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport, err := t.current()
+	if err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	return transport.RoundTrip(req)
+}
+
+// current returns the transport for the files' contents, building a new one
+// when they changed
+func (t *tlsTransport) current() (*http.Transport, error) {
+	contents := make([][]byte, 0, 3)
+	for _, path := range []string{t.caFile, t.certFile, t.keyFile} {
+		if path == "" {
+			contents = append(contents, nil)
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read backend TLS file: %w", err)
+		}
+		contents = append(contents, data)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.transport != nil && equalContents(contents, t.loaded) {
+		return t.transport, nil
+	}
+
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if t.caFile != "" {
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(contents[0]) {
+			return nil, fmt.Errorf("no certificates found in backend CA bundle %s", t.caFile)
+		}
+	}
+	if t.certFile != "" || t.keyFile != "" {
+		cert, err := tls.X509KeyPair(contents[1], contents[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid backend client certificate %s or key %s: %w", t.certFile, t.keyFile, err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+	if t.transport != nil {
+		t.transport.CloseIdleConnections()
+	}
+	t.transport = transport
+	t.loaded = contents
+	return transport, nil
+}
+
+func equalContents(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}